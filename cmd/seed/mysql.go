@@ -19,7 +19,12 @@ func seed_mysql() error {
 	defer db.Close()
 
 	clearQueries := []string{
+		"DELETE FROM sale_contracts",
+		"DELETE FROM sale_approvals",
+		"DELETE FROM trade_ins",
 		"DELETE FROM sales",
+		"DELETE FROM reservations",
+		"DELETE FROM credit_applications",
 		"DELETE FROM vehicles",
 		"DELETE FROM customers",
 		"DELETE FROM salespersons",
@@ -124,7 +129,7 @@ func create_sample_vehicles() []mysql.Vehicle {
 			Color:        "Red",
 			Mileage:      2500,
 			Price:        35000.00,
-			Status:       "reserved",
+			Status:       "pending",
 			Engine_Type:  "3.6L V6",
 			Transmission: "8-Speed Automatic",
 			Fuel_Type:    "Gasoline",
@@ -169,6 +174,7 @@ func create_sample_customers() []mysql.Customer {
 			Zip_Code:      "73301",
 			Date_Of_Birth: &birth_date_1985,
 			Credit_Score:  720,
+			Annual_Income: 85000,
 			Created_At:    now,
 			Updated_At:    now,
 		},
@@ -184,6 +190,7 @@ func create_sample_customers() []mysql.Customer {
 			Zip_Code:      "75201",
 			Date_Of_Birth: &birth_date_1990,
 			Credit_Score:  680,
+			Annual_Income: 62000,
 			Created_At:    now,
 			Updated_At:    now,
 		},
@@ -199,6 +206,7 @@ func create_sample_customers() []mysql.Customer {
 			Zip_Code:      "77001",
 			Date_Of_Birth: &birth_date_1978,
 			Credit_Score:  750,
+			Annual_Income: 120000,
 			Created_At:    now,
 			Updated_At:    now,
 		},
@@ -221,6 +229,7 @@ func create_sample_salespersons() []mysql.Salesperson {
 			Hire_Date:   hire_date_2020,
 			Commission:  0.05,
 			Department:  "New Cars",
+			Role:        mysql.SalesPersonRoleManager,
 			Status:      mysql.SalesPersonStatusActive,
 			Created_At:  now,
 			Updated_At:  now,
@@ -235,6 +244,7 @@ func create_sample_salespersons() []mysql.Salesperson {
 			Hire_Date:   hire_date_2019,
 			Commission:  0.06,
 			Department:  "Used Cars",
+			Role:        mysql.SalesPersonRoleSales,
 			Status:      mysql.SalesPersonStatusActive,
 			Created_At:  now,
 			Updated_At:  now,
@@ -249,50 +259,56 @@ func create_sample_sales(vehicles []mysql.Vehicle, customers []mysql.Customer, s
 
 	return []mysql.Sale{
 		{
-			ID:             uuid.New().String(),
-			Vehicle_ID:     vehicles[2].ID, // Sold Hyundai Sonata
-			Customer_ID:    customers[0].ID,
-			Salesperson_ID: salespersons[0].ID,
-			Sale_Date:      sale_date_1,
-			Sale_Price:     24500.00,
-			Down_Payment:   5000.00,
-			Finance_Amount: 19500.00,
-			Finance_Term:   60,
-			Interest_Rate:  4.5,
-			Payment_Method: mysql.PaymentMethodFinance,
-			Status:         mysql.SaleStatusCompleted,
-			Notes:          "Customer traded in 2018 Toyota Corolla",
-			Created_At:     now,
-			Updated_At:     now,
+			ID:                 uuid.New().String(),
+			Vehicle_ID:         vehicles[2].ID, // Sold Hyundai Sonata
+			Customer_ID:        customers[0].ID,
+			Salesperson_ID:     salespersons[0].ID,
+			Sale_Date:          sale_date_1,
+			Sale_Price:         24500.00,
+			Out_The_Door_Price: 24500.00,
+			Down_Payment:       5000.00,
+			Finance_Amount:     19500.00,
+			Finance_Term:       60,
+			Interest_Rate:      4.5,
+			Payment_Method:     mysql.PaymentMethodFinance,
+			Commission:         1225.00,
+			Status:             mysql.SaleStatusCompleted,
+			Completed_At:       &sale_date_1,
+			Notes:              "Customer traded in 2018 Toyota Corolla",
+			Created_At:         now,
+			Updated_At:         now,
 		},
 		{
-			ID:             uuid.New().String(),
-			Vehicle_ID:     vehicles[3].ID, // Reserved Chevrolet Camaro
-			Customer_ID:    customers[1].ID,
-			Salesperson_ID: salespersons[1].ID,
-			Sale_Date:      sale_date_2,
-			Sale_Price:     35000.00,
-			Down_Payment:   10000.00,
-			Finance_Amount: 25000.00,
-			Finance_Term:   48,
-			Interest_Rate:  3.9,
-			Payment_Method: mysql.PaymentMethodFinance,
-			Status:         mysql.SaleStatusPending,
-			Notes:          "Waiting for financing approval",
-			Created_At:     now,
-			Updated_At:     now,
+			ID:                 uuid.New().String(),
+			Vehicle_ID:         vehicles[3].ID, // Chevrolet Camaro held for the pending sale
+			Customer_ID:        customers[1].ID,
+			Salesperson_ID:     salespersons[1].ID,
+			Sale_Date:          sale_date_2,
+			Sale_Price:         35000.00,
+			Out_The_Door_Price: 35000.00,
+			Down_Payment:       10000.00,
+			Finance_Amount:     25000.00,
+			Finance_Term:       48,
+			Interest_Rate:      3.9,
+			Payment_Method:     mysql.PaymentMethodFinance,
+			Commission:         2100.00,
+			Status:             mysql.SaleStatusPending,
+			Notes:              "Waiting for financing approval",
+			Approval_Reason:    "credit score 680 is below the minimum of 700",
+			Created_At:         now,
+			Updated_At:         now,
 		},
 	}
 }
 
 func seed_vehicles(db *sql.DB, vehicles []mysql.Vehicle) error {
-	query := `INSERT INTO vehicles (id, vin, make, model, year, color, mileage, price, status, engine_type, transmission, fuel_type, created_at, updated_at) 
-			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO vehicles (id, vin, make, model, year, color, mileage, price, status, engine_type, transmission, fuel_type, listed_at, created_at, updated_at) 
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	for _, vehicle := range vehicles {
 		_, err := db.Exec(query, vehicle.ID, vehicle.VIN, vehicle.Make, vehicle.Model, vehicle.Year,
 			vehicle.Color, vehicle.Mileage, vehicle.Price, vehicle.Status, vehicle.Engine_Type,
-			vehicle.Transmission, vehicle.Fuel_Type, vehicle.Created_At, vehicle.Updated_At)
+			vehicle.Transmission, vehicle.Fuel_Type, vehicle.Created_At, vehicle.Created_At, vehicle.Updated_At)
 		if err != nil {
 			return err
 		}
@@ -302,13 +318,13 @@ func seed_vehicles(db *sql.DB, vehicles []mysql.Vehicle) error {
 }
 
 func seed_customers(db *sql.DB, customers []mysql.Customer) error {
-	query := `INSERT INTO customers (id, first_name, last_name, email, phone, address, city, state, zip_code, date_of_birth, credit_score, created_at, updated_at) 
-			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO customers (id, first_name, last_name, email, phone, address, city, state, zip_code, date_of_birth, credit_score, annual_income, created_at, updated_at) 
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	for _, customer := range customers {
 		_, err := db.Exec(query, customer.ID, customer.First_Name, customer.Last_Name, customer.Email,
 			customer.Phone, customer.Address, customer.City, customer.State, customer.Zip_Code,
-			customer.Date_Of_Birth, customer.Credit_Score, customer.Created_At, customer.Updated_At)
+			customer.Date_Of_Birth, customer.Credit_Score, customer.Annual_Income, customer.Created_At, customer.Updated_At)
 		if err != nil {
 			return err
 		}
@@ -318,13 +334,13 @@ func seed_customers(db *sql.DB, customers []mysql.Customer) error {
 }
 
 func seed_salespersons(db *sql.DB, salespersons []mysql.Salesperson) error {
-	query := `INSERT INTO salespersons (id, employee_id, first_name, last_name, email, phone, hire_date, commission, department, status, created_at, updated_at) 
-			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO salespersons (id, employee_id, first_name, last_name, email, phone, hire_date, commission, department, role, status, created_at, updated_at) 
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	for _, salesperson := range salespersons {
 		_, err := db.Exec(query, salesperson.ID, salesperson.Employee_ID, salesperson.First_Name,
 			salesperson.Last_Name, salesperson.Email, salesperson.Phone, salesperson.Hire_Date,
-			salesperson.Commission, salesperson.Department, salesperson.Status, salesperson.Created_At, salesperson.Updated_At)
+			salesperson.Commission, salesperson.Department, salesperson.Role, salesperson.Status, salesperson.Created_At, salesperson.Updated_At)
 		if err != nil {
 			return err
 		}
@@ -334,13 +350,13 @@ func seed_salespersons(db *sql.DB, salespersons []mysql.Salesperson) error {
 }
 
 func seed_sales(db *sql.DB, sales []mysql.Sale) error {
-	query := `INSERT INTO sales (id, vehicle_id, customer_id, salesperson_id, sale_date, sale_price, down_payment, finance_amount, finance_term, interest_rate, payment_method, status, notes, created_at, updated_at) 
-			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO sales (id, vehicle_id, customer_id, salesperson_id, sale_date, sale_price, out_the_door_price, down_payment, finance_amount, finance_term, interest_rate, payment_method, commission, status, notes, approval_reason, completed_at, created_at, updated_at) 
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	for _, sale := range sales {
 		_, err := db.Exec(query, sale.ID, sale.Vehicle_ID, sale.Customer_ID, sale.Salesperson_ID,
-			sale.Sale_Date, sale.Sale_Price, sale.Down_Payment, sale.Finance_Amount, sale.Finance_Term,
-			sale.Interest_Rate, sale.Payment_Method, sale.Status, sale.Notes, sale.Created_At, sale.Updated_At)
+			sale.Sale_Date, sale.Sale_Price, sale.Out_The_Door_Price, sale.Down_Payment, sale.Finance_Amount, sale.Finance_Term,
+			sale.Interest_Rate, sale.Payment_Method, sale.Commission, sale.Status, sale.Notes, sale.Approval_Reason, sale.Completed_At, sale.Created_At, sale.Updated_At)
 		if err != nil {
 			return err
 		}
@@ -3,9 +3,13 @@ package main
 import (
 	"api-servers/internal/api/rest"
 	"api-servers/internal/repository/mysql"
+	"api-servers/internal/repository/redis"
 	"api-servers/internal/service/dealership"
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"time"
 )
 
 func main() {
@@ -14,14 +18,45 @@ func main() {
 		log.Fatal("Failed to connect to MySQL:", err)
 	}
 
+	redisDB, err := redis.GetDatabase()
+	if err != nil {
+		log.Fatal("Failed to connect to Redis:", err)
+	}
+
 	customerRepo := mysql.NewCustomerRepository(mysqlDB)
 	vehicleRepo := mysql.NewVehicleRepository(mysqlDB)
 	salespersonRepo := mysql.NewSalespersonRepository(mysqlDB)
 	salesRepo := mysql.NewSaleRepository(mysqlDB)
+	reservationRepo := mysql.NewReservationRepository(mysqlDB)
+	salesSessionRepo := redis.NewSalesSessionRepository(redisDB)
+	creditRepo := mysql.NewCreditApplicationRepository(mysqlDB)
+	tradeInRepo := mysql.NewTradeInRepository(mysqlDB)
+	approvalRepo := mysql.NewSaleApprovalRepository(mysqlDB)
+	contractRepo := mysql.NewSaleContractRepository(mysqlDB)
+	creditBureau := dealership.NewStubCreditBureau()
+
+	contractKey := os.Getenv("CONTRACT_SIGNING_KEY")
+	if contractKey == "" {
+		log.Fatal("CONTRACT_SIGNING_KEY must be set to sign sale contracts")
+	}
 
-	dealershipService := dealership.NewService(customerRepo, vehicleRepo, salespersonRepo, salesRepo)
+	dealershipService := dealership.NewService(customerRepo, vehicleRepo, salespersonRepo, salesRepo, reservationRepo, mysqlDB, salesSessionRepo, creditRepo, tradeInRepo, approvalRepo, contractRepo, creditBureau,
+		dealership.WithContractSigningKey([]byte(contractKey)),
+	)
+
+	go dealership.RunReservationSweeper(context.Background(), dealershipService, time.Minute)
+
+	// reports are cut on the dealership's local days
+	timezone := os.Getenv("DEALERSHIP_TIMEZONE")
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.Fatal("Invalid DEALERSHIP_TIMEZONE:", err)
+	}
 
-	router := rest.SetupRouter(dealershipService)
+	router := rest.SetupRouter(dealershipService, location)
 
 	log.Println("Starting API server on http://127.0.0.1:8080")
 	log.Fatal(http.ListenAndServe(":8080", router))
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"api-servers/internal/models/mysql"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// contractFormats maps ?format= names to the media type each contract
+// format is served as
+var contractFormats = map[string]string{
+	"json": "application/json",
+	"html": "text/html",
+	"pdf":  "application/pdf",
+}
+
+// negotiateContractFormat picks a format from ?format= or, failing that, the
+// Accept header. No preference means the JSON metadata.
+func negotiateContractFormat(r *http.Request) (string, error) {
+	if format := strings.ToLower(r.URL.Query().Get("format")); format != "" {
+		if _, ok := contractFormats[format]; !ok {
+			return "", fmt.Errorf("unsupported format %q, expected one of %s", format, strings.Join(contractFormatNames(), ", "))
+		}
+		return format, nil
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return "json", nil
+	}
+
+	for _, mediaType := range acceptedMediaTypes(accept) {
+		if mediaType == "*/*" || mediaType == "application/*" {
+			return "json", nil
+		}
+		for format, contentType := range contractFormats {
+			if contentType == mediaType {
+				return format, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("none of %q can be produced, expected one of %s", accept, strings.Join(contractFormatNames(), ", "))
+}
+
+func contractFormatNames() []string {
+	formats := make([]string, 0, len(contractFormats))
+	for format := range contractFormats {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// writeContract sends the stored rendering byte for byte, with its SHA-256 in
+// X-Content-SHA256 and as the ETag so a client can check what it received
+func writeContract(w http.ResponseWriter, contract *mysql.SaleContract, format string) {
+	content, hash := []byte(contract.HTML), contract.HTML_SHA256
+	contentType := "text/html; charset=utf-8"
+	if format == "pdf" {
+		content, hash = contract.PDF, contract.PDF_SHA256
+		contentType = contractFormats["pdf"]
+		filename := fmt.Sprintf("contract-%s.pdf", contract.Sale_ID)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-SHA256", hash)
+	w.Header().Set("ETag", `"`+hash+`"`)
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
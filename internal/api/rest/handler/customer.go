@@ -2,8 +2,10 @@ package handler
 
 import (
 	"api-servers/internal/api/rest/middleware"
+	"api-servers/internal/models/mysql"
 	"api-servers/internal/service/dealership"
 	"encoding/json"
+	"io"
 	"log"
 	"net/http"
 
@@ -24,11 +26,26 @@ func NewCustomerHandlerService(service dealership.DealershipService) *CustomerHa
 func (h *CustomerHandler) GetAllCustomers(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	customers, err := h.dealership_service.GetAllCustomers(r.Context())
+	opts, err := parseListOptions(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "invalid query parameters",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	filter := mysql.CustomerFilter{
+		City:  r.URL.Query().Get("city"),
+		State: r.URL.Query().Get("state"),
+	}
+
+	customers, total, err := h.dealership_service.GetAllCustomers(r.Context(), filter, opts)
 
 	if err != nil {
 		log.Printf("Error getting customers: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 		json.NewEncoder(w).Encode(map[string]string{
 			"error":  "failed to retrieve customers",
 			"detail": err.Error(),
@@ -36,6 +53,7 @@ func (h *CustomerHandler) GetAllCustomers(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	writePaginationHeaders(w, r, opts, total)
 	version := middleware.GetVersionFromContext(r.Context())
 
 	if version == middleware.Version20241001 {
@@ -71,9 +89,9 @@ func (h *CustomerHandler) GetCustomerByID(w http.ResponseWriter, r *http.Request
 	customerID := vars["id"]
 
 	w.Header().Set("Content-Type", "application/json")
-	customerProfile, err := h.dealership_service.GetCustomerProfile(r.Context(), customerID)
+	customer, err := h.dealership_service.GetCustomer(r.Context(), customerID)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 		json.NewEncoder(w).Encode(map[string]string{
 			"error":       "customer not found",
 			"customer_id": customerID,
@@ -81,6 +99,26 @@ func (h *CustomerHandler) GetCustomerByID(w http.ResponseWriter, r *http.Request
 		return
 	}
 	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(customer)
+}
+
+// GET /customers/{id}/profile
+func (h *CustomerHandler) GetCustomerProfile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	customerID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+	customerProfile, err := h.dealership_service.GetCustomerProfile(r.Context(), customerID)
+	if err != nil {
+		log.Printf("Error getting profile for customer %s: %v", customerID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":       "failed to get customer profile",
+			"customer_id": customerID,
+		})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(customerProfile)
 }
 
@@ -100,9 +138,11 @@ func (h *CustomerHandler) CreateCustomer(w http.ResponseWriter, r *http.Request)
 
 	customer, err := h.dealership_service.RegisterNewCustomer(r.Context(), application)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error creating customer: %v", err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 		json.NewEncoder(w).Encode(map[string]string{
-			"error": "failed to create customer",
+			"error":  "failed to create customer",
+			"detail": err.Error(),
 		})
 		return
 	}
@@ -111,6 +151,91 @@ func (h *CustomerHandler) CreateCustomer(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(customer)
 }
 
+// PUT /customers/{id}
+func (h *CustomerHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	customerID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var application dealership.CustomerApplication
+
+	if err := json.NewDecoder(r.Body).Decode(&application); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	customer, err := h.dealership_service.UpdateCustomer(r.Context(), customerID, application)
+	if err != nil {
+		log.Printf("Error updating customer %s: %v", customerID, err)
+		w.WriteHeader(errorStatus(err, http.StatusBadRequest))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":       "failed to update customer",
+			"customer_id": customerID,
+			"detail":      err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(customer)
+}
+
+// PATCH /customers/{id}
+func (h *CustomerHandler) PatchCustomer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	customerID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil || !json.Valid(patch) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	customer, err := h.dealership_service.PatchCustomer(r.Context(), customerID, patch)
+	if err != nil {
+		log.Printf("Error patching customer %s: %v", customerID, err)
+		w.WriteHeader(errorStatus(err, http.StatusBadRequest))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":       "failed to update customer",
+			"customer_id": customerID,
+			"detail":      err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(customer)
+}
+
+// DELETE /customers/{id}
+func (h *CustomerHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	customerID := vars["id"]
+
+	err := h.dealership_service.DeleteCustomer(r.Context(), customerID)
+	if err != nil {
+		log.Printf("Error deleting customer %s: %v", customerID, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":       "failed to delete customer",
+			"customer_id": customerID,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // POST /customers/{id}/credit-application
 func (h *CustomerHandler) ProcessCreditApplication(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -120,7 +245,8 @@ func (h *CustomerHandler) ProcessCreditApplication(w http.ResponseWriter, r *htt
 
 	creditDecision, err := h.dealership_service.ProcessCreditApplication(r.Context(), customerID)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
+		log.Printf("Error processing credit application for customer %s: %v", customerID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 		json.NewEncoder(w).Encode(map[string]string{
 			"error":       "credit processing failed",
 			"customer_id": customerID,
@@ -131,3 +257,69 @@ func (h *CustomerHandler) ProcessCreditApplication(w http.ResponseWriter, r *htt
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(creditDecision)
 }
+
+// GET /customers/{id}/credit-applications
+func (h *CustomerHandler) GetCreditApplications(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	customerID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	applications, err := h.dealership_service.GetCreditApplications(r.Context(), customerID)
+	if err != nil {
+		log.Printf("Error getting credit applications for customer %s: %v", customerID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":       "failed to retrieve credit applications",
+			"customer_id": customerID,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(applications)
+}
+
+// GET /customers/{id}/trade-ins
+func (h *CustomerHandler) GetCustomerTradeIns(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	customerID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	tradeIns, err := h.dealership_service.GetCustomerTradeIns(r.Context(), customerID)
+	if err != nil {
+		log.Printf("Error getting trade-ins for customer %s: %v", customerID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":       "failed to retrieve trade-ins",
+			"customer_id": customerID,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(tradeIns)
+}
+
+// GET /customers/{id}/reservations
+func (h *CustomerHandler) GetCustomerReservations(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	customerID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	reservations, err := h.dealership_service.GetCustomerReservations(r.Context(), customerID)
+	if err != nil {
+		log.Printf("Error getting reservations for customer %s: %v", customerID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":       "failed to retrieve reservations",
+			"customer_id": customerID,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reservations)
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"api-servers/internal/service/dealership"
+	"errors"
+	"net/http"
+)
+
+// errorStatus maps well-known service errors to their HTTP status and falls
+// back to fallback for everything else
+func errorStatus(err error, fallback int) int {
+	var conflict *dealership.ConflictError
+	switch {
+	case errors.As(err, &conflict), errors.Is(err, dealership.ErrDuplicate), errors.Is(err, dealership.ErrInvalidState):
+		return http.StatusConflict
+	case errors.Is(err, dealership.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, dealership.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dealership.ErrInvalidQuery):
+		return http.StatusBadRequest
+	case errors.Is(err, dealership.ErrInvalidInput):
+		return http.StatusUnprocessableEntity
+	}
+	return fallback
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"api-servers/internal/models/mysql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// parseListOptions reads ?limit=, ?offset= and ?sort= from the request
+func parseListOptions(r *http.Request) (mysql.ListOptions, error) {
+	query := r.URL.Query()
+	opts := mysql.ListOptions{
+		Limit: defaultPageLimit,
+		Sort:  query.Get("sort"),
+	}
+
+	var err error
+	if opts.Limit, err = queryInt(query, "limit", defaultPageLimit); err != nil {
+		return opts, err
+	}
+	if opts.Limit < 1 || opts.Limit > maxPageLimit {
+		return opts, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+	}
+
+	if opts.Offset, err = queryInt(query, "offset", 0); err != nil {
+		return opts, err
+	}
+	if opts.Offset < 0 {
+		return opts, fmt.Errorf("offset cannot be negative")
+	}
+
+	return opts, nil
+}
+
+// writePaginationHeaders sets X-Total-Count and an RFC 8288 Link header with
+// next/prev page URLs that keep the rest of the query string intact
+func writePaginationHeaders(w http.ResponseWriter, r *http.Request, opts mysql.ListOptions, total int) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+
+	var links []string
+	if opts.Offset+opts.Limit < total {
+		links = append(links, pageLink(r, opts.Limit, opts.Offset+opts.Limit, "next"))
+	}
+	if opts.Offset > 0 {
+		prev := opts.Offset - opts.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, pageLink(r, opts.Limit, prev, "prev"))
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+func pageLink(r *http.Request, limit, offset int, rel string) string {
+	query := r.URL.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+
+	link := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf(`<%s>; rel="%s"`, link.String(), rel)
+}
+
+func queryInt(query url.Values, key string, fallback int) (int, error) {
+	raw := query.Get(key)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer", key)
+	}
+	return value, nil
+}
+
+func queryFloat(query url.Values, key string) (float64, error) {
+	raw := query.Get(key)
+	if raw == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number", key)
+	}
+	return value, nil
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"api-servers/internal/document"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reportRenderer writes a report in one output format. data is the report
+// value the service returned; table is the same report flattened into
+// tables for formats that can't represent nested JSON.
+type reportRenderer interface {
+	ContentType() string
+	Extension() string
+	Render(w io.Writer, data interface{}, table document.Report) error
+}
+
+// reportRenderers maps ?format= names to renderers. New formats only need
+// an entry here.
+var reportRenderers = map[string]reportRenderer{
+	"json": jsonRenderer{},
+	"csv":  csvRenderer{},
+	"xlsx": xlsxRenderer{},
+	"pdf":  pdfRenderer{},
+}
+
+type jsonRenderer struct{}
+
+func (jsonRenderer) ContentType() string { return "application/json" }
+func (jsonRenderer) Extension() string   { return "json" }
+func (jsonRenderer) Render(w io.Writer, data interface{}, _ document.Report) error {
+	return json.NewEncoder(w).Encode(data)
+}
+
+type csvRenderer struct{}
+
+func (csvRenderer) ContentType() string { return "text/csv" }
+func (csvRenderer) Extension() string   { return "csv" }
+func (csvRenderer) Render(w io.Writer, _ interface{}, table document.Report) error {
+	return document.WriteCSV(w, table)
+}
+
+type xlsxRenderer struct{}
+
+func (xlsxRenderer) ContentType() string {
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+func (xlsxRenderer) Extension() string { return "xlsx" }
+func (xlsxRenderer) Render(w io.Writer, _ interface{}, table document.Report) error {
+	return document.WriteXLSX(w, table)
+}
+
+type pdfRenderer struct{}
+
+func (pdfRenderer) ContentType() string { return "application/pdf" }
+func (pdfRenderer) Extension() string   { return "pdf" }
+func (pdfRenderer) Render(w io.Writer, _ interface{}, table document.Report) error {
+	return document.WritePDF(w, table)
+}
+
+// negotiateRenderer picks a renderer from ?format= or, failing that, the
+// Accept header. No preference means JSON.
+func negotiateRenderer(r *http.Request) (reportRenderer, error) {
+	if format := strings.ToLower(r.URL.Query().Get("format")); format != "" {
+		renderer, ok := reportRenderers[format]
+		if !ok {
+			return nil, fmt.Errorf("unsupported format %q, expected one of %s", format, strings.Join(reportFormats(), ", "))
+		}
+		return renderer, nil
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return reportRenderers["json"], nil
+	}
+
+	for _, mediaType := range acceptedMediaTypes(accept) {
+		if mediaType == "*/*" || mediaType == "application/*" {
+			return reportRenderers["json"], nil
+		}
+		for _, renderer := range reportRenderers {
+			if renderer.ContentType() == mediaType {
+				return renderer, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("none of %q can be produced, expected one of %s", accept, strings.Join(reportFormats(), ", "))
+}
+
+// acceptedMediaTypes returns the media types of an Accept header, most
+// preferred first, dropping any with q=0
+func acceptedMediaTypes(accept string) []string {
+	type candidate struct {
+		mediaType string
+		quality   float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{mediaType, quality})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+
+	mediaTypes := make([]string, len(candidates))
+	for i, c := range candidates {
+		mediaTypes[i] = c.mediaType
+	}
+	return mediaTypes
+}
+
+func reportFormats() []string {
+	formats := make([]string, 0, len(reportRenderers))
+	for format := range reportRenderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// writeReport renders the report into a buffer first so a failed render can
+// still be reported as a 500. Non-JSON formats are sent as attachments.
+func writeReport(w http.ResponseWriter, renderer reportRenderer, name string, data interface{}, table document.Report) {
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, data, table); err != nil {
+		log.Printf("Error rendering %s report as %s: %v", name, renderer.Extension(), err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "failed to render report",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", renderer.ContentType())
+	if renderer.Extension() != "json" {
+		filename := fmt.Sprintf("%s-report-%s.%s", name, time.Now().Format("20060102"), renderer.Extension())
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
@@ -1,88 +1,168 @@
 package handler
 
 import (
+	"api-servers/internal/models/mysql"
 	"api-servers/internal/service/dealership"
 	"encoding/json"
 	"net/http"
+	"time"
 )
 
 type ReportHandler struct {
 	dealership_service dealership.DealershipService
+	location           *time.Location
 }
 
-func NewReportHandler(service dealership.DealershipService) *ReportHandler {
+// NewReportHandler cuts reports on the dealership's local days in location
+// unless a request asks for another timezone with ?tz=
+func NewReportHandler(service dealership.DealershipService, location *time.Location) *ReportHandler {
 	return &ReportHandler{
 		dealership_service: service,
+		location:           location,
 	}
 }
 
-// GET /reports/sales
+// GET /report/sales?start=&end=&preset=&tz=&format=
 func (h *ReportHandler) GenerateSalesReport(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	var reportRequest dealership.ReportPeriod
+	renderer, ok := h.negotiate(w, r)
+	if !ok {
+		return
+	}
 
-	if err := json.NewDecoder(r.Body).Decode(&reportRequest); err != nil {
+	reportRequest, err := parseReportPeriod(r, time.Now(), h.location)
+	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{
-			"error": "invalid request body",
+			"error":  "invalid report period",
+			"detail": err.Error(),
 		})
 		return
 	}
 
 	salesReport, err := h.dealership_service.GenerateSalesReport(r.Context(), reportRequest)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 		json.NewEncoder(w).Encode(map[string]string{
-			"error": "failed to generate sales report",
+			"error":  "failed to generate sales report",
+			"detail": err.Error(),
 		})
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(salesReport)
+	writeReport(w, renderer, "sales", salesReport, salesReportDocument(salesReport))
 }
 
-// GET /reports/performance
-func (h *ReportHandler) GetTopPerformers(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+// GET /report/sales/timeseries?start=&end=&preset=&tz=&interval=&group_by=&format=
+func (h *ReportHandler) GetSalesTimeSeries(w http.ResponseWriter, r *http.Request) {
+	renderer, ok := h.negotiate(w, r)
+	if !ok {
+		return
+	}
+
+	reportRequest, err := parseReportPeriod(r, time.Now(), h.location)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "invalid report period",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	interval := mysql.TimeBucket(r.URL.Query().Get("interval"))
+	groupBy := mysql.SalesGrouping(r.URL.Query().Get("group_by"))
+
+	timeSeries, err := h.dealership_service.GetSalesTimeSeries(r.Context(), reportRequest, interval, groupBy)
+	if err != nil {
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "failed to get sales time series",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	writeReport(w, renderer, "sales-timeseries", timeSeries, salesTimeSeriesDocument(timeSeries))
+}
 
-	var reportRequest dealership.ReportPeriod
+// GET /report/performance?start=&end=&preset=&tz=&format=
+func (h *ReportHandler) GetTopPerformers(w http.ResponseWriter, r *http.Request) {
+	renderer, ok := h.negotiate(w, r)
+	if !ok {
+		return
+	}
 
-	if err := json.NewDecoder(r.Body).Decode(&reportRequest); err != nil {
+	reportRequest, err := parseReportPeriod(r, time.Now(), h.location)
+	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{
-			"error": "invalid request body",
+			"error":  "invalid report period",
+			"detail": err.Error(),
 		})
 		return
 	}
 
 	performanceReport, err := h.dealership_service.GetTopPerformers(r.Context(), reportRequest)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 		json.NewEncoder(w).Encode(map[string]string{
-			"error": "failed to get performance report",
+			"error":  "failed to get performance report",
+			"detail": err.Error(),
 		})
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(performanceReport)
+	writeReport(w, renderer, "performance", performanceReport, performanceReportDocument(performanceReport))
 }
 
-// GET /reports/inventory
+// GET /report/inventory?top=&turn_days=&format=
 func (h *ReportHandler) GetInventoryReport(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+	renderer, ok := h.negotiate(w, r)
+	if !ok {
+		return
+	}
+
+	var options dealership.InventoryReportOptions
+	var err error
+	if options.TopN, err = queryInt(r.URL.Query(), "top", 0); err == nil {
+		options.TurnWindowDays, err = queryInt(r.URL.Query(), "turn_days", 0)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "invalid query parameters",
+			"detail": err.Error(),
+		})
+		return
+	}
 
-	inventoryReport, err := h.dealership_service.GetInventoryReport(r.Context())
+	inventoryReport, err := h.dealership_service.GetInventoryReport(r.Context(), options)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 		json.NewEncoder(w).Encode(map[string]string{
-			"error": "failed to get inventory report",
+			"error":  "failed to get inventory report",
+			"detail": err.Error(),
 		})
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(inventoryReport)
+	writeReport(w, renderer, "inventory", inventoryReport, inventoryReportDocument(inventoryReport))
+}
+
+// negotiate picks the output format and answers 406 when none fits. Errors
+// are always written as JSON, so the content type is set here and replaced
+// once a report is rendered.
+func (h *ReportHandler) negotiate(w http.ResponseWriter, r *http.Request) (reportRenderer, bool) {
+	w.Header().Set("Content-Type", "application/json")
+
+	renderer, err := negotiateRenderer(r)
+	if err != nil {
+		w.WriteHeader(http.StatusNotAcceptable)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "unsupported report format",
+			"detail": err.Error(),
+		})
+		return nil, false
+	}
+	return renderer, true
 }
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"api-servers/internal/service/dealership"
+	"fmt"
+	"net/http"
+	"time"
+	_ "time/tzdata"
+)
+
+const (
+	reportPresetThisMonth   = "this_month"
+	reportPresetLastQuarter = "last_quarter"
+	reportPresetYearToDate  = "ytd"
+)
+
+// parseReportPeriod builds a report period from either ?preset= or
+// ?start=&end=. Dates may be plain days (2024-03-31) or RFC 3339 timestamps;
+// plain days are read in the ?tz= location, or the dealership's own when it
+// isn't given, and an end day is inclusive. With neither given the period
+// defaults to this month.
+func parseReportPeriod(r *http.Request, now time.Time, dealershipLocation *time.Location) (dealership.ReportPeriod, error) {
+	query := r.URL.Query()
+
+	location := dealershipLocation
+	if timezone := query.Get("tz"); timezone != "" {
+		var err error
+		location, err = time.LoadLocation(timezone)
+		if err != nil {
+			return dealership.ReportPeriod{}, fmt.Errorf("unknown timezone %q", timezone)
+		}
+	}
+
+	preset := query.Get("preset")
+	start, end := query.Get("start"), query.Get("end")
+
+	if preset != "" && (start != "" || end != "") {
+		return dealership.ReportPeriod{}, fmt.Errorf("use either preset or start and end, not both")
+	}
+	if preset == "" && start == "" && end == "" {
+		preset = reportPresetThisMonth
+	}
+
+	var period dealership.ReportPeriod
+	var err error
+	if preset != "" {
+		period, err = reportPreset(preset, now.In(location))
+		if err != nil {
+			return period, err
+		}
+	} else {
+		if start == "" || end == "" {
+			return period, fmt.Errorf("both start and end are required")
+		}
+		if period.StartDate, err = parseReportDate(start, location); err != nil {
+			return period, fmt.Errorf("invalid start: %w", err)
+		}
+		if period.EndDate, err = parseReportDate(end, location); err != nil {
+			return period, fmt.Errorf("invalid end: %w", err)
+		}
+		if period.StartDate.After(period.EndDate) {
+			return period, fmt.Errorf("start must not be after end")
+		}
+	}
+
+	period.Timezone = location.String()
+	return period, nil
+}
+
+// reportPreset resolves a named period relative to now. End dates are
+// midnight so the service treats the final day as a whole day.
+func reportPreset(preset string, now time.Time) (dealership.ReportPeriod, error) {
+	location := now.Location()
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, location)
+
+	switch preset {
+	case reportPresetThisMonth:
+		return dealership.ReportPeriod{
+			StartDate: time.Date(year, month, 1, 0, 0, 0, 0, location),
+			EndDate:   today,
+		}, nil
+	case reportPresetLastQuarter:
+		quarterStart := time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, location)
+		return dealership.ReportPeriod{
+			StartDate: quarterStart.AddDate(0, -3, 0),
+			EndDate:   quarterStart.AddDate(0, 0, -1),
+		}, nil
+	case reportPresetYearToDate:
+		return dealership.ReportPeriod{
+			StartDate: time.Date(year, time.January, 1, 0, 0, 0, 0, location),
+			EndDate:   today,
+		}, nil
+	default:
+		return dealership.ReportPeriod{}, fmt.Errorf("unknown preset %q, expected %s, %s or %s",
+			preset, reportPresetThisMonth, reportPresetLastQuarter, reportPresetYearToDate)
+	}
+}
+
+func parseReportDate(value string, location *time.Location) (time.Time, error) {
+	if date, err := time.ParseInLocation("2006-01-02", value, location); err == nil {
+		return date, nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a YYYY-MM-DD date or RFC 3339 timestamp", value)
+	}
+	return timestamp.In(location), nil
+}
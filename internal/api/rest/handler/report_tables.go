@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"api-servers/internal/document"
+	"api-servers/internal/service/dealership"
+	"sort"
+)
+
+// flatten reports into tables for the CSV, XLSX and PDF renderers
+
+func salesReportDocument(report *dealership.SalesReport) document.Report {
+	summary := document.Table{
+		Title:   "Summary",
+		Columns: []string{"Metric", "Value"},
+		Rows: [][]interface{}{
+			{"Start date", report.Period.StartDate},
+			{"End date", report.Period.EndDate},
+			{"Total sales", report.TotalSales},
+			{"Total revenue", report.TotalRevenue},
+			{"Average revenue", report.AverageRevenue},
+			{"Pending sales", report.PendingSales},
+			{"Pending revenue", report.PendingRevenue},
+			{"Cancelled sales", report.CancelledSales},
+			{"Cancelled revenue", report.CancelledRevenue},
+		},
+	}
+
+	vehicles := document.Table{
+		Title:   "Top vehicles",
+		Columns: []string{"Make", "Model", "Year", "Units sold", "Total revenue"},
+	}
+	for _, vehicle := range report.TopVehicles {
+		vehicles.Rows = append(vehicles.Rows, []interface{}{
+			vehicle.Make, vehicle.Model, vehicle.Year, vehicle.UnitsSold, vehicle.TotalRevenue,
+		})
+	}
+
+	return document.Report{
+		Title:  "Sales report",
+		Tables: []document.Table{summary, vehicles, countTable("Sales by status", "Status", report.SalesByStatus)},
+	}
+}
+
+func salesTimeSeriesDocument(report *dealership.SalesTimeSeries) document.Report {
+	points := document.Table{
+		Title:   "Sales by " + string(report.Interval),
+		Columns: []string{"Bucket start", "Group", "Units sold", "Revenue", "Average price"},
+	}
+	for _, series := range report.Series {
+		for _, point := range series.Points {
+			points.Rows = append(points.Rows, []interface{}{
+				point.BucketStart.Format("2006-01-02"), series.Label, point.UnitsSold, point.Revenue, point.AveragePrice,
+			})
+		}
+	}
+
+	return document.Report{
+		Title:  "Sales time series",
+		Tables: []document.Table{points},
+	}
+}
+
+func performanceReportDocument(report *dealership.PerformanceReport) document.Report {
+	salespeople := document.Table{
+		Title:   "Salespeople",
+		Columns: []string{"Employee ID", "First name", "Last name", "Department", "Total sales", "Total revenue", "Commission", "Pending sales", "Pending revenue", "Cancelled sales", "Commission reversed"},
+	}
+	for _, perf := range report.SalesPersonData {
+		salespeople.Rows = append(salespeople.Rows, []interface{}{
+			perf.Salesperson.Employee_ID,
+			perf.Salesperson.First_Name,
+			perf.Salesperson.Last_Name,
+			perf.Salesperson.Department,
+			perf.TotalSales,
+			perf.TotalRevenue,
+			perf.Commission,
+			perf.PendingSales,
+			perf.PendingRevenue,
+			perf.CancelledSales,
+			perf.CommissionReversed,
+		})
+	}
+
+	summary := document.Table{
+		Title:   "Summary",
+		Columns: []string{"Metric", "Value"},
+		Rows: [][]interface{}{
+			{"Start date", report.Period.StartDate},
+			{"End date", report.Period.EndDate},
+			{"Top salesperson", report.TopSalesperson.First_Name + " " + report.TopSalesperson.Last_Name},
+		},
+	}
+
+	return document.Report{
+		Title:  "Salesperson performance report",
+		Tables: []document.Table{summary, salespeople},
+	}
+}
+
+func inventoryReportDocument(report *dealership.InventoryReport) document.Report {
+	summary := document.Table{
+		Title:   "Summary",
+		Columns: []string{"Metric", "Value"},
+		Rows: [][]interface{}{
+			{"As of", report.AsOf},
+			{"Vehicles on lot", report.TotalVehicles},
+			{"Average days on lot", report.AverageDaysOnLot},
+			{"Turn window (days)", report.TurnWindowDays},
+		},
+	}
+
+	aging := document.Table{
+		Title:   "Aging",
+		Columns: []string{"Days on lot", "Units", "Total value"},
+	}
+	for _, bucket := range report.Aging {
+		aging.Rows = append(aging.Rows, []interface{}{string(bucket.Bucket), bucket.Units, bucket.TotalValue})
+	}
+
+	turn := document.Table{
+		Title:   "Turn rate by model",
+		Columns: []string{"Make", "Model", "On lot", "Sold", "Avg days to sell", "Turn rate"},
+	}
+	for _, model := range report.TurnRates {
+		turn.Rows = append(turn.Rows, []interface{}{
+			model.Make, model.Model, model.UnitsOnLot, model.UnitsSold, model.AverageDaysToSell, model.TurnRate,
+		})
+	}
+
+	value := document.Table{
+		Title:   "Value by make",
+		Columns: []string{"Make", "Total value"},
+	}
+	for _, make := range sortedKeys(report.ValueByMake) {
+		value.Rows = append(value.Rows, []interface{}{make, report.ValueByMake[make]})
+	}
+
+	vehicles := document.Table{
+		Title:   "Top value vehicles",
+		Columns: []string{"VIN", "Make", "Model", "Year", "Price", "Status", "Days on lot"},
+	}
+	for _, aged := range report.TopValueVehicles {
+		vehicle := aged.Vehicle
+		vehicles.Rows = append(vehicles.Rows, []interface{}{
+			vehicle.VIN, vehicle.Make, vehicle.Model, vehicle.Year, vehicle.Price, string(vehicle.Status), aged.DaysOnLot,
+		})
+	}
+
+	return document.Report{
+		Title: "Inventory report",
+		Tables: []document.Table{
+			summary,
+			aging,
+			turn,
+			countTable("Vehicles by status", "Status", report.VehiclesByStatus),
+			value,
+			vehicles,
+		},
+	}
+}
+
+func countTable(title, label string, counts map[string]int) document.Table {
+	table := document.Table{
+		Title:   title,
+		Columns: []string{label, "Count"},
+	}
+	for _, key := range sortedKeys(counts) {
+		table.Rows = append(table.Rows, []interface{}{key, counts[key]})
+	}
+	return table
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
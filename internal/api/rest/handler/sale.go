@@ -2,8 +2,16 @@ package handler
 
 import (
 	"api-servers/internal/service/dealership"
+	"context"
 	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
 )
 
 type SaleHandler struct {
@@ -60,6 +68,7 @@ func (h *SaleHandler) CalculateFinancing(w http.ResponseWriter, r *http.Request)
 		VehicleID   string  `json:"vehicle_id"`
 		DownPayment float64 `json:"down_payment"`
 		CustomerID  string  `json:"customer_id"`
+		TradeInID   string  `json:"trade_in_id"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&financingRequest); err != nil {
@@ -75,6 +84,7 @@ func (h *SaleHandler) CalculateFinancing(w http.ResponseWriter, r *http.Request)
 		financingRequest.VehicleID,
 		financingRequest.DownPayment,
 		financingRequest.CustomerID,
+		financingRequest.TradeInID,
 	)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -88,6 +98,90 @@ func (h *SaleHandler) CalculateFinancing(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(financingOptions)
 }
 
+// POST /sale/out-the-door
+func (h *SaleHandler) QuoteOutTheDoor(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var quoteRequest dealership.OutTheDoorRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&quoteRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	pricing, err := h.dealership_service.QuoteOutTheDoor(r.Context(), quoteRequest)
+	if err != nil {
+		w.WriteHeader(errorStatus(err, http.StatusBadRequest))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "failed to price vehicle",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pricing)
+}
+
+// POST /sale/lease-quote
+func (h *SaleHandler) QuoteLease(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var quoteRequest dealership.LeaseQuoteRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&quoteRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	leaseQuote, err := h.dealership_service.QuoteLease(r.Context(), quoteRequest)
+	if err != nil {
+		w.WriteHeader(errorStatus(err, http.StatusBadRequest))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "failed to quote lease",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(leaseQuote)
+}
+
+// POST /sale/amortization
+func (h *SaleHandler) QuoteAmortization(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var quoteRequest dealership.AmortizationQuoteRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&quoteRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	schedule, err := h.dealership_service.QuoteAmortization(r.Context(), quoteRequest)
+	if err != nil {
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "failed to build amortization schedule",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(schedule)
+}
+
 // POST /sales/complete
 func (h *SaleHandler) ProcessVehicleSale(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -104,9 +198,11 @@ func (h *SaleHandler) ProcessVehicleSale(w http.ResponseWriter, r *http.Request)
 
 	saleResult, err := h.dealership_service.ProcessVehicleSale(r.Context(), saleRequest)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error processing vehicle sale for session %s: %v", saleRequest.SessionID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 		json.NewEncoder(w).Encode(map[string]string{
-			"error": "failed to process vehicle sale",
+			"error":  "failed to process vehicle sale",
+			"detail": err.Error(),
 		})
 		return
 	}
@@ -114,3 +210,295 @@ func (h *SaleHandler) ProcessVehicleSale(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(saleResult)
 }
+
+// POST /sales/{id}/cancel
+func (h *SaleHandler) CancelSale(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	saleID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var cancelRequest struct {
+		Reason string `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&cancelRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	cancellation, err := h.dealership_service.CancelSale(r.Context(), saleID, cancelRequest.Reason)
+	if err != nil {
+		log.Printf("Error cancelling sale %s: %v", saleID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":   "failed to cancel sale",
+			"sale_id": saleID,
+			"detail":  err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(cancellation)
+}
+
+// GET /sales/pending
+func (h *SaleHandler) GetPendingSales(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	sales, err := h.dealership_service.GetPendingSales(r.Context())
+	if err != nil {
+		log.Printf("Error getting pending sales: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "failed to retrieve pending sales",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sales)
+}
+
+// POST /sales/{id}/approve
+func (h *SaleHandler) ApproveSale(w http.ResponseWriter, r *http.Request) {
+	h.reviewSale(w, r, "approve", h.dealership_service.ApproveSale)
+}
+
+// POST /sales/{id}/reject
+func (h *SaleHandler) RejectSale(w http.ResponseWriter, r *http.Request) {
+	h.reviewSale(w, r, "reject", h.dealership_service.RejectSale)
+}
+
+func (h *SaleHandler) reviewSale(w http.ResponseWriter, r *http.Request, action string, review func(ctx context.Context, saleID string, review dealership.SaleReviewRequest) (*dealership.SaleReview, error)) {
+	vars := mux.Vars(r)
+	saleID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var reviewRequest dealership.SaleReviewRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&reviewRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	result, err := review(r.Context(), saleID, reviewRequest)
+	if err != nil {
+		log.Printf("Error trying to %s sale %s: %v", action, saleID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":   "failed to " + action + " sale",
+			"sale_id": saleID,
+			"detail":  err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// GET /sales/{id}/approvals
+func (h *SaleHandler) GetSaleApprovals(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	saleID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	approvals, err := h.dealership_service.GetSaleApprovals(r.Context(), saleID)
+	if err != nil {
+		log.Printf("Error getting approvals for sale %s: %v", saleID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":   "failed to retrieve sale approvals",
+			"sale_id": saleID,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(approvals)
+}
+
+// GET /sales/{id}/amortization?extra_monthly=100&lump_sum=12:5000
+func (h *SaleHandler) GetSaleAmortization(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	saleID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	extra, err := parseExtraPayments(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "invalid extra payments",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	schedule, err := h.dealership_service.GetSaleAmortization(r.Context(), saleID, extra)
+	if err != nil {
+		log.Printf("Error building amortization schedule for sale %s: %v", saleID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":   "failed to build amortization schedule",
+			"detail":  err.Error(),
+			"sale_id": saleID,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(schedule)
+}
+
+// parseExtraPayments reads ?extra_monthly= and any number of
+// ?lump_sum=month:amount parameters
+func parseExtraPayments(query url.Values) (dealership.ExtraPayments, error) {
+	var extra dealership.ExtraPayments
+
+	if value := query.Get("extra_monthly"); value != "" {
+		monthly, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return extra, fmt.Errorf("extra_monthly must be a number, got %q", value)
+		}
+		extra.Monthly = monthly
+	}
+
+	for _, value := range query["lump_sum"] {
+		month, amount, ok := strings.Cut(value, ":")
+		if !ok {
+			return extra, fmt.Errorf("lump_sum must be month:amount, got %q", value)
+		}
+		lumpSum := dealership.LumpSumPayment{}
+		var err error
+		if lumpSum.Month, err = strconv.Atoi(month); err != nil {
+			return extra, fmt.Errorf("lump_sum month must be a whole number, got %q", month)
+		}
+		if lumpSum.Amount, err = strconv.ParseFloat(amount, 64); err != nil {
+			return extra, fmt.Errorf("lump_sum amount must be a number, got %q", amount)
+		}
+		extra.LumpSums = append(extra.LumpSums, lumpSum)
+	}
+	return extra, nil
+}
+
+// GET /sales/{id}/contract
+func (h *SaleHandler) GetSaleContract(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	saleID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	format, err := negotiateContractFormat(r)
+	if err != nil {
+		w.WriteHeader(http.StatusNotAcceptable)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "unsupported contract format",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	contract, err := h.dealership_service.GetSaleContract(r.Context(), saleID)
+	if err != nil {
+		log.Printf("Error getting contract for sale %s: %v", saleID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":   "failed to retrieve sale contract",
+			"detail":  err.Error(),
+			"sale_id": saleID,
+		})
+		return
+	}
+
+	if format == "json" {
+		w.Header().Add("Vary", "Accept")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(contract)
+		return
+	}
+	writeContract(w, contract, format)
+}
+
+// GET /sale/sessions/{id}
+func (h *SaleHandler) GetSalesSession(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	salesSession, err := h.dealership_service.GetSalesSession(r.Context(), sessionID)
+	if err != nil {
+		log.Printf("Error getting sales session %s: %v", sessionID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":      "failed to retrieve sales session",
+			"session_id": sessionID,
+			"detail":     err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(salesSession)
+}
+
+// POST /sale/sessions/{id}/cancel
+func (h *SaleHandler) CancelSalesSession(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	salesSession, err := h.dealership_service.CancelSalesSession(r.Context(), sessionID)
+	if err != nil {
+		log.Printf("Error cancelling sales session %s: %v", sessionID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":      "failed to cancel sales session",
+			"session_id": sessionID,
+			"detail":     err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(salesSession)
+}
+
+// GET /sale/sessions?salesperson_id={id}
+func (h *SaleHandler) GetActiveSalesSessions(w http.ResponseWriter, r *http.Request) {
+	salespersonID := r.URL.Query().Get("salesperson_id")
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if salespersonID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "salesperson_id is required",
+		})
+		return
+	}
+
+	salesSessions, err := h.dealership_service.GetActiveSalesSessions(r.Context(), salespersonID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "failed to retrieve sales sessions",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(salesSessions)
+}
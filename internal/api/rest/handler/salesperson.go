@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"api-servers/internal/models/mysql"
+	"api-servers/internal/service/dealership"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type SalespersonHandler struct {
+	dealership_service dealership.DealershipService
+}
+
+func NewSalespersonHandler(service dealership.DealershipService) *SalespersonHandler {
+	return &SalespersonHandler{
+		dealership_service: service,
+	}
+}
+
+// GET /salespeople?department={department}&status={status}
+func (h *SalespersonHandler) GetSalespeople(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	opts, err := parseListOptions(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "invalid query parameters",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	filter := mysql.SalespersonFilter{
+		Department: r.URL.Query().Get("department"),
+		Status:     mysql.SalesPersonStatus(r.URL.Query().Get("status")),
+	}
+
+	switch filter.Status {
+	case "", mysql.SalesPersonStatusActive, mysql.SalesPersonStatusInactive, mysql.SalesPersonStatusTerminated:
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "invalid salesperson status",
+			"status": string(filter.Status),
+		})
+		return
+	}
+
+	salespeople, total, err := h.dealership_service.GetSalespeople(r.Context(), filter, opts)
+	if err != nil {
+		log.Printf("Error getting salespeople: %v", err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "failed to retrieve salespeople",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	writePaginationHeaders(w, r, opts, total)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(salespeople)
+}
+
+// GET /salespeople/{id}
+func (h *SalespersonHandler) GetSalespersonByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	salespersonID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	salesperson, err := h.dealership_service.GetSalesperson(r.Context(), salespersonID)
+	if err != nil {
+		log.Printf("Error getting salesperson %s: %v", salespersonID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":          "failed to retrieve salesperson",
+			"salesperson_id": salespersonID,
+			"detail":         err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(salesperson)
+}
+
+// POST /salespeople
+func (h *SalespersonHandler) CreateSalesperson(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var input dealership.SalespersonInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	salesperson, err := h.dealership_service.HireSalesperson(r.Context(), input)
+	if err != nil {
+		log.Printf("Error creating salesperson: %v", err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "failed to create salesperson",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(salesperson)
+}
+
+// PUT /salespeople/{id}
+func (h *SalespersonHandler) UpdateSalesperson(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	salespersonID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var input dealership.SalespersonInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	salesperson, err := h.dealership_service.UpdateSalesperson(r.Context(), salespersonID, input)
+	if err != nil {
+		log.Printf("Error updating salesperson %s: %v", salespersonID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":          "failed to update salesperson",
+			"salesperson_id": salespersonID,
+			"detail":         err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(salesperson)
+}
+
+// POST /salespeople/{id}/deactivate
+func (h *SalespersonHandler) DeactivateSalesperson(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	salespersonID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	salesperson, err := h.dealership_service.DeactivateSalesperson(r.Context(), salespersonID)
+	if err != nil {
+		log.Printf("Error deactivating salesperson %s: %v", salespersonID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":          "failed to deactivate salesperson",
+			"salesperson_id": salespersonID,
+			"detail":         err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(salesperson)
+}
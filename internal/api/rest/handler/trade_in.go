@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"api-servers/internal/service/dealership"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type TradeInHandler struct {
+	dealership_service dealership.DealershipService
+}
+
+func NewTradeInHandler(service dealership.DealershipService) *TradeInHandler {
+	return &TradeInHandler{
+		dealership_service: service,
+	}
+}
+
+// POST /trade-ins
+func (h *TradeInHandler) AppraiseTradeIn(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var tradeInRequest dealership.TradeInRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&tradeInRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	appraisal, err := h.dealership_service.AppraiseTradeIn(r.Context(), tradeInRequest)
+	if err != nil {
+		log.Printf("Error appraising trade-in for customer %s: %v", tradeInRequest.CustomerID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "failed to appraise trade-in",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(appraisal)
+}
+
+// GET /trade-ins/{id}
+func (h *TradeInHandler) GetTradeIn(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tradeInID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	tradeIn, err := h.dealership_service.GetTradeIn(r.Context(), tradeInID)
+	if err != nil {
+		log.Printf("Error getting trade-in %s: %v", tradeInID, err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":       "failed to retrieve trade-in",
+			"trade_in_id": tradeInID,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(tradeIn)
+}
@@ -1,10 +1,16 @@
 package handler
 
 import (
+	"api-servers/internal/models/mysql"
 	"api-servers/internal/service/dealership"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
@@ -22,10 +28,21 @@ func NewVehicleHandlerService(service dealership.DealershipService) *VehicleHand
 // GET /vehicles
 func (h *VehicleHandler) GetAllVehicles(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	vehicles, err := h.dealership_service.GetAllVehicles(r.Context())
+
+	filter, opts, err := parseVehicleListQuery(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "invalid query parameters",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	vehicles, total, err := h.dealership_service.GetAllVehicles(r.Context(), filter, opts)
 	if err != nil {
 		log.Printf("Error getting all vehicles: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 		json.NewEncoder(w).Encode(map[string]string{
 			"error":  "failed to retrieve vehicles",
 			"detail": err.Error(),
@@ -33,6 +50,7 @@ func (h *VehicleHandler) GetAllVehicles(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	writePaginationHeaders(w, r, opts, total)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(vehicles)
 }
@@ -54,6 +72,7 @@ func (h *VehicleHandler) GetVehicleByID(w http.ResponseWriter, r *http.Request)
 		})
 		return
 	}
+	setVehicleETag(w, vehicle)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(vehicle)
 }
@@ -74,7 +93,7 @@ func (h *VehicleHandler) CreateVehicle(w http.ResponseWriter, r *http.Request) {
 	vehicle, err := h.dealership_service.AddVehicleToInventory(r.Context(), vehicleDetails)
 	if err != nil {
 		log.Printf("Error adding vehicle to inventory: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 		json.NewEncoder(w).Encode(map[string]string{
 			"error":  "failed to add vehicle",
 			"detail": err.Error(),
@@ -86,7 +105,121 @@ func (h *VehicleHandler) CreateVehicle(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(vehicle)
 }
 
-// POST /vehicles/search
+// PUT /vehicles/{id}
+func (h *VehicleHandler) UpdateVehicle(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	vehicleID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var vehicleDetails dealership.VehicleInput
+
+	if err := json.NewDecoder(r.Body).Decode(&vehicleDetails); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err == nil && version != nil {
+		if vehicleDetails.Version != nil && *vehicleDetails.Version != *version {
+			err = fmt.Errorf("body version %d does not match If-Match version %d", *vehicleDetails.Version, *version)
+		}
+		vehicleDetails.Version = version
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "invalid version",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	vehicle, err := h.dealership_service.UpdateVehicle(r.Context(), vehicleID, vehicleDetails)
+	if err != nil {
+		log.Printf("Error updating vehicle %s: %v", vehicleID, err)
+		w.WriteHeader(errorStatus(err, http.StatusBadRequest))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":      "failed to update vehicle",
+			"vehicle_id": vehicleID,
+			"detail":     err.Error(),
+		})
+		return
+	}
+
+	setVehicleETag(w, vehicle)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(vehicle)
+}
+
+// PATCH /vehicles/{id}
+func (h *VehicleHandler) PatchVehicle(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	vehicleID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil || !json.Valid(patch) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "invalid version",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	vehicle, err := h.dealership_service.PatchVehicle(r.Context(), vehicleID, patch, version)
+	if err != nil {
+		log.Printf("Error patching vehicle %s: %v", vehicleID, err)
+		w.WriteHeader(errorStatus(err, http.StatusBadRequest))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":      "failed to update vehicle",
+			"vehicle_id": vehicleID,
+			"detail":     err.Error(),
+		})
+		return
+	}
+
+	setVehicleETag(w, vehicle)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(vehicle)
+}
+
+// DELETE /vehicles/{id}
+func (h *VehicleHandler) DeleteVehicle(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	vehicleID := vars["id"]
+
+	err := h.dealership_service.DeleteVehicle(r.Context(), vehicleID)
+	if err != nil {
+		log.Printf("Error deleting vehicle %s: %v", vehicleID, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":      "failed to delete vehicle",
+			"vehicle_id": vehicleID,
+			"detail":     err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /vehicles/search?sort=&limit=&offset=
 func (h *VehicleHandler) SearchVehicles(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -103,19 +236,33 @@ func (h *VehicleHandler) SearchVehicles(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	vehicles, err := h.dealership_service.FindVehiclesForCustomers(
+	opts, err := parseListOptions(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":  "invalid query parameters",
+			"detail": err.Error(),
+		})
+		return
+	}
+
+	vehicles, total, err := h.dealership_service.FindVehiclesForCustomers(
 		r.Context(),
 		searchRequest.CustomerID,
 		searchRequest.Preferences,
+		opts,
 	)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Error searching vehicles: %v", err)
+		w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 		json.NewEncoder(w).Encode(map[string]string{
-			"error": "failed to search vehicles",
+			"error":  "failed to search vehicles",
+			"detail": err.Error(),
 		})
 		return
 	}
 
+	writePaginationHeaders(w, r, opts, total)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(vehicles)
 }
@@ -139,18 +286,111 @@ func (h *VehicleHandler) ReserveVehicle(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err := h.dealership_service.ReserveVehicle(r.Context(), vehicleID, reservationRequest.CustomerID)
+	reservation, err := h.dealership_service.ReserveVehicle(r.Context(), vehicleID, reservationRequest.CustomerID)
+	var conflict *dealership.ConflictError
+	if errors.As(err, &conflict) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":      "vehicle is no longer available for reservation",
+			"vehicle_id": vehicleID,
+		})
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{
-			"error": "failed to reserve vehicle",
+			"error":  "failed to reserve vehicle",
+			"detail": err.Error(),
 		})
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message":    "vehicle reserved successfully",
-		"vehicle_id": vehicleID,
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":     "vehicle reserved successfully",
+		"vehicle_id":  vehicleID,
+		"reservation": reservation,
 	})
 }
+
+// DELETE /vehicles/{id}/reserve
+func (h *VehicleHandler) ReleaseVehicleReservation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	vehicleID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	reservation, err := h.dealership_service.ReleaseVehicleReservation(r.Context(), vehicleID)
+	var conflict *dealership.ConflictError
+	if errors.As(err, &conflict) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":      "vehicle reservation changed while releasing",
+			"vehicle_id": vehicleID,
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error releasing reservation for vehicle %s: %v", vehicleID, err)
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":      "no active reservation for vehicle",
+			"vehicle_id": vehicleID,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reservation)
+}
+
+// parseVehicleListQuery reads the GET /vehicles filters and paging options
+func parseVehicleListQuery(r *http.Request) (mysql.VehicleFilter, mysql.ListOptions, error) {
+	query := r.URL.Query()
+	filter := mysql.VehicleFilter{
+		Make:   query.Get("make"),
+		Status: mysql.VehicleStatus(query.Get("status")),
+	}
+
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return filter, opts, err
+	}
+
+	if filter.MinPrice, err = queryFloat(query, "min_price"); err != nil {
+		return filter, opts, err
+	}
+	if filter.MaxPrice, err = queryFloat(query, "max_price"); err != nil {
+		return filter, opts, err
+	}
+	if filter.MinYear, err = queryInt(query, "min_year", 0); err != nil {
+		return filter, opts, err
+	}
+	if filter.MaxYear, err = queryInt(query, "max_year", 0); err != nil {
+		return filter, opts, err
+	}
+
+	return filter, opts, nil
+}
+
+// setVehicleETag sends the vehicle's version as its ETag, for clients to
+// return in If-Match when they update it
+func setVehicleETag(w http.ResponseWriter, vehicle *mysql.Vehicle) {
+	w.Header().Set("ETag", `"`+strconv.Itoa(vehicle.Version)+`"`)
+}
+
+// ifMatchVersion reads the vehicle version from If-Match. It returns nil when
+// the header is absent or "*".
+func ifMatchVersion(r *http.Request) (*int, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return nil, nil
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`)
+	version, err := strconv.Atoi(tag)
+	if err != nil || version < 0 {
+		return nil, fmt.Errorf("If-Match %s is not a vehicle version ETag", ifMatch)
+	}
+	return &version, nil
+}
@@ -6,38 +6,80 @@ import (
 	"api-servers/internal/service/dealership"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
-func SetupRouter(dealershipService dealership.DealershipService) *mux.Router {
+// SetupRouter wires the REST routes. Reports default to the dealership's
+// timezone.
+func SetupRouter(dealershipService dealership.DealershipService, timezone *time.Location) *mux.Router {
 	router := mux.NewRouter()
+	router.Use(middleware.TimeoutMiddleware(middleware.DefaultRequestTimeout))
 
 	customerHandler := handler.NewCustomerHandlerService(dealershipService)
 	vehicleHandler := handler.NewVehicleHandlerService(dealershipService)
+	salespersonHandler := handler.NewSalespersonHandler(dealershipService)
 	salesHandler := handler.NewSaleHandler(dealershipService)
-	reportingHandler := handler.NewReportHandler(dealershipService)
+	tradeInHandler := handler.NewTradeInHandler(dealershipService)
+	reportingHandler := handler.NewReportHandler(dealershipService, timezone)
 
 	// customer
 	router.Handle("/customers", middleware.VersioningMiddleware(http.HandlerFunc(customerHandler.GetAllCustomers))).Methods("GET")
 	router.HandleFunc("/customers/{id}", customerHandler.GetCustomerByID).Methods("GET")
+	router.HandleFunc("/customers/{id}/profile", customerHandler.GetCustomerProfile).Methods("GET")
 	router.HandleFunc("/customers", customerHandler.CreateCustomer).Methods("POST")
+	router.HandleFunc("/customers/{id}", customerHandler.UpdateCustomer).Methods("PUT")
+	router.HandleFunc("/customers/{id}", customerHandler.PatchCustomer).Methods("PATCH")
+	router.HandleFunc("/customers/{id}", customerHandler.DeleteCustomer).Methods("DELETE")
 	router.HandleFunc("/customers/{id}/credit-application", customerHandler.ProcessCreditApplication).Methods("POST")
+	router.HandleFunc("/customers/{id}/credit-applications", customerHandler.GetCreditApplications).Methods("GET")
+	router.HandleFunc("/customers/{id}/trade-ins", customerHandler.GetCustomerTradeIns).Methods("GET")
+	router.HandleFunc("/customers/{id}/reservations", customerHandler.GetCustomerReservations).Methods("GET")
 
 	// vehicle
 	router.HandleFunc("/vehicles", vehicleHandler.GetAllVehicles).Methods("GET")
 	router.HandleFunc("/vehicles/{id}", vehicleHandler.GetVehicleByID).Methods("GET")
 	router.HandleFunc("/vehicles", vehicleHandler.CreateVehicle).Methods("POST")
+	router.HandleFunc("/vehicles/{id}", vehicleHandler.UpdateVehicle).Methods("PUT")
+	router.HandleFunc("/vehicles/{id}", vehicleHandler.PatchVehicle).Methods("PATCH")
+	router.HandleFunc("/vehicles/{id}", vehicleHandler.DeleteVehicle).Methods("DELETE")
 	router.HandleFunc("/vehicles/search", vehicleHandler.SearchVehicles).Methods("POST")
 	router.HandleFunc("/vehicles/{id}/reserve", vehicleHandler.ReserveVehicle).Methods("PUT")
+	router.HandleFunc("/vehicles/{id}/reserve", vehicleHandler.ReleaseVehicleReservation).Methods("DELETE")
+
+	// salespeople
+	router.HandleFunc("/salespeople", salespersonHandler.GetSalespeople).Methods("GET")
+	router.HandleFunc("/salespeople/{id}", salespersonHandler.GetSalespersonByID).Methods("GET")
+	router.HandleFunc("/salespeople", salespersonHandler.CreateSalesperson).Methods("POST")
+	router.HandleFunc("/salespeople/{id}", salespersonHandler.UpdateSalesperson).Methods("PUT")
+	router.HandleFunc("/salespeople/{id}/deactivate", salespersonHandler.DeactivateSalesperson).Methods("POST")
 
 	// sales
 	router.HandleFunc("/sale/start", salesHandler.StartSalesProcess).Methods("POST")
 	router.HandleFunc("/sale/financing", salesHandler.CalculateFinancing).Methods("POST")
+	router.HandleFunc("/sale/out-the-door", salesHandler.QuoteOutTheDoor).Methods("POST")
+	router.HandleFunc("/sale/lease-quote", salesHandler.QuoteLease).Methods("POST")
+	router.HandleFunc("/sale/amortization", salesHandler.QuoteAmortization).Methods("POST")
 	router.HandleFunc("/sale/complete", salesHandler.ProcessVehicleSale).Methods("POST")
+	router.HandleFunc("/sale/sessions", salesHandler.GetActiveSalesSessions).Methods("GET")
+	router.HandleFunc("/sale/sessions/{id}", salesHandler.GetSalesSession).Methods("GET")
+	router.HandleFunc("/sale/sessions/{id}/cancel", salesHandler.CancelSalesSession).Methods("POST")
+	router.HandleFunc("/sales/pending", salesHandler.GetPendingSales).Methods("GET")
+	router.HandleFunc("/sales/{id}/cancel", salesHandler.CancelSale).Methods("POST")
+	router.HandleFunc("/sales/{id}/approve", salesHandler.ApproveSale).Methods("POST")
+	router.HandleFunc("/sales/{id}/reject", salesHandler.RejectSale).Methods("POST")
+	router.HandleFunc("/sales/{id}/approvals", salesHandler.GetSaleApprovals).Methods("GET")
+	router.HandleFunc("/sales/{id}/contract", salesHandler.GetSaleContract).Methods("GET")
+	router.HandleFunc("/sales/{id}/amortization", salesHandler.GetSaleAmortization).Methods("GET")
+
+	// trade-ins
+	router.HandleFunc("/trade-ins", tradeInHandler.AppraiseTradeIn).Methods("POST")
+	router.HandleFunc("/trade-ins/{id}", tradeInHandler.GetTradeIn).Methods("GET")
 
 	// reporting
 	router.HandleFunc("/report/sales", reportingHandler.GenerateSalesReport).Methods("GET")
+	router.HandleFunc("/report/sales/timeseries", reportingHandler.GetSalesTimeSeries).Methods("GET")
 	router.HandleFunc("/report/performance", reportingHandler.GetTopPerformers).Methods("GET")
 	router.HandleFunc("/report/inventory", reportingHandler.GetInventoryReport).Methods("GET")
 
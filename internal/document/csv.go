@@ -0,0 +1,45 @@
+package document
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// WriteCSV writes every table of the report into a single CSV stream. When
+// there is more than one table each is preceded by its title and followed
+// by a blank row.
+func WriteCSV(w io.Writer, report Report) error {
+	writer := csv.NewWriter(w)
+	multiple := len(report.Tables) > 1
+
+	for _, table := range report.Tables {
+		if multiple {
+			if err := writer.Write([]string{table.Title}); err != nil {
+				return fmt.Errorf("failed to write csv title: %w", err)
+			}
+		}
+		if err := writer.Write(table.Columns); err != nil {
+			return fmt.Errorf("failed to write csv header: %w", err)
+		}
+
+		for _, row := range table.Rows {
+			record := make([]string, len(row))
+			for i, cell := range row {
+				record[i] = FormatCell(cell)
+			}
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("failed to write csv row: %w", err)
+			}
+		}
+
+		if multiple {
+			if err := writer.Write(nil); err != nil {
+				return fmt.Errorf("failed to write csv separator: %w", err)
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,79 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// HTML builds a self-contained HTML page with the same structure as PDF:
+// headings, paragraphs and tables
+type HTML struct {
+	title string
+	body  bytes.Buffer
+}
+
+// NewHTML starts a page with title as both the page title and first heading
+func NewHTML(title string) *HTML {
+	doc := &HTML{title: title}
+	if title != "" {
+		fmt.Fprintf(&doc.body, "<h1>%s</h1>\n", html.EscapeString(title))
+	}
+	return doc
+}
+
+// Heading adds a section heading
+func (d *HTML) Heading(text string) {
+	fmt.Fprintf(&d.body, "<h2>%s</h2>\n", html.EscapeString(text))
+}
+
+// Text adds a paragraph; line breaks in text are kept
+func (d *HTML) Text(text string) {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = html.EscapeString(line)
+	}
+	fmt.Fprintf(&d.body, "<p>%s</p>\n", strings.Join(lines, "<br>\n"))
+}
+
+// Blank adds vertical space
+func (d *HTML) Blank() {
+	d.body.WriteString("<br>\n")
+}
+
+// Table adds a titled table with a header row
+func (d *HTML) Table(table Table) {
+	if table.Title != "" {
+		d.Heading(table.Title)
+	}
+	d.body.WriteString("<table>\n")
+	if len(table.Columns) > 0 {
+		d.body.WriteString("<tr>")
+		for _, column := range table.Columns {
+			fmt.Fprintf(&d.body, "<th>%s</th>", html.EscapeString(column))
+		}
+		d.body.WriteString("</tr>\n")
+	}
+	for _, row := range table.Rows {
+		d.body.WriteString("<tr>")
+		for _, cell := range row {
+			fmt.Fprintf(&d.body, "<td>%s</td>", html.EscapeString(FormatCell(cell)))
+		}
+		d.body.WriteString("</tr>\n")
+	}
+	d.body.WriteString("</table>\n")
+}
+
+// WriteTo writes the finished page to w
+func (d *HTML) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	buf.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&buf, "<title>%s</title>\n", html.EscapeString(d.title))
+	buf.WriteString("<style>body{font-family:sans-serif;max-width:48em;margin:2em auto}table{border-collapse:collapse}th,td{border:1px solid #ccc;padding:.25em .5em;text-align:left}</style>\n")
+	buf.WriteString("</head>\n<body>\n")
+	buf.Write(d.body.Bytes())
+	buf.WriteString("</body>\n</html>\n")
+	return buf.WriteTo(w)
+}
@@ -0,0 +1,214 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// US letter page laid out in monospaced Courier so tables line up
+const (
+	pdfPageWidth   = 612
+	pdfPageHeight  = 792
+	pdfMargin      = 50
+	pdfFontSize    = 9
+	pdfTitleSize   = 14
+	pdfLeading     = 12
+	pdfCharsPerRow = (pdfPageWidth - 2*pdfMargin) * 10 / (pdfFontSize * 6)
+	pdfTitlePerRow = (pdfPageWidth - 2*pdfMargin) * 10 / (pdfTitleSize * 6)
+	pdfRowsPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+)
+
+type pdfLine struct {
+	text  string
+	bold  bool
+	title bool
+}
+
+// PDF builds a simple text document. Lines longer than the page are wrapped
+// and content flows onto as many pages as it needs.
+type PDF struct {
+	lines []pdfLine
+}
+
+// NewPDF starts a document with title printed at the top of the first page
+func NewPDF(title string) *PDF {
+	doc := &PDF{}
+	if title != "" {
+		for _, line := range wrap(title, pdfTitlePerRow) {
+			doc.lines = append(doc.lines, pdfLine{text: line, bold: true, title: true})
+		}
+		doc.lines = append(doc.lines, pdfLine{})
+	}
+	return doc
+}
+
+// Heading adds a bold line
+func (d *PDF) Heading(text string) {
+	for _, line := range wrap(text, pdfCharsPerRow) {
+		d.lines = append(d.lines, pdfLine{text: line, bold: true})
+	}
+}
+
+// Text adds a paragraph, wrapping it to the page width
+func (d *PDF) Text(text string) {
+	for _, paragraph := range strings.Split(text, "\n") {
+		for _, line := range wrap(paragraph, pdfCharsPerRow) {
+			d.lines = append(d.lines, pdfLine{text: line})
+		}
+	}
+}
+
+// Blank adds an empty line
+func (d *PDF) Blank() {
+	d.lines = append(d.lines, pdfLine{})
+}
+
+// Table adds a titled, column-aligned table
+func (d *PDF) Table(table Table) {
+	if table.Title != "" {
+		d.Heading(table.Title)
+	}
+	for _, line := range FormatTable(table, 28) {
+		d.Text(line)
+	}
+	d.Blank()
+}
+
+// WriteTo writes the finished PDF to w
+func (d *PDF) WriteTo(w io.Writer) (int64, error) {
+	pages := d.pages()
+
+	// fixed objects: 1 catalog, 2 page tree, 3 regular font, 4 bold font;
+	// each page then takes two objects, the page and its content stream
+	var objects []string
+	objects = append(objects, "<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	objects = append(objects, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		content := pageContent(page)
+		objects = append(objects, fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+i*2))
+		objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.WriteTo(w)
+}
+
+// WritePDF prints each table of the report under the report title
+func WritePDF(w io.Writer, report Report) error {
+	doc := NewPDF(report.Title)
+	for _, table := range report.Tables {
+		doc.Table(table)
+	}
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+func (d *PDF) pages() [][]pdfLine {
+	var pages [][]pdfLine
+	for start := 0; start < len(d.lines); start += pdfRowsPerPage {
+		end := start + pdfRowsPerPage
+		if end > len(d.lines) {
+			end = len(d.lines)
+		}
+		pages = append(pages, d.lines[start:end])
+	}
+	if len(pages) == 0 {
+		pages = append(pages, nil)
+	}
+	return pages
+}
+
+func pageContent(lines []pdfLine) string {
+	var b strings.Builder
+	y := pdfPageHeight - pdfMargin
+	for _, line := range lines {
+		if line.text != "" {
+			font, size := "F1", pdfFontSize
+			if line.bold {
+				font = "F2"
+			}
+			if line.title {
+				size = pdfTitleSize
+			}
+			fmt.Fprintf(&b, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, size, pdfMargin, y, pdfEscape(line.text))
+		}
+		y -= pdfLeading
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// pdfEscape escapes string delimiters and replaces anything outside the
+// Latin-1 range, which the standard fonts cannot draw
+func pdfEscape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32:
+			b.WriteByte(' ')
+		case r < 128:
+			b.WriteRune(r)
+		case r < 256:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// wrap breaks text into lines of at most width characters, at the last
+// space where there is one. Width counts runes, so multi-byte characters are
+// never split.
+func wrap(text string, width int) []string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return []string{text}
+	}
+
+	var lines []string
+	for len(runes) > width {
+		cut := width
+		for i := width; i > 0; i-- {
+			if runes[i] == ' ' {
+				cut = i
+				break
+			}
+		}
+		lines = append(lines, strings.TrimRight(string(runes[:cut]), " "))
+		runes = []rune(strings.TrimLeft(string(runes[cut:]), " "))
+	}
+	if len(runes) > 0 {
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
@@ -0,0 +1,115 @@
+// Package document renders tabular data and plain text into downloadable
+// file formats (CSV, XLSX, PDF and HTML) using only the standard library.
+package document
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Table is a titled grid of cells. Cells may be strings, ints, float64s or
+// time.Times; writers that know about types (XLSX) keep numbers numeric.
+type Table struct {
+	Title   string
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// Report is a titled set of tables
+type Report struct {
+	Title  string
+	Tables []Table
+}
+
+// Builder lays out a text document. PDF and HTML both implement it, so the
+// same content can be written once and rendered to either.
+type Builder interface {
+	Heading(text string)
+	Text(text string)
+	Blank()
+	Table(table Table)
+}
+
+// FormatCell renders a single cell as text
+func FormatCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case bool:
+		return strconv.FormatBool(v)
+	case interface{ String() string }:
+		return v.String()
+	default:
+		return ""
+	}
+}
+
+// FormatTable lays a table out as fixed-width text lines, truncating cells
+// wider than maxCellWidth. Widths are counted in runes.
+func FormatTable(table Table, maxCellWidth int) []string {
+	widths := make([]int, len(table.Columns))
+	cells := make([][]string, 0, len(table.Rows)+1)
+
+	header := make([]string, len(table.Columns))
+	copy(header, table.Columns)
+	cells = append(cells, header)
+
+	for _, row := range table.Rows {
+		line := make([]string, len(table.Columns))
+		for i := range table.Columns {
+			if i < len(row) {
+				line[i] = FormatCell(row[i])
+			}
+		}
+		cells = append(cells, line)
+	}
+
+	for _, line := range cells {
+		for i, cell := range line {
+			if runes := []rune(cell); len(runes) > maxCellWidth {
+				line[i] = string(runes[:maxCellWidth-1]) + "~"
+			}
+			if width := utf8.RuneCountInString(line[i]); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(cells)+1)
+	for n, line := range cells {
+		var b strings.Builder
+		for i, cell := range line {
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			b.WriteString(cell)
+			b.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " "))
+
+		if n == 0 {
+			var rule strings.Builder
+			for i, width := range widths {
+				if i > 0 {
+					rule.WriteString("  ")
+				}
+				rule.WriteString(strings.Repeat("-", width))
+			}
+			lines = append(lines, rule.String())
+		}
+	}
+	return lines
+}
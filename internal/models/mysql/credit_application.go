@@ -0,0 +1,20 @@
+package mysql
+
+import "time"
+
+// CreditApplication records the inputs and outcome of one credit decision
+type CreditApplication struct {
+	ID             string    `json:"id" db:"id"`
+	Customer_ID    string    `json:"customer_id" db:"customer_id"`
+	Bureau         string    `json:"bureau" db:"bureau"`
+	Annual_Income  float64   `json:"annual_income" db:"annual_income"`
+	Monthly_Debt   float64   `json:"monthly_debt" db:"monthly_debt"`
+	Credit_Score   int       `json:"credit_score" db:"credit_score"`
+	Debt_To_Income float64   `json:"debt_to_income" db:"debt_to_income"`
+	Approved       bool      `json:"approved" db:"approved"`
+	Credit_Limit   float64   `json:"credit_limit" db:"credit_limit"`
+	Interest_Rate  float64   `json:"interest_rate" db:"interest_rate"`
+	Reason         string    `json:"reason" db:"reason"`
+	Created_At     time.Time `json:"created_at" db:"created_at"`
+	Expires_At     time.Time `json:"expires_at" db:"expires_at"`
+}
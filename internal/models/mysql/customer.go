@@ -14,6 +14,8 @@ type Customer struct {
 	Zip_Code      string     `json:"zip_code" db:"zip_code"`
 	Date_Of_Birth *time.Time `json:"date_of_birth" db:"date_of_birth"`
 	Credit_Score  int        `json:"credit_score" db:"credit_score"`
+	Annual_Income float64    `json:"annual_income" db:"annual_income"`
+	Deleted_At    *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 	Created_At    time.Time  `json:"created_at" db:"created_at"`
 	Updated_At    time.Time  `json:"updated_at" db:"updated_at"`
 }
@@ -0,0 +1,34 @@
+package mysql
+
+// ListOptions pages and orders list queries. Sort names a column, prefixed
+// with "-" for descending order.
+type ListOptions struct {
+	Limit  int
+	Offset int
+	Sort   string
+}
+
+// VehicleFilter narrows vehicle queries. Zero values mean "no bound", so a
+// filter with only MinPrice set has no upper price limit.
+type VehicleFilter struct {
+	Make       string
+	Makes      []string
+	Status     VehicleStatus
+	Statuses   []VehicleStatus
+	FuelTypes  []FuelType
+	MinPrice   float64
+	MaxPrice   float64
+	MinYear    int
+	MaxYear    int
+	MaxMileage int
+}
+
+type CustomerFilter struct {
+	City  string
+	State string
+}
+
+type SalespersonFilter struct {
+	Department string
+	Status     SalesPersonStatus
+}
@@ -0,0 +1,96 @@
+package mysql
+
+import "time"
+
+// VehicleSalesSummary is one make/model/year/status group of sales
+type VehicleSalesSummary struct {
+	Make          string     `json:"make" db:"make"`
+	Model         string     `json:"model" db:"model"`
+	Year          int        `json:"year" db:"year"`
+	Status        SaleStatus `json:"status" db:"status"`
+	Units_Sold    int        `json:"units_sold" db:"units_sold"`
+	Total_Revenue float64    `json:"total_revenue" db:"total_revenue"`
+}
+
+// SalespersonSalesSummary is a salesperson with their sales totals for a
+// period. Salespeople without sales are included with zero totals.
+type SalespersonSalesSummary struct {
+	Salesperson
+	Total_Sales       int     `json:"total_sales" db:"total_sales"`
+	Total_Revenue     float64 `json:"total_revenue" db:"total_revenue"`
+	Commission_Earned float64 `json:"commission_earned" db:"commission_earned"`
+
+	Pending_Sales   int     `json:"pending_sales" db:"pending_sales"`
+	Pending_Revenue float64 `json:"pending_revenue" db:"pending_revenue"`
+
+	Cancelled_Sales     int     `json:"cancelled_sales" db:"cancelled_sales"`
+	Commission_Reversed float64 `json:"commission_reversed" db:"commission_reversed"`
+}
+
+// TimeBucket is the width of a time series bucket
+type TimeBucket string
+
+const (
+	TimeBucketDay   TimeBucket = "day"
+	TimeBucketWeek  TimeBucket = "week"
+	TimeBucketMonth TimeBucket = "month"
+)
+
+// SalesGrouping splits a sales time series into one series per value
+type SalesGrouping string
+
+const (
+	SalesGroupingNone          SalesGrouping = ""
+	SalesGroupingMake          SalesGrouping = "make"
+	SalesGroupingSalesperson   SalesGrouping = "salesperson"
+	SalesGroupingPaymentMethod SalesGrouping = "payment_method"
+)
+
+// SalesTimeSeriesQuery selects completed sales between Start and End
+// (inclusive), split by GroupBy
+type SalesTimeSeriesQuery struct {
+	Start   time.Time
+	End     time.Time
+	GroupBy SalesGrouping
+}
+
+// SalesTimeSeriesRow totals one group's sales in one 15 minute slot of UTC
+// time, starting at Slot_Start seconds since the epoch. Every UTC offset is a
+// whole number of slots, so the service can place each slot on a local day
+// in any timezone, daylight saving changes included.
+type SalesTimeSeriesRow struct {
+	Slot_Start    int64   `db:"slot_start"`
+	Group_Key     string  `db:"group_key"`
+	Group_Label   string  `db:"group_label"`
+	Units_Sold    int     `db:"units_sold"`
+	Total_Revenue float64 `db:"total_revenue"`
+}
+
+// InventoryAgeBucket labels how long a vehicle has been on the lot
+type InventoryAgeBucket string
+
+const (
+	InventoryAge0To30  InventoryAgeBucket = "0-30"
+	InventoryAge31To60 InventoryAgeBucket = "31-60"
+	InventoryAge61To90 InventoryAgeBucket = "61-90"
+	InventoryAgeOver90 InventoryAgeBucket = "90+"
+)
+
+// InventorySummary is one make/status/age bucket group of vehicles
+type InventorySummary struct {
+	Make              string             `db:"make"`
+	Status            VehicleStatus      `db:"status"`
+	Age_Bucket        InventoryAgeBucket `db:"age_bucket"`
+	Units             int                `db:"units"`
+	Total_Value       float64            `db:"total_value"`
+	Total_Days_On_Lot int                `db:"total_days_on_lot"`
+}
+
+// ModelTurnover compares what is on the lot for a make/model with what sold
+type ModelTurnover struct {
+	Make                 string  `db:"make"`
+	Model                string  `db:"model"`
+	Units_On_Lot         int     `db:"units_on_lot"`
+	Units_Sold           int     `db:"units_sold"`
+	Average_Days_To_Sell float64 `db:"average_days_to_sell"`
+}
@@ -0,0 +1,24 @@
+package mysql
+
+import "time"
+
+type ReservationStatus string
+
+const (
+	ReservationStatusActive    ReservationStatus = "active"
+	ReservationStatusReleased  ReservationStatus = "released"
+	ReservationStatusExpired   ReservationStatus = "expired"
+	ReservationStatusCompleted ReservationStatus = "completed"
+)
+
+type Reservation struct {
+	ID          string            `json:"id" db:"id"`
+	Vehicle_ID  string            `json:"vehicle_id" db:"vehicle_id"`
+	Customer_ID string            `json:"customer_id" db:"customer_id"`
+	Status      ReservationStatus `json:"status" db:"status"`
+	Reserved_At time.Time         `json:"reserved_at" db:"reserved_at"`
+	Expires_At  time.Time         `json:"expires_at" db:"expires_at"`
+	Released_At *time.Time        `json:"released_at" db:"released_at"`
+	Created_At  time.Time         `json:"created_at" db:"created_at"`
+	Updated_At  time.Time         `json:"updated_at" db:"updated_at"`
+}
@@ -16,22 +16,43 @@ const (
 	SaleStatusPending   SaleStatus = "pending"
 	SaleStatusCompleted SaleStatus = "completed"
 	SaleStatusCancelled SaleStatus = "cancelled"
+	SaleStatusRejected  SaleStatus = "rejected"
 )
 
 type Sale struct {
-	ID             string        `json:"id" db:"id"`
-	Vehicle_ID     string        `json:"vehicle_id" db:"vehicle_id"`
-	Customer_ID    string        `json:"customer_id" db:"customer_id"`
-	Salesperson_ID string        `json:"salesperson_id" db:"salesperson_id"`
-	Sale_Date      time.Time     `json:"sale_date" db:"sale_date"`
-	Sale_Price     float64       `json:"sale_price" db:"sale_price"`
-	Down_Payment   float64       `json:"down_payment" db:"down_payment"`
-	Finance_Amount float64       `json:"finance_amount" db:"finance_amount"`
-	Finance_Term   int           `json:"finance_term" db:"finance_term"`
-	Interest_Rate  float64       `json:"interest_rate" db:"interest_rate"`
-	Payment_Method PaymentMethod `json:"payment_method" db:"payment_method"`
-	Status         SaleStatus    `json:"status" db:"status"`
-	Notes          string        `json:"notes" db:"notes"`
-	Created_At     time.Time     `json:"created_at" db:"created_at"`
-	Updated_At     time.Time     `json:"updated_at" db:"updated_at"`
+	ID             string    `json:"id" db:"id"`
+	Vehicle_ID     string    `json:"vehicle_id" db:"vehicle_id"`
+	Customer_ID    string    `json:"customer_id" db:"customer_id"`
+	Salesperson_ID string    `json:"salesperson_id" db:"salesperson_id"`
+	Sale_Date      time.Time `json:"sale_date" db:"sale_date"`
+	Sale_Price     float64   `json:"sale_price" db:"sale_price"`
+	Discount       float64   `json:"discount" db:"discount"`
+
+	Sales_Tax          float64 `json:"sales_tax" db:"sales_tax"`
+	Doc_Fee            float64 `json:"doc_fee" db:"doc_fee"`
+	Registration_Fee   float64 `json:"registration_fee" db:"registration_fee"`
+	Title_Fee          float64 `json:"title_fee" db:"title_fee"`
+	Out_The_Door_Price float64 `json:"out_the_door_price" db:"out_the_door_price"`
+
+	Down_Payment    float64       `json:"down_payment" db:"down_payment"`
+	Trade_In_Credit float64       `json:"trade_in_credit" db:"trade_in_credit"`
+	Finance_Amount  float64       `json:"finance_amount" db:"finance_amount"`
+	Finance_Term    int           `json:"finance_term" db:"finance_term"`
+	Interest_Rate   float64       `json:"interest_rate" db:"interest_rate"`
+	Monthly_Payment float64       `json:"monthly_payment" db:"monthly_payment"`
+	Residual_Value  float64       `json:"residual_value" db:"residual_value"`
+	Money_Factor    float64       `json:"money_factor" db:"money_factor"`
+	Annual_Mileage  int           `json:"annual_mileage" db:"annual_mileage"`
+	Payment_Method  PaymentMethod `json:"payment_method" db:"payment_method"`
+	Commission      float64       `json:"commission" db:"commission"`
+	Status          SaleStatus    `json:"status" db:"status"`
+	Notes           string        `json:"notes" db:"notes"`
+	Approval_Reason string        `json:"approval_reason,omitempty" db:"approval_reason"`
+
+	Completed_At        *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	Cancelled_At        *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	Cancellation_Reason string     `json:"cancellation_reason,omitempty" db:"cancellation_reason"`
+
+	Created_At time.Time `json:"created_at" db:"created_at"`
+	Updated_At time.Time `json:"updated_at" db:"updated_at"`
 }
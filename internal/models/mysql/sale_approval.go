@@ -0,0 +1,20 @@
+package mysql
+
+import "time"
+
+type ApprovalDecision string
+
+const (
+	ApprovalDecisionApproved ApprovalDecision = "approved"
+	ApprovalDecisionRejected ApprovalDecision = "rejected"
+)
+
+// SaleApproval records a manager's decision on a pending sale
+type SaleApproval struct {
+	ID         string           `json:"id" db:"id"`
+	Sale_ID    string           `json:"sale_id" db:"sale_id"`
+	Manager_ID string           `json:"manager_id" db:"manager_id"`
+	Decision   ApprovalDecision `json:"decision" db:"decision"`
+	Notes      string           `json:"notes" db:"notes"`
+	Decided_At time.Time        `json:"decided_at" db:"decided_at"`
+}
@@ -0,0 +1,19 @@
+package mysql
+
+import "time"
+
+// SaleContract is the agreement generated for a completed sale, kept in both
+// of the formats it is served in along with a SHA-256 of each. Signature is
+// an HMAC-SHA256 over the contract's identity and hashes under the server's
+// contract signing key.
+type SaleContract struct {
+	ID           string    `json:"id" db:"id"`
+	Sale_ID      string    `json:"sale_id" db:"sale_id"`
+	Terms        string    `json:"terms" db:"terms"`
+	HTML         string    `json:"-" db:"html"`
+	HTML_SHA256  string    `json:"html_sha256" db:"html_sha256"`
+	PDF          []byte    `json:"-" db:"pdf"`
+	PDF_SHA256   string    `json:"pdf_sha256" db:"pdf_sha256"`
+	Signature    string    `json:"signature" db:"signature"`
+	Generated_At time.Time `json:"generated_at" db:"generated_at"`
+}
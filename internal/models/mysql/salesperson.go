@@ -10,6 +10,13 @@ const (
 	SalesPersonStatusTerminated SalesPersonStatus = "terminated"
 )
 
+type SalesPersonRole string
+
+const (
+	SalesPersonRoleSales   SalesPersonRole = "sales"
+	SalesPersonRoleManager SalesPersonRole = "manager"
+)
+
 type Salesperson struct {
 	ID          string            `json:"id" db:"id"`
 	Employee_ID string            `json:"employee_id" db:"employee_id"`
@@ -20,6 +27,7 @@ type Salesperson struct {
 	Hire_Date   time.Time         `json:"hire_date" db:"hire_date"`
 	Commission  float64           `json:"commission" db:"commission"`
 	Department  string            `json:"department" db:"department"`
+	Role        SalesPersonRole   `json:"role" db:"role"`
 	Status      SalesPersonStatus `json:"status" db:"status"`
 	Created_At  time.Time         `json:"created_at" db:"created_at"`
 	Updated_At  time.Time         `json:"updated_at" db:"updated_at"`
@@ -0,0 +1,43 @@
+package mysql
+
+import "time"
+
+type TradeInCondition string
+
+const (
+	TradeInConditionExcellent TradeInCondition = "excellent"
+	TradeInConditionGood      TradeInCondition = "good"
+	TradeInConditionFair      TradeInCondition = "fair"
+	TradeInConditionPoor      TradeInCondition = "poor"
+)
+
+type TradeInStatus string
+
+const (
+	TradeInStatusOffered  TradeInStatus = "offered"
+	TradeInStatusApplied  TradeInStatus = "applied"
+	TradeInStatusExpired  TradeInStatus = "expired"
+	TradeInStatusReturned TradeInStatus = "returned"
+)
+
+// TradeIn is an appraisal of a customer's vehicle and the offer made for it.
+// Sale_ID and Vehicle_ID are set once the offer is applied to a sale and the
+// car has been taken into inventory.
+type TradeIn struct {
+	ID           string           `json:"id" db:"id"`
+	Customer_ID  string           `json:"customer_id" db:"customer_id"`
+	VIN          string           `json:"vin" db:"vin"`
+	Make         string           `json:"make" db:"make"`
+	Model        string           `json:"model" db:"model"`
+	Year         int              `json:"year" db:"year"`
+	Color        string           `json:"color" db:"color"`
+	Mileage      int              `json:"mileage" db:"mileage"`
+	Condition    TradeInCondition `json:"condition" db:"condition"`
+	Offer_Amount float64          `json:"offer_amount" db:"offer_amount"`
+	Status       TradeInStatus    `json:"status" db:"status"`
+	Sale_ID      *string          `json:"sale_id" db:"sale_id"`
+	Vehicle_ID   *string          `json:"vehicle_id" db:"vehicle_id"`
+	Expires_At   time.Time        `json:"expires_at" db:"expires_at"`
+	Created_At   time.Time        `json:"created_at" db:"created_at"`
+	Updated_At   time.Time        `json:"updated_at" db:"updated_at"`
+}
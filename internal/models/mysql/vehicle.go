@@ -33,7 +33,10 @@ type Vehicle struct {
 	Status       VehicleStatus `json:"status" db:"status"`
 	Engine_Type  string        `json:"engine_type" db:"engine_type"`
 	Transmission string        `json:"transmission" db:"transmission"`
-	Fuel_Type    FuelType `json:"fuel_type" db:"fuel_type"`
+	Fuel_Type    FuelType      `json:"fuel_type" db:"fuel_type"`
+	Version      int           `json:"version" db:"version"`
+	Deleted_At   *time.Time    `json:"deleted_at,omitempty" db:"deleted_at"`
+	Listed_At    time.Time     `json:"listed_at" db:"listed_at"`
 	Created_At   time.Time     `json:"created_at" db:"created_at"`
 	Updated_At   time.Time     `json:"updated_at" db:"updated_at"`
 }
@@ -0,0 +1,14 @@
+package redis
+
+import "time"
+
+type SalesSession struct {
+	ID             string    `json:"id" redis:"id"`
+	Customer_ID    string    `json:"customer_id" redis:"customer_id"`
+	Vehicle_ID     string    `json:"vehicle_id" redis:"vehicle_id"`
+	Salesperson_ID string    `json:"salesperson_id" redis:"salesperson_id"`
+	Status         string    `json:"status" redis:"status"`
+	Started_At     time.Time `json:"started_at" redis:"started_at"`
+	Expires_At     time.Time `json:"expires_at" redis:"expires_at"`
+	Updated_At     time.Time `json:"updated_at" redis:"updated_at"`
+}
@@ -2,32 +2,33 @@ package mongodb
 
 import (
 	"api-servers/internal/models/mongodb"
+	"context"
 )
 
 type UserRepository interface {
-	Create(user mongodb.User) error
-	GetByID(id string) (mongodb.User, error)
-	GetByEmail(email string) (mongodb.User, error)
-	GetAll() ([]mongodb.User, error)
-	Update(id string, user mongodb.User) error
-	Delete(id string) error
+	Create(ctx context.Context, user mongodb.User) error
+	GetByID(ctx context.Context, id string) (mongodb.User, error)
+	GetByEmail(ctx context.Context, email string) (mongodb.User, error)
+	GetAll(ctx context.Context) ([]mongodb.User, error)
+	Update(ctx context.Context, id string, user mongodb.User) error
+	Delete(ctx context.Context, id string) error
 }
 
 type ProductRepository interface {
-	Create(product mongodb.Product) error
-	GetByID(id string) (mongodb.Product, error)
-	GetByCategory(category string) ([]mongodb.Product, error)
-	GetAll() ([]mongodb.Product, error)
-	Update(id string, product mongodb.Product) error
-	Delete(id string) error
+	Create(ctx context.Context, product mongodb.Product) error
+	GetByID(ctx context.Context, id string) (mongodb.Product, error)
+	GetByCategory(ctx context.Context, category string) ([]mongodb.Product, error)
+	GetAll(ctx context.Context) ([]mongodb.Product, error)
+	Update(ctx context.Context, id string, product mongodb.Product) error
+	Delete(ctx context.Context, id string) error
 }
 
 type OrderRepository interface {
-	Create(order mongodb.Order) error
-	GetByID(id string) (mongodb.Order, error)
-	GetByUserID(user_id string) ([]mongodb.Order, error)
-	GetByStatus(status string) ([]mongodb.Order, error)
-	GetAll() ([]mongodb.Order, error)
-	Update(id string, order mongodb.Order) error
-	Delete(id string) error
+	Create(ctx context.Context, order mongodb.Order) error
+	GetByID(ctx context.Context, id string) (mongodb.Order, error)
+	GetByUserID(ctx context.Context, user_id string) ([]mongodb.Order, error)
+	GetByStatus(ctx context.Context, status string) ([]mongodb.Order, error)
+	GetAll(ctx context.Context) ([]mongodb.Order, error)
+	Update(ctx context.Context, id string, order mongodb.Order) error
+	Delete(ctx context.Context, id string) error
 }
@@ -20,59 +20,59 @@ func NewOrderRepository(db *Database) OrderRepository {
 	}
 }
 
-func (r *orderRepository) Create(order mongodb.Order) error {
-	_, err := r.collection.InsertOne(context.Background(), order)
+func (r *orderRepository) Create(ctx context.Context, order mongodb.Order) error {
+	_, err := r.collection.InsertOne(ctx, order)
 	return err
 }
 
-func (r *orderRepository) GetByID(id string) (mongodb.Order, error) {
+func (r *orderRepository) GetByID(ctx context.Context, id string) (mongodb.Order, error) {
 	var order mongodb.Order
-	err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&order)
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&order)
 	return order, err
 }
 
-func (r *orderRepository) GetByUserID(user_id string) ([]mongodb.Order, error) {
-	cursor, err := r.collection.Find(context.Background(), bson.M{"user_id": user_id})
+func (r *orderRepository) GetByUserID(ctx context.Context, user_id string) ([]mongodb.Order, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": user_id})
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var orders []mongodb.Order
-	err = cursor.All(context.Background(), &orders)
+	err = cursor.All(ctx, &orders)
 	return orders, err
 }
 
-func (r *orderRepository) GetByStatus(status string) ([]mongodb.Order, error) {
-	cursor, err := r.collection.Find(context.Background(), bson.M{"status": status})
+func (r *orderRepository) GetByStatus(ctx context.Context, status string) ([]mongodb.Order, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"status": status})
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var orders []mongodb.Order
-	err = cursor.All(context.Background(), &orders)
+	err = cursor.All(ctx, &orders)
 	return orders, err
 }
 
-func (r *orderRepository) GetAll() ([]mongodb.Order, error) {
-	cursor, err := r.collection.Find(context.Background(), bson.M{})
+func (r *orderRepository) GetAll(ctx context.Context) ([]mongodb.Order, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{})
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var orders []mongodb.Order
-	err = cursor.All(context.Background(), &orders)
+	err = cursor.All(ctx, &orders)
 	return orders, err
 }
 
-func (r *orderRepository) Update(id string, user mongodb.Order) error {
-	_, err := r.collection.ReplaceOne(context.Background(), bson.M{"_id": id}, user)
+func (r *orderRepository) Update(ctx context.Context, id string, user mongodb.Order) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": id}, user)
 	return err
 }
 
-func (r *orderRepository) Delete(id string) error {
-	_, err := r.collection.DeleteOne(context.Background(), bson.M{"_id": id})
+func (r *orderRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
 }
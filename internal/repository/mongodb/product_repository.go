@@ -20,47 +20,47 @@ func NewProductRepository(db *Database) ProductRepository {
 	}
 }
 
-func (r *productRepository) Create(product mongodb.Product) error {
-	_, err := r.collection.InsertOne(context.Background(), product)
+func (r *productRepository) Create(ctx context.Context, product mongodb.Product) error {
+	_, err := r.collection.InsertOne(ctx, product)
 	return err
 }
 
-func (r *productRepository) GetByID(id string) (mongodb.Product, error) {
+func (r *productRepository) GetByID(ctx context.Context, id string) (mongodb.Product, error) {
 	var product mongodb.Product
-	err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&product)
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&product)
 	return product, err
 }
 
-func (r *productRepository) GetByCategory(category string) ([]mongodb.Product, error) {
-	cursor, err := r.collection.Find(context.Background(), bson.M{"category": category})
+func (r *productRepository) GetByCategory(ctx context.Context, category string) ([]mongodb.Product, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"category": category})
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var products []mongodb.Product
-	err = cursor.All(context.Background(), &products)
+	err = cursor.All(ctx, &products)
 	return products, err
 }
 
-func (r *productRepository) GetAll() ([]mongodb.Product, error) {
-	cursor, err := r.collection.Find(context.Background(), bson.M{})
+func (r *productRepository) GetAll(ctx context.Context) ([]mongodb.Product, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{})
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var products []mongodb.Product
-	err = cursor.All(context.Background(), &products)
+	err = cursor.All(ctx, &products)
 	return products, err
 }
 
-func (r *productRepository) Update(id string, product mongodb.Product) error {
-	_, err := r.collection.ReplaceOne(context.Background(), bson.M{"_id": id}, product)
+func (r *productRepository) Update(ctx context.Context, id string, product mongodb.Product) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": id}, product)
 	return err
 }
 
-func (r *productRepository) Delete(id string) error {
-	_, err := r.collection.DeleteOne(context.Background(), bson.M{"_id": id})
+func (r *productRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
 }
@@ -20,41 +20,41 @@ func NewUserRepository(db *Database) UserRepository {
 	}
 }
 
-func (r *userRepository) Create(user mongodb.User) error {
-	_, err := r.collection.InsertOne(context.Background(), user)
+func (r *userRepository) Create(ctx context.Context, user mongodb.User) error {
+	_, err := r.collection.InsertOne(ctx, user)
 	return err
 }
 
-func (r *userRepository) GetByID(id string) (mongodb.User, error) {
+func (r *userRepository) GetByID(ctx context.Context, id string) (mongodb.User, error) {
 	var user mongodb.User
-	err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&user)
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
 	return user, err
 }
 
-func (r *userRepository) GetByEmail(email string) (mongodb.User, error) {
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (mongodb.User, error) {
 	var user mongodb.User
-	err := r.collection.FindOne(context.Background(), bson.M{"email": email}).Decode(&user)
+	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
 	return user, err
 }
 
-func (r *userRepository) GetAll() ([]mongodb.User, error) {
-	cursor, err := r.collection.Find(context.Background(), bson.M{})
+func (r *userRepository) GetAll(ctx context.Context) ([]mongodb.User, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{})
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var users []mongodb.User
-	err = cursor.All(context.Background(), &users)
+	err = cursor.All(ctx, &users)
 	return users, err
 }
 
-func (r *userRepository) Update(id string, order mongodb.User) error {
-	_, err := r.collection.ReplaceOne(context.Background(), bson.M{"_id": id}, order)
+func (r *userRepository) Update(ctx context.Context, id string, order mongodb.User) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": id}, order)
 	return err
 }
 
-func (r *userRepository) Delete(id string) error {
-	_, err := r.collection.DeleteOne(context.Background(), bson.M{"_id": id})
+func (r *userRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
 }
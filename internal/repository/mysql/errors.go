@@ -0,0 +1,30 @@
+package mysql
+
+import (
+	"errors"
+
+	driver "github.com/go-sql-driver/mysql"
+)
+
+// ErrConflict is wrapped by write methods that lose an optimistic concurrency
+// check, i.e. the row exists but changed underneath the caller
+var ErrConflict = errors.New("concurrent modification")
+
+// ErrNotFound is wrapped when a lookup matches no rows and callers need to
+// tell that apart from a failed query
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalidQuery is wrapped when list options or filters cannot be turned into SQL
+var ErrInvalidQuery = errors.New("invalid query")
+
+// ErrDuplicate is wrapped when a write would repeat a unique value, such as a
+// VIN or email, that another live row already has
+var ErrDuplicate = errors.New("already exists")
+
+// mysqlDuplicateEntry is the MySQL error number for a unique key violation
+const mysqlDuplicateEntry = 1062
+
+func isDuplicateKey(err error) bool {
+	var mysqlErr *driver.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry
+}
@@ -2,53 +2,105 @@ package mysql
 
 import (
 	"api-servers/internal/models/mysql"
+	"context"
+	"time"
 )
 
 type VehicleRepository interface {
-	Create(vehicle mysql.Vehicle) error
-	GetByID(id string) (mysql.Vehicle, error)
-	GetByVin(vin string) (mysql.Vehicle, error)
-	GetByMake(make string) ([]mysql.Vehicle, error)
-	GetByStatus(status string) ([]mysql.Vehicle, error)
-	GetByPriceRange(minPrice, maxPrice float64) ([]mysql.Vehicle, error)
-	GetAll() ([]mysql.Vehicle, error)
-	Update(id string, vehicle mysql.Vehicle) error
-	Delete(id string) error
+	Create(ctx context.Context, vehicle mysql.Vehicle) error
+	GetByID(ctx context.Context, id string) (mysql.Vehicle, error)
+	GetByIDs(ctx context.Context, ids []string) ([]mysql.Vehicle, error)
+	GetByVin(ctx context.Context, vin string) (mysql.Vehicle, error)
+	GetByMake(ctx context.Context, make string) ([]mysql.Vehicle, error)
+	GetByStatus(ctx context.Context, status string) ([]mysql.Vehicle, error)
+	GetByPriceRange(ctx context.Context, minPrice, maxPrice float64) ([]mysql.Vehicle, error)
+	GetAll(ctx context.Context) ([]mysql.Vehicle, error)
+	List(ctx context.Context, filter mysql.VehicleFilter, opts mysql.ListOptions) ([]mysql.Vehicle, error)
+	Search(ctx context.Context, filter mysql.VehicleFilter, opts mysql.ListOptions) ([]mysql.Vehicle, error)
+	SummarizeInventory(ctx context.Context, asOf time.Time) ([]mysql.InventorySummary, error)
+	TurnoverByModel(ctx context.Context, since time.Time) ([]mysql.ModelTurnover, error)
+	Count(ctx context.Context, filter mysql.VehicleFilter) (int, error)
+	Update(ctx context.Context, id string, vehicle mysql.Vehicle) error
+	UpdateStatus(ctx context.Context, id string, status mysql.VehicleStatus, from ...mysql.VehicleStatus) error
+	Delete(ctx context.Context, id string, from ...mysql.VehicleStatus) error
 }
 
 type CustomerRepository interface {
-	Create(customer mysql.Customer) error
-	GetByID(id string) (mysql.Customer, error)
-	GetByEmail(email string) (mysql.Customer, error)
-	GetByPhone(phone string) (mysql.Customer, error)
-	GetByName(first_name, last_name string) (mysql.Customer, error)
-	GetAll() ([]mysql.Customer, error)
-	Update(id string, customer mysql.Customer) error
-	Delete(id string) error
+	Create(ctx context.Context, customer mysql.Customer) error
+	GetByID(ctx context.Context, id string) (mysql.Customer, error)
+	GetByEmail(ctx context.Context, email string) (mysql.Customer, error)
+	GetByPhone(ctx context.Context, phone string) (mysql.Customer, error)
+	GetByName(ctx context.Context, first_name, last_name string) (mysql.Customer, error)
+	GetAll(ctx context.Context) ([]mysql.Customer, error)
+	List(ctx context.Context, filter mysql.CustomerFilter, opts mysql.ListOptions) ([]mysql.Customer, error)
+	Count(ctx context.Context, filter mysql.CustomerFilter) (int, error)
+	Update(ctx context.Context, id string, customer mysql.Customer) error
+	Delete(ctx context.Context, id string) error
 }
 
 type SalespersonRepository interface {
-	Create(salesperson mysql.Salesperson) error
-	GetByID(id string) (mysql.Salesperson, error)
-	GetByEmployeeId(employeeId string) (mysql.Salesperson, error)
-	GetByEmail(email string) (mysql.Salesperson, error)
-	GetByDepartment(department string) ([]mysql.Salesperson, error)
-	GetByStatus(status mysql.SalesPersonStatus) ([]mysql.Salesperson, error)
-	GetAll() ([]mysql.Salesperson, error)
-	Update(id string, salesperson mysql.Salesperson) error
-	Delete(id string) error
+	Create(ctx context.Context, salesperson mysql.Salesperson) error
+	GetByID(ctx context.Context, id string) (mysql.Salesperson, error)
+	GetByEmployeeId(ctx context.Context, employeeId string) (mysql.Salesperson, error)
+	GetByEmail(ctx context.Context, email string) (mysql.Salesperson, error)
+	GetByDepartment(ctx context.Context, department string) ([]mysql.Salesperson, error)
+	GetByStatus(ctx context.Context, status mysql.SalesPersonStatus) ([]mysql.Salesperson, error)
+	GetAll(ctx context.Context) ([]mysql.Salesperson, error)
+	List(ctx context.Context, filter mysql.SalespersonFilter, opts mysql.ListOptions) ([]mysql.Salesperson, error)
+	Count(ctx context.Context, filter mysql.SalespersonFilter) (int, error)
+	Update(ctx context.Context, id string, salesperson mysql.Salesperson) error
+	Delete(ctx context.Context, id string) error
 }
 
 type SaleRepository interface {
-	Create(sale mysql.Sale) error
-	GetByID(id string) (mysql.Sale, error)
-	GetByCustomerId(customerId string) ([]mysql.Sale, error)
-	GetBySalespersonId(salespersonId string) ([]mysql.Sale, error)
-	GetByVehicleId(vehicleId string) (mysql.Sale, error)
-	GetByStatus(status mysql.SaleStatus) ([]mysql.Sale, error)
-	GetByPaymentMethod(method mysql.PaymentMethod) ([]mysql.Sale, error)
-	GetByDateRange(startDate, endDate string) ([]mysql.Sale, error)
-	GetAll() ([]mysql.Sale, error)
-	Update(id string, sale mysql.Sale) error
-	Delete(id string) error
+	Create(ctx context.Context, sale mysql.Sale) error
+	GetByID(ctx context.Context, id string) (mysql.Sale, error)
+	GetByCustomerId(ctx context.Context, customerId string) ([]mysql.Sale, error)
+	GetBySalespersonId(ctx context.Context, salespersonId string) ([]mysql.Sale, error)
+	GetByVehicleId(ctx context.Context, vehicleId string) (mysql.Sale, error)
+	GetByStatus(ctx context.Context, status mysql.SaleStatus) ([]mysql.Sale, error)
+	GetByPaymentMethod(ctx context.Context, method mysql.PaymentMethod) ([]mysql.Sale, error)
+	GetByDateRange(ctx context.Context, startDate, endDate string) ([]mysql.Sale, error)
+	SummarizeByVehicle(ctx context.Context, start, end time.Time) ([]mysql.VehicleSalesSummary, error)
+	SummarizeBySalesperson(ctx context.Context, start, end time.Time) ([]mysql.SalespersonSalesSummary, error)
+	TimeSeries(ctx context.Context, query mysql.SalesTimeSeriesQuery) ([]mysql.SalesTimeSeriesRow, error)
+	GetAll(ctx context.Context) ([]mysql.Sale, error)
+	Update(ctx context.Context, id string, sale mysql.Sale) error
+	UpdateStatus(ctx context.Context, id string, status mysql.SaleStatus, from ...mysql.SaleStatus) error
+	Complete(ctx context.Context, id string, at time.Time) error
+	Cancel(ctx context.Context, id string, reason string, at time.Time) error
+	Delete(ctx context.Context, id string) error
+}
+
+type ReservationRepository interface {
+	Create(ctx context.Context, reservation mysql.Reservation) error
+	GetByID(ctx context.Context, id string) (mysql.Reservation, error)
+	GetActiveByVehicleId(ctx context.Context, vehicleId string) (mysql.Reservation, error)
+	GetByCustomerId(ctx context.Context, customerId string) ([]mysql.Reservation, error)
+	GetExpired(ctx context.Context, asOf time.Time) ([]mysql.Reservation, error)
+	Update(ctx context.Context, id string, reservation mysql.Reservation) error
+}
+
+type CreditApplicationRepository interface {
+	Create(ctx context.Context, application mysql.CreditApplication) error
+	GetByCustomerId(ctx context.Context, customerId string) ([]mysql.CreditApplication, error)
+	GetLatestByCustomerId(ctx context.Context, customerId string) (mysql.CreditApplication, error)
+}
+
+type TradeInRepository interface {
+	Create(ctx context.Context, tradeIn mysql.TradeIn) error
+	GetByID(ctx context.Context, id string) (mysql.TradeIn, error)
+	GetBySaleId(ctx context.Context, saleId string) (mysql.TradeIn, error)
+	GetByCustomerId(ctx context.Context, customerId string) ([]mysql.TradeIn, error)
+	Update(ctx context.Context, id string, tradeIn mysql.TradeIn, from mysql.TradeInStatus) error
+}
+
+type SaleApprovalRepository interface {
+	Create(ctx context.Context, approval mysql.SaleApproval) error
+	GetBySaleId(ctx context.Context, saleId string) ([]mysql.SaleApproval, error)
+}
+
+type SaleContractRepository interface {
+	Create(ctx context.Context, contract mysql.SaleContract) error
+	GetBySaleId(ctx context.Context, saleId string) (mysql.SaleContract, error)
 }
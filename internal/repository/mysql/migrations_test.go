@@ -0,0 +1,138 @@
+package mysql
+
+import (
+	"api-servers/internal/models/mysql"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// newTestDatabase creates an empty database on the server MYSQL_TEST_DSN
+// points at, e.g. root:password@tcp(localhost:3306)/, and applies every
+// migration in schema/mysql in order the way the mysql image's init scripts
+// do. The database is dropped when the test ends.
+func newTestDatabase(t *testing.T) *Database {
+	t.Helper()
+
+	dsn := os.Getenv("MYSQL_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_TEST_DSN is not set")
+	}
+	config, err := driver.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("invalid MYSQL_TEST_DSN: %v", err)
+	}
+	config.DBName = ""
+	config.ParseTime = true
+	config.MultiStatements = true
+	// sales time series slot sale dates by UNIX_TIMESTAMP, which reads
+	// TIMESTAMP columns in the session's zone
+	config.Params = map[string]string{"time_zone": "'+00:00'"}
+
+	server, err := sqlx.Open("mysql", config.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to connect to MySQL: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	name := fmt.Sprintf("api_test_%d", time.Now().UnixNano())
+	if _, err := server.Exec("CREATE DATABASE " + name); err != nil {
+		t.Fatalf("failed to create database %s: %v", name, err)
+	}
+	t.Cleanup(func() { server.Exec("DROP DATABASE " + name) })
+
+	config.DBName = name
+	conn, err := sqlx.Open("mysql", config.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to connect to database %s: %v", name, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	migrations, err := filepath.Glob("../../../schema/mysql/*.sql")
+	if err != nil || len(migrations) == 0 {
+		t.Fatalf("no migrations found in schema/mysql: %v", err)
+	}
+	sort.Strings(migrations)
+	for _, migration := range migrations {
+		statements, err := os.ReadFile(migration)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", migration, err)
+		}
+		if _, err := conn.Exec(string(statements)); err != nil {
+			t.Fatalf("failed to apply %s: %v", filepath.Base(migration), err)
+		}
+	}
+
+	return &Database{Connection: conn}
+}
+
+func testVehicleRow(id, vin string, listedAt time.Time) mysql.Vehicle {
+	return mysql.Vehicle{
+		ID: id, VIN: vin, Make: "Honda", Model: "Accord", Year: 2021, Color: "blue",
+		Mileage: 20000, Price: 24000, Status: mysql.VehicleStatusAvailable, Fuel_Type: mysql.FuelTypeGasoline,
+		Listed_At: listedAt, Created_At: listedAt, Updated_At: listedAt,
+	}
+}
+
+func TestMigrationsApplyToAFreshDatabase(t *testing.T) {
+	db := newTestDatabase(t)
+
+	var indexes []struct {
+		Table     string `db:"table_name"`
+		Index     string `db:"index_name"`
+		NonUnique int    `db:"non_unique"`
+	}
+	err := db.Connection.Select(&indexes, `SELECT DISTINCT table_name AS table_name, index_name AS index_name, non_unique AS non_unique
+		FROM information_schema.statistics
+		WHERE table_schema = DATABASE() AND table_name IN ('vehicles', 'customers')`)
+	if err != nil {
+		t.Fatalf("failed to list indexes: %v", err)
+	}
+
+	found := map[string]int{}
+	for _, index := range indexes {
+		found[index.Table+"."+index.Index] = index.NonUnique
+	}
+	for index, nonUnique := range map[string]int{
+		"vehicles.idx_vehicles_vin":         1,
+		"vehicles.ux_vehicles_live_vin":     0,
+		"customers.idx_customers_email":     1,
+		"customers.ux_customers_live_email": 0,
+	} {
+		if got, ok := found[index]; !ok || got != nonUnique {
+			t.Errorf("index %s missing or with non_unique %d, want %d", index, got, nonUnique)
+		}
+	}
+	if _, ok := found["vehicles.vin"]; ok {
+		t.Error("the unique key on every vehicles.vin is still there")
+	}
+}
+
+func TestDeletedVINCanBeListedAgain(t *testing.T) {
+	db := newTestDatabase(t)
+	repo := NewVehicleRepository(db)
+	ctx := context.Background()
+
+	first := testVehicleRow("vehicle-1", "1HGCM82633A004352", time.Now())
+	if err := repo.Create(ctx, first); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if err := repo.Create(ctx, testVehicleRow("vehicle-2", first.VIN, time.Now())); !errors.Is(err, ErrDuplicate) {
+		t.Fatalf("Create with a live VIN error = %v, want ErrDuplicate", err)
+	}
+
+	if err := repo.Delete(ctx, first.ID); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if err := repo.Create(ctx, testVehicleRow("vehicle-2", first.VIN, time.Now())); err != nil {
+		t.Errorf("Create after the VIN was deleted returned error: %v", err)
+	}
+}
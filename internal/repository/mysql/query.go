@@ -0,0 +1,62 @@
+package mysql
+
+import (
+	"api-servers/internal/models/mysql"
+	"fmt"
+	"strings"
+)
+
+// whereClause accumulates AND-ed conditions and their positional arguments
+type whereClause struct {
+	conditions []string
+	args       []interface{}
+}
+
+func (w *whereClause) add(condition string, args ...interface{}) {
+	w.conditions = append(w.conditions, condition)
+	w.args = append(w.args, args...)
+}
+
+// in adds "column IN (?, ?, ...)" for values; an empty list adds nothing
+func (w *whereClause) in(column string, values []interface{}) {
+	if len(values) == 0 {
+		return
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+	w.add(column+" IN ("+placeholders+")", values...)
+}
+
+func (w *whereClause) String() string {
+	if len(w.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(w.conditions, " AND ")
+}
+
+// orderAndPage renders ORDER BY / LIMIT / OFFSET for opts. Only columns in
+// sortable can be sorted on; id is always appended so paging is stable.
+func orderAndPage(opts mysql.ListOptions, sortable map[string]bool, defaultSort string) (string, []interface{}, error) {
+	sort := opts.Sort
+	if sort == "" {
+		sort = defaultSort
+	}
+
+	direction := "ASC"
+	column := sort
+	if strings.HasPrefix(sort, "-") {
+		direction = "DESC"
+		column = sort[1:]
+	}
+
+	if !sortable[column] {
+		return "", nil, fmt.Errorf("cannot sort by %q: %w", column, ErrInvalidQuery)
+	}
+
+	clause := fmt.Sprintf(" ORDER BY %s %s, id %s", column, direction, direction)
+	var args []interface{}
+	if opts.Limit > 0 {
+		clause += " LIMIT ? OFFSET ?"
+		args = append(args, opts.Limit, opts.Offset)
+	}
+	return clause, args, nil
+}
@@ -0,0 +1,51 @@
+package mysql
+
+import (
+	"api-servers/internal/models/mysql"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type creditApplicationRepository struct {
+	conn executor
+}
+
+func NewCreditApplicationRepository(db *Database) CreditApplicationRepository {
+	return &creditApplicationRepository{
+		conn: db.Connection,
+	}
+}
+
+func (r *creditApplicationRepository) Create(ctx context.Context, application mysql.CreditApplication) error {
+	query := `INSERT INTO credit_applications (id, customer_id, bureau, annual_income, monthly_debt, credit_score, debt_to_income, approved, credit_limit, interest_rate, reason, created_at, expires_at)
+			  VALUES (:id, :customer_id, :bureau, :annual_income, :monthly_debt, :credit_score, :debt_to_income, :approved, :credit_limit, :interest_rate, :reason, :created_at, :expires_at)`
+	_, err := r.conn.NamedExecContext(ctx, query, application)
+	if err != nil {
+		return fmt.Errorf("failed to create credit application for customer %s: %w", application.Customer_ID, err)
+	}
+	return nil
+}
+
+func (r *creditApplicationRepository) GetByCustomerId(ctx context.Context, customerId string) ([]mysql.CreditApplication, error) {
+	applications := []mysql.CreditApplication{}
+	err := r.conn.SelectContext(ctx, &applications, "SELECT * FROM credit_applications WHERE customer_id = ? ORDER BY created_at DESC, id", customerId)
+
+	if err != nil {
+		return applications, fmt.Errorf("failed to get credit applications by customer_id %s: %w", customerId, err)
+	}
+	return applications, nil
+}
+
+func (r *creditApplicationRepository) GetLatestByCustomerId(ctx context.Context, customerId string) (mysql.CreditApplication, error) {
+	var application mysql.CreditApplication
+	err := r.conn.GetContext(ctx, &application, "SELECT * FROM credit_applications WHERE customer_id = ? ORDER BY created_at DESC, id LIMIT 1", customerId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return application, fmt.Errorf("credit application for customer %s not found: %w", customerId, ErrNotFound)
+		}
+		return application, fmt.Errorf("failed to get latest credit application for customer %s: %w", customerId, err)
+	}
+	return application, nil
+}
@@ -2,47 +2,52 @@ package mysql
 
 import (
 	"api-servers/internal/models/mysql"
+	"context"
 	"database/sql"
 	"fmt"
+	"time"
 )
 
 type customerRepository struct {
-	db *Database
+	conn executor
 }
 
 func NewCustomerRepository(db *Database) CustomerRepository {
 	return &customerRepository{
-		db: db,
+		conn: db.Connection,
 	}
 }
 
-func (r *customerRepository) Create(customer mysql.Customer) error {
-	query := `INSERT INTO customers (id, first_name, last_name, email, phone, address, city, state, zip_code, date_of_birth, credit_score, created_at, updated_at)
-			VALUES (:id, :first_name, :last_name, :email, :phone, :address, :city, :state, :zip_code, :date_of_birth, :credit_score, :created_at, :updated_at)`
-	_, err := r.db.Connection.NamedExec(query, customer)
+func (r *customerRepository) Create(ctx context.Context, customer mysql.Customer) error {
+	query := `INSERT INTO customers (id, first_name, last_name, email, phone, address, city, state, zip_code, date_of_birth, credit_score, annual_income, created_at, updated_at)
+			VALUES (:id, :first_name, :last_name, :email, :phone, :address, :city, :state, :zip_code, :date_of_birth, :credit_score, :annual_income, :created_at, :updated_at)`
+	_, err := r.conn.NamedExecContext(ctx, query, customer)
 
+	if isDuplicateKey(err) {
+		return fmt.Errorf("customer with email %s: %w", customer.Email, ErrDuplicate)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create customer: %w", err)
 	}
 	return nil
 }
 
-func (r *customerRepository) GetByID(id string) (mysql.Customer, error) {
+func (r *customerRepository) GetByID(ctx context.Context, id string) (mysql.Customer, error) {
 	var customer mysql.Customer
-	err := r.db.Connection.Get(&customer, "SELECT * FROM customers WHERE id = ?", id)
+	err := r.conn.GetContext(ctx, &customer, "SELECT * FROM customers WHERE id = ?", id)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return customer, fmt.Errorf("customer with id %s not found", id)
+			return customer, fmt.Errorf("customer with id %s not found: %w", id, ErrNotFound)
 		}
 		return customer, fmt.Errorf("failed to get customer by id %s: %w", id, err)
 	}
 	return customer, nil
 }
 
-func (r *customerRepository) GetByEmail(email string) (mysql.Customer, error) {
+func (r *customerRepository) GetByEmail(ctx context.Context, email string) (mysql.Customer, error) {
 	var customer mysql.Customer
-	err := r.db.Connection.Get(&customer, "SELECT * FROM customers WHERE email = ?", email)
+	err := r.conn.GetContext(ctx, &customer, "SELECT * FROM customers WHERE email = ? AND deleted_at IS NULL", email)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -53,9 +58,9 @@ func (r *customerRepository) GetByEmail(email string) (mysql.Customer, error) {
 	return customer, nil
 }
 
-func (r *customerRepository) GetByPhone(phone string) (mysql.Customer, error) {
+func (r *customerRepository) GetByPhone(ctx context.Context, phone string) (mysql.Customer, error) {
 	var customer mysql.Customer
-	err := r.db.Connection.Get(&customer, "SELECT * FROM customers WHERE phone = ?", phone)
+	err := r.conn.GetContext(ctx, &customer, "SELECT * FROM customers WHERE phone = ? AND deleted_at IS NULL", phone)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return customer, fmt.Errorf("customer with phone %s not found", phone)
@@ -65,9 +70,9 @@ func (r *customerRepository) GetByPhone(phone string) (mysql.Customer, error) {
 	return customer, nil
 }
 
-func (r *customerRepository) GetByName(first_name, last_name string) (mysql.Customer, error) {
+func (r *customerRepository) GetByName(ctx context.Context, first_name, last_name string) (mysql.Customer, error) {
 	var customer mysql.Customer
-	err := r.db.Connection.Get(&customer, "SELECT * FROM customers WHERE first_name = ? AND last_name = ?", first_name, last_name)
+	err := r.conn.GetContext(ctx, &customer, "SELECT * FROM customers WHERE first_name = ? AND last_name = ? AND deleted_at IS NULL", first_name, last_name)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return customer, fmt.Errorf("customer with name %s %s not found", first_name, last_name)
@@ -77,16 +82,51 @@ func (r *customerRepository) GetByName(first_name, last_name string) (mysql.Cust
 	return customer, nil
 }
 
-func (r *customerRepository) GetAll() ([]mysql.Customer, error) {
+func (r *customerRepository) GetAll(ctx context.Context) ([]mysql.Customer, error) {
 	var customers []mysql.Customer
-	err := r.db.Connection.Select(&customers, "SELECT * FROM customers")
+	err := r.conn.SelectContext(ctx, &customers, "SELECT * FROM customers WHERE deleted_at IS NULL")
 	if err != nil {
 		return customers, fmt.Errorf("failed to get customers: %w", err)
 	}
 	return customers, nil
 }
 
-func (r *customerRepository) Update(id string, customer mysql.Customer) error {
+var customerSortColumns = map[string]bool{
+	"first_name": true,
+	"last_name":  true,
+	"email":      true,
+	"city":       true,
+	"state":      true,
+	"created_at": true,
+}
+
+func (r *customerRepository) List(ctx context.Context, filter mysql.CustomerFilter, opts mysql.ListOptions) ([]mysql.Customer, error) {
+	where := customerWhere(filter)
+	order, page_args, err := orderAndPage(opts, customerSortColumns, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	customers := []mysql.Customer{}
+	err = r.conn.SelectContext(ctx, &customers, "SELECT * FROM customers"+where.String()+order, append(where.args, page_args...)...)
+	if err != nil {
+		return customers, fmt.Errorf("failed to list customers: %w", err)
+	}
+	return customers, nil
+}
+
+func (r *customerRepository) Count(ctx context.Context, filter mysql.CustomerFilter) (int, error) {
+	where := customerWhere(filter)
+
+	var count int
+	err := r.conn.GetContext(ctx, &count, "SELECT COUNT(*) FROM customers"+where.String(), where.args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count customers: %w", err)
+	}
+	return count, nil
+}
+
+func (r *customerRepository) Update(ctx context.Context, id string, customer mysql.Customer) error {
 	query := `UPDATE customers SET
                 first_name = :first_name,
                 last_name = :last_name,
@@ -98,11 +138,15 @@ func (r *customerRepository) Update(id string, customer mysql.Customer) error {
                 zip_code = :zip_code,
                 date_of_birth = :date_of_birth,
                 credit_score = :credit_score,
+                annual_income = :annual_income,
                 updated_at = :updated_at
-                WHERE id = :id`
+                WHERE id = :id AND deleted_at IS NULL`
 	customer.ID = id
 
-	result, err := r.db.Connection.NamedExec(query, customer)
+	result, err := r.conn.NamedExecContext(ctx, query, customer)
+	if isDuplicateKey(err) {
+		return fmt.Errorf("customer with email %s: %w", customer.Email, ErrDuplicate)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update customer %s: %w", id, err)
 	}
@@ -112,15 +156,17 @@ func (r *customerRepository) Update(id string, customer mysql.Customer) error {
 		return fmt.Errorf("failed to get rows affected for customer %s update: %w", id, err)
 	}
 	if rows_affected == 0 {
-		return fmt.Errorf("customer with id %s not found for update", id)
+		return fmt.Errorf("customer with id %s not found for update: %w", id, ErrNotFound)
 	}
 	return nil
 }
 
-func (r *customerRepository) Delete(id string) error {
-	query := `DELETE FROM customers WHERE id = ?`
+// Delete soft-deletes the customer so their sales history stays intact
+func (r *customerRepository) Delete(ctx context.Context, id string) error {
+	query := `UPDATE customers SET deleted_at = ?, updated_at = ? WHERE id = ? AND deleted_at IS NULL`
 
-	result, err := r.db.Connection.Exec(query, id)
+	now := time.Now()
+	result, err := r.conn.ExecContext(ctx, query, now, now, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete customer %s: %w", id, err)
 	}
@@ -129,7 +175,20 @@ func (r *customerRepository) Delete(id string) error {
 		return fmt.Errorf("failed to get rows affected for customer %s deletion: %w", id, err)
 	}
 	if rows_affected == 0 {
-		return fmt.Errorf("customer with id %s not found for deletion", id)
+		return fmt.Errorf("customer with id %s not found for deletion: %w", id, ErrNotFound)
 	}
 	return nil
 }
+
+func customerWhere(filter mysql.CustomerFilter) whereClause {
+	var where whereClause
+	where.add("deleted_at IS NULL")
+
+	if filter.City != "" {
+		where.add("city = ?", filter.City)
+	}
+	if filter.State != "" {
+		where.add("state = ?", filter.State)
+	}
+	return where
+}
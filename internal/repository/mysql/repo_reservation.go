@@ -0,0 +1,102 @@
+package mysql
+
+import (
+	"api-servers/internal/models/mysql"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type reservationRepository struct {
+	conn executor
+}
+
+func NewReservationRepository(db *Database) ReservationRepository {
+	return &reservationRepository{
+		conn: db.Connection,
+	}
+}
+
+func (r *reservationRepository) Create(ctx context.Context, reservation mysql.Reservation) error {
+	query := `INSERT INTO reservations (id, vehicle_id, customer_id, status, reserved_at, expires_at, released_at, created_at, updated_at)
+			  VALUES (:id, :vehicle_id, :customer_id, :status, :reserved_at, :expires_at, :released_at, :created_at, :updated_at)`
+	_, err := r.conn.NamedExecContext(ctx, query, reservation)
+	if err != nil {
+		return fmt.Errorf("failed to create reservation for vehicle %s: %w", reservation.Vehicle_ID, err)
+	}
+	return nil
+}
+
+func (r *reservationRepository) GetByID(ctx context.Context, id string) (mysql.Reservation, error) {
+	var reservation mysql.Reservation
+	err := r.conn.GetContext(ctx, &reservation, "SELECT * FROM reservations WHERE id = ?", id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return reservation, fmt.Errorf("reservation with id %s not found: %w", id, ErrNotFound)
+		}
+		return reservation, fmt.Errorf("failed to get reservation by id %s: %w", id, err)
+	}
+	return reservation, nil
+}
+
+func (r *reservationRepository) GetActiveByVehicleId(ctx context.Context, vehicleId string) (mysql.Reservation, error) {
+	var reservation mysql.Reservation
+	err := r.conn.GetContext(ctx, &reservation, "SELECT * FROM reservations WHERE vehicle_id = ? AND status = ?", vehicleId, mysql.ReservationStatusActive)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return reservation, fmt.Errorf("active reservation for vehicle %s not found: %w", vehicleId, ErrNotFound)
+		}
+		return reservation, fmt.Errorf("failed to get active reservation for vehicle %s: %w", vehicleId, err)
+	}
+	return reservation, nil
+}
+
+func (r *reservationRepository) GetByCustomerId(ctx context.Context, customerId string) ([]mysql.Reservation, error) {
+	var reservations []mysql.Reservation
+	err := r.conn.SelectContext(ctx, &reservations, "SELECT * FROM reservations WHERE customer_id = ? ORDER BY reserved_at DESC", customerId)
+
+	if err != nil {
+		return reservations, fmt.Errorf("failed to get reservations by customer_id %s: %w", customerId, err)
+	}
+	return reservations, nil
+}
+
+func (r *reservationRepository) GetExpired(ctx context.Context, asOf time.Time) ([]mysql.Reservation, error) {
+	var reservations []mysql.Reservation
+	err := r.conn.SelectContext(ctx, &reservations, "SELECT * FROM reservations WHERE status = ? AND expires_at <= ?", mysql.ReservationStatusActive, asOf)
+
+	if err != nil {
+		return reservations, fmt.Errorf("failed to get reservations expired as of %s: %w", asOf.Format(time.RFC3339), err)
+	}
+	return reservations, nil
+}
+
+func (r *reservationRepository) Update(ctx context.Context, id string, reservation mysql.Reservation) error {
+	query := `UPDATE reservations SET
+				vehicle_id = :vehicle_id,
+				customer_id = :customer_id,
+				status = :status,
+				reserved_at = :reserved_at,
+				expires_at = :expires_at,
+				released_at = :released_at,
+				updated_at = :updated_at
+				WHERE id = :id`
+	reservation.ID = id
+
+	result, err := r.conn.NamedExecContext(ctx, query, reservation)
+	if err != nil {
+		return fmt.Errorf("failed to update reservation %s: %w", id, err)
+	}
+
+	rows_affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected for reservation %s update: %w", id, err)
+	}
+	if rows_affected == 0 {
+		return fmt.Errorf("reservation with id %s not found for update: %w", id, ErrNotFound)
+	}
+	return nil
+}
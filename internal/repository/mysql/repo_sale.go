@@ -2,46 +2,49 @@ package mysql
 
 import (
 	"api-servers/internal/models/mysql"
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 )
 
 type saleRepository struct {
-	db *Database
+	conn executor
 }
 
 func NewSaleRepository(db *Database) SaleRepository {
 	return &saleRepository{
-		db: db,
+		conn: db.Connection,
 	}
 }
 
-func (r *saleRepository) Create(sale mysql.Sale) error {
-	query := `INSERT INTO sales (id, vehicle_id, customer_id, salesperson_id, sale_date, sale_price, down_payment, finance_amount, finance_term, interest_rate, payment_method, status, notes, created_at, updated_at)
-			  VALUES (:id, :vehicle_id, :customer_id, :salesperson_id, :sale_date, :sale_price, :down_payment, :finance_amount, :finance_term, :interest_rate, :payment_method, :status, :notes, :created_at, :updated_at)`
-	_, err := r.db.Connection.NamedExec(query, sale)
+func (r *saleRepository) Create(ctx context.Context, sale mysql.Sale) error {
+	query := `INSERT INTO sales (id, vehicle_id, customer_id, salesperson_id, sale_date, sale_price, discount, sales_tax, doc_fee, registration_fee, title_fee, out_the_door_price, down_payment, trade_in_credit, finance_amount, finance_term, interest_rate, monthly_payment, residual_value, money_factor, annual_mileage, payment_method, commission, status, notes, approval_reason, completed_at, cancelled_at, cancellation_reason, created_at, updated_at)
+			  VALUES (:id, :vehicle_id, :customer_id, :salesperson_id, :sale_date, :sale_price, :discount, :sales_tax, :doc_fee, :registration_fee, :title_fee, :out_the_door_price, :down_payment, :trade_in_credit, :finance_amount, :finance_term, :interest_rate, :monthly_payment, :residual_value, :money_factor, :annual_mileage, :payment_method, :commission, :status, :notes, :approval_reason, :completed_at, :cancelled_at, :cancellation_reason, :created_at, :updated_at)`
+	_, err := r.conn.NamedExecContext(ctx, query, sale)
 	if err != nil {
 		return fmt.Errorf("failed to create sale with id %s: %w", sale.ID, err)
 	}
 	return nil
 }
 
-func (r *saleRepository) GetByID(id string) (mysql.Sale, error) {
+func (r *saleRepository) GetByID(ctx context.Context, id string) (mysql.Sale, error) {
 	var sale mysql.Sale
-	err := r.db.Connection.Get(&sale, "SELECT * FROM sales WHERE id = ?", id)
+	err := r.conn.GetContext(ctx, &sale, "SELECT * FROM sales WHERE id = ?", id)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return sale, fmt.Errorf("sale with id %s not found", id)
+			return sale, fmt.Errorf("sale with id %s not found: %w", id, ErrNotFound)
 		}
 		return sale, fmt.Errorf("failed to get sale by id %s: %w", id, err)
 	}
 	return sale, nil
 }
 
-func (r *saleRepository) GetByCustomerId(customerId string) ([]mysql.Sale, error) {
+func (r *saleRepository) GetByCustomerId(ctx context.Context, customerId string) ([]mysql.Sale, error) {
 	var sales []mysql.Sale
-	err := r.db.Connection.Select(&sales, "SELECT * FROM sales WHERE customer_id = ?", customerId)
+	err := r.conn.SelectContext(ctx, &sales, "SELECT * FROM sales WHERE customer_id = ? ORDER BY sale_date DESC", customerId)
 
 	if err != nil {
 		return sales, fmt.Errorf("failed to get sales by customer_id %s: %w", customerId, err)
@@ -49,9 +52,9 @@ func (r *saleRepository) GetByCustomerId(customerId string) ([]mysql.Sale, error
 	return sales, nil
 }
 
-func (r *saleRepository) GetBySalespersonId(salespersonId string) ([]mysql.Sale, error) {
+func (r *saleRepository) GetBySalespersonId(ctx context.Context, salespersonId string) ([]mysql.Sale, error) {
 	var sales []mysql.Sale
-	err := r.db.Connection.Select(&sales, "SELECT * FROM sales WHERE salesperson_id = ?", salespersonId)
+	err := r.conn.SelectContext(ctx, &sales, "SELECT * FROM sales WHERE salesperson_id = ?", salespersonId)
 
 	if err != nil {
 		return sales, fmt.Errorf("failed to get sales by salesperson_id %s: %w", salespersonId, err)
@@ -59,9 +62,9 @@ func (r *saleRepository) GetBySalespersonId(salespersonId string) ([]mysql.Sale,
 	return sales, nil
 }
 
-func (r *saleRepository) GetByVehicleId(vehicleId string) (mysql.Sale, error) {
+func (r *saleRepository) GetByVehicleId(ctx context.Context, vehicleId string) (mysql.Sale, error) {
 	var sale mysql.Sale
-	err := r.db.Connection.Get(&sale, "SELECT * FROM sales WHERE vehicle_id = ?", vehicleId)
+	err := r.conn.GetContext(ctx, &sale, "SELECT * FROM sales WHERE vehicle_id = ?", vehicleId)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -72,9 +75,9 @@ func (r *saleRepository) GetByVehicleId(vehicleId string) (mysql.Sale, error) {
 	return sale, nil
 }
 
-func (r *saleRepository) GetByStatus(status mysql.SaleStatus) ([]mysql.Sale, error) {
+func (r *saleRepository) GetByStatus(ctx context.Context, status mysql.SaleStatus) ([]mysql.Sale, error) {
 	var sales []mysql.Sale
-	err := r.db.Connection.Select(&sales, "SELECT * FROM sales WHERE status = ?", status)
+	err := r.conn.SelectContext(ctx, &sales, "SELECT * FROM sales WHERE status = ? ORDER BY sale_date, id", status)
 
 	if err != nil {
 		return sales, fmt.Errorf("failed to get sales by status %s: %w", status, err)
@@ -82,9 +85,9 @@ func (r *saleRepository) GetByStatus(status mysql.SaleStatus) ([]mysql.Sale, err
 	return sales, nil
 }
 
-func (r *saleRepository) GetByPaymentMethod(method mysql.PaymentMethod) ([]mysql.Sale, error) {
+func (r *saleRepository) GetByPaymentMethod(ctx context.Context, method mysql.PaymentMethod) ([]mysql.Sale, error) {
 	var sales []mysql.Sale
-	err := r.db.Connection.Select(&sales, "SELECT * FROM sales WHERE payment_method = ?", method)
+	err := r.conn.SelectContext(ctx, &sales, "SELECT * FROM sales WHERE payment_method = ?", method)
 
 	if err != nil {
 		return sales, fmt.Errorf("failed to get sales by payment_method %s: %w", method, err)
@@ -92,9 +95,9 @@ func (r *saleRepository) GetByPaymentMethod(method mysql.PaymentMethod) ([]mysql
 	return sales, nil
 }
 
-func (r *saleRepository) GetByDateRange(startDate, endDate string) ([]mysql.Sale, error) {
+func (r *saleRepository) GetByDateRange(ctx context.Context, startDate, endDate string) ([]mysql.Sale, error) {
 	var sales []mysql.Sale
-	err := r.db.Connection.Select(&sales, "SELECT * FROM sales WHERE sale_date BETWEEN ? AND ?", startDate, endDate)
+	err := r.conn.SelectContext(ctx, &sales, "SELECT * FROM sales WHERE sale_date BETWEEN ? AND ?", startDate, endDate)
 
 	if err != nil {
 		return sales, fmt.Errorf("failed to get sales by date range %s to %s: %w", startDate, endDate, err)
@@ -102,34 +105,137 @@ func (r *saleRepository) GetByDateRange(startDate, endDate string) ([]mysql.Sale
 	return sales, nil
 }
 
-func (r *saleRepository) GetAll() ([]mysql.Sale, error) {
+// SummarizeByVehicle groups sales made between start and end (inclusive) by
+// vehicle make/model/year and sale status
+func (r *saleRepository) SummarizeByVehicle(ctx context.Context, start, end time.Time) ([]mysql.VehicleSalesSummary, error) {
+	query := `SELECT v.make, v.model, v.year, s.status,
+				COUNT(*) AS units_sold,
+				SUM(s.sale_price) AS total_revenue
+			  FROM sales s
+			  JOIN vehicles v ON v.id = s.vehicle_id
+			  WHERE s.sale_date BETWEEN ? AND ?
+			  GROUP BY v.make, v.model, v.year, s.status
+			  ORDER BY total_revenue DESC, v.make, v.model, v.year`
+
+	summaries := []mysql.VehicleSalesSummary{}
+	err := r.conn.SelectContext(ctx, &summaries, query, start, end)
+	if err != nil {
+		return summaries, fmt.Errorf("failed to summarize sales by vehicle: %w", err)
+	}
+	return summaries, nil
+}
+
+// SummarizeBySalesperson totals each salesperson's sales between start and
+// end (inclusive), highest revenue first. Only completed sales earn revenue
+// and commission; sales waiting on approval and cancelled sales are counted
+// separately. Commission is only reversed for cancelled sales that had been
+// completed.
+func (r *saleRepository) SummarizeBySalesperson(ctx context.Context, start, end time.Time) ([]mysql.SalespersonSalesSummary, error) {
+	query := `SELECT sp.*,
+				COUNT(CASE WHEN s.status = 'completed' THEN s.id END) AS total_sales,
+				COALESCE(SUM(CASE WHEN s.status = 'completed' THEN s.sale_price END), 0) AS total_revenue,
+				COALESCE(SUM(CASE WHEN s.status = 'completed' THEN s.commission END), 0) AS commission_earned,
+				COUNT(CASE WHEN s.status = 'pending' THEN s.id END) AS pending_sales,
+				COALESCE(SUM(CASE WHEN s.status = 'pending' THEN s.sale_price END), 0) AS pending_revenue,
+				COUNT(CASE WHEN s.status = 'cancelled' THEN s.id END) AS cancelled_sales,
+				COALESCE(SUM(CASE WHEN s.status = 'cancelled' AND s.completed_at IS NOT NULL THEN s.commission END), 0) AS commission_reversed
+			  FROM salespersons sp
+			  LEFT JOIN sales s ON s.salesperson_id = sp.id AND s.sale_date BETWEEN ? AND ?
+			  GROUP BY sp.id
+			  ORDER BY total_revenue DESC, sp.id`
+
+	summaries := []mysql.SalespersonSalesSummary{}
+	err := r.conn.SelectContext(ctx, &summaries, query, start, end)
+	if err != nil {
+		return summaries, fmt.Errorf("failed to summarize sales by salesperson: %w", err)
+	}
+	return summaries, nil
+}
+
+// sales_group_expressions give the key and display label for each grouping
+var sales_group_expressions = map[mysql.SalesGrouping][2]string{
+	mysql.SalesGroupingNone:          {"''", "''"},
+	mysql.SalesGroupingMake:          {"v.make", "v.make"},
+	mysql.SalesGroupingSalesperson:   {"sp.id", "CONCAT(sp.first_name, ' ', sp.last_name)"},
+	mysql.SalesGroupingPaymentMethod: {"s.payment_method", "s.payment_method"},
+}
+
+// sales_time_series_slot is the width in seconds of the UTC slots TimeSeries
+// totals sales in
+const sales_time_series_slot = 15 * 60
+
+// TimeSeries totals completed sales per 15 minute UTC slot, optionally split
+// by make, salesperson or payment method. Empty slots are not returned.
+func (r *saleRepository) TimeSeries(ctx context.Context, query mysql.SalesTimeSeriesQuery) ([]mysql.SalesTimeSeriesRow, error) {
+	group, ok := sales_group_expressions[query.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown sales grouping %q: %w", query.GroupBy, ErrInvalidQuery)
+	}
+
+	sql_query := fmt.Sprintf(`SELECT FLOOR(UNIX_TIMESTAMP(s.sale_date) / %[3]d) * %[3]d AS slot_start,
+				%[1]s AS group_key,
+				MAX(%[2]s) AS group_label,
+				COUNT(*) AS units_sold,
+				SUM(s.sale_price) AS total_revenue
+			  FROM sales s
+			  JOIN vehicles v ON v.id = s.vehicle_id
+			  JOIN salespersons sp ON sp.id = s.salesperson_id
+			  WHERE s.sale_date BETWEEN ? AND ? AND s.status = 'completed'
+			  GROUP BY slot_start, group_key
+			  ORDER BY slot_start, group_key`, group[0], group[1], sales_time_series_slot)
+
+	rows := []mysql.SalesTimeSeriesRow{}
+	err := r.conn.SelectContext(ctx, &rows, sql_query, query.Start, query.End)
+	if err != nil {
+		return rows, fmt.Errorf("failed to build sales time series: %w", err)
+	}
+	return rows, nil
+}
+
+func (r *saleRepository) GetAll(ctx context.Context) ([]mysql.Sale, error) {
 	var sales []mysql.Sale
-	err := r.db.Connection.Select(&sales, "SELECT * FROM sales")
+	err := r.conn.SelectContext(ctx, &sales, "SELECT * FROM sales")
 	if err != nil {
 		return sales, fmt.Errorf("failed to get all sales: %w", err)
 	}
 	return sales, nil
 }
 
-func (r *saleRepository) Update(id string, sale mysql.Sale) error {
+func (r *saleRepository) Update(ctx context.Context, id string, sale mysql.Sale) error {
 	query := `UPDATE sales SET
 				vehicle_id = :vehicle_id,
 				customer_id = :customer_id,
 				salesperson_id = :salesperson_id,
 				sale_date = :sale_date,
 				sale_price = :sale_price,
+				discount = :discount,
+				sales_tax = :sales_tax,
+				doc_fee = :doc_fee,
+				registration_fee = :registration_fee,
+				title_fee = :title_fee,
+				out_the_door_price = :out_the_door_price,
 				down_payment = :down_payment,
+				trade_in_credit = :trade_in_credit,
 				finance_amount = :finance_amount,
 				finance_term = :finance_term,
 				interest_rate = :interest_rate,
+				monthly_payment = :monthly_payment,
+				residual_value = :residual_value,
+				money_factor = :money_factor,
+				annual_mileage = :annual_mileage,
 				payment_method = :payment_method,
+				commission = :commission,
 				status = :status,
 				notes = :notes,
+				approval_reason = :approval_reason,
+				completed_at = :completed_at,
+				cancelled_at = :cancelled_at,
+				cancellation_reason = :cancellation_reason,
 				updated_at = :updated_at
 				WHERE id = :id`
 	sale.ID = id
 
-	result, err := r.db.Connection.NamedExec(query, sale)
+	result, err := r.conn.NamedExecContext(ctx, query, sale)
 	if err != nil {
 		return fmt.Errorf("failed to update sale %s: %w", id, err)
 	}
@@ -144,10 +250,84 @@ func (r *saleRepository) Update(id string, sale mysql.Sale) error {
 	return nil
 }
 
-func (r *saleRepository) Delete(id string) error {
+// UpdateStatus moves the sale to status, but only while it is still in one of
+// the from statuses
+func (r *saleRepository) UpdateStatus(ctx context.Context, id string, status mysql.SaleStatus, from ...mysql.SaleStatus) error {
+	query := `UPDATE sales SET status = ?, updated_at = ? WHERE id = ?`
+	args := []interface{}{status, time.Now(), id}
+
+	if len(from) > 0 {
+		query += ` AND status IN (?` + strings.Repeat(`, ?`, len(from)-1) + `)`
+		for _, allowed := range from {
+			args = append(args, allowed)
+		}
+	}
+
+	result, err := r.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update status of sale %s: %w", id, err)
+	}
+	rows_affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected for sale %s status update: %w", id, err)
+	}
+	if rows_affected == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return fmt.Errorf("sale %s is no longer %s: %w", id, joinStatuses(from), ErrConflict)
+	}
+	return nil
+}
+
+// Complete marks a pending sale completed
+func (r *saleRepository) Complete(ctx context.Context, id string, at time.Time) error {
+	query := `UPDATE sales SET status = ?, completed_at = ?, updated_at = ? WHERE id = ? AND status = ?`
+
+	result, err := r.conn.ExecContext(ctx, query, mysql.SaleStatusCompleted, at, at, id, mysql.SaleStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to complete sale %s: %w", id, err)
+	}
+	rows_affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected for sale %s completion: %w", id, err)
+	}
+	if rows_affected == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return fmt.Errorf("sale %s is no longer pending: %w", id, ErrConflict)
+	}
+	return nil
+}
+
+// Cancel marks a pending or completed sale cancelled. Cancelling a sale that
+// is already cancelled or was rejected is a conflict.
+func (r *saleRepository) Cancel(ctx context.Context, id string, reason string, at time.Time) error {
+	query := `UPDATE sales SET status = ?, cancelled_at = ?, cancellation_reason = ?, updated_at = ?
+			  WHERE id = ? AND status IN (?, ?)`
+
+	result, err := r.conn.ExecContext(ctx, query, mysql.SaleStatusCancelled, at, reason, at, id, mysql.SaleStatusPending, mysql.SaleStatusCompleted)
+	if err != nil {
+		return fmt.Errorf("failed to cancel sale %s: %w", id, err)
+	}
+	rows_affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected for sale %s cancellation: %w", id, err)
+	}
+	if rows_affected == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return fmt.Errorf("sale %s is no longer pending or completed: %w", id, ErrConflict)
+	}
+	return nil
+}
+
+func (r *saleRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM sales WHERE id = ?`
 
-	result, err := r.db.Connection.Exec(query, id)
+	result, err := r.conn.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete sale %s: %w", id, err)
 	}
@@ -159,4 +339,4 @@ func (r *saleRepository) Delete(id string) error {
 		return fmt.Errorf("sale with id %s not found for deletion", id)
 	}
 	return nil
-}
\ No newline at end of file
+}
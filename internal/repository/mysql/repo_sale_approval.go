@@ -0,0 +1,37 @@
+package mysql
+
+import (
+	"api-servers/internal/models/mysql"
+	"context"
+	"fmt"
+)
+
+type saleApprovalRepository struct {
+	conn executor
+}
+
+func NewSaleApprovalRepository(db *Database) SaleApprovalRepository {
+	return &saleApprovalRepository{
+		conn: db.Connection,
+	}
+}
+
+func (r *saleApprovalRepository) Create(ctx context.Context, approval mysql.SaleApproval) error {
+	query := `INSERT INTO sale_approvals (id, sale_id, manager_id, decision, notes, decided_at)
+			  VALUES (:id, :sale_id, :manager_id, :decision, :notes, :decided_at)`
+	_, err := r.conn.NamedExecContext(ctx, query, approval)
+	if err != nil {
+		return fmt.Errorf("failed to record %s decision for sale %s: %w", approval.Decision, approval.Sale_ID, err)
+	}
+	return nil
+}
+
+func (r *saleApprovalRepository) GetBySaleId(ctx context.Context, saleId string) ([]mysql.SaleApproval, error) {
+	approvals := []mysql.SaleApproval{}
+	err := r.conn.SelectContext(ctx, &approvals, "SELECT * FROM sale_approvals WHERE sale_id = ? ORDER BY decided_at, id", saleId)
+
+	if err != nil {
+		return approvals, fmt.Errorf("failed to get approvals by sale_id %s: %w", saleId, err)
+	}
+	return approvals, nil
+}
@@ -0,0 +1,42 @@
+package mysql
+
+import (
+	"api-servers/internal/models/mysql"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+type saleContractRepository struct {
+	conn executor
+}
+
+func NewSaleContractRepository(db *Database) SaleContractRepository {
+	return &saleContractRepository{
+		conn: db.Connection,
+	}
+}
+
+func (r *saleContractRepository) Create(ctx context.Context, contract mysql.SaleContract) error {
+	query := `INSERT INTO sale_contracts (id, sale_id, terms, html, html_sha256, pdf, pdf_sha256, signature, generated_at)
+			  VALUES (:id, :sale_id, :terms, :html, :html_sha256, :pdf, :pdf_sha256, :signature, :generated_at)`
+	_, err := r.conn.NamedExecContext(ctx, query, contract)
+	if err != nil {
+		return fmt.Errorf("failed to store contract for sale %s: %w", contract.Sale_ID, err)
+	}
+	return nil
+}
+
+func (r *saleContractRepository) GetBySaleId(ctx context.Context, saleId string) (mysql.SaleContract, error) {
+	var contract mysql.SaleContract
+	err := r.conn.GetContext(ctx, &contract, "SELECT * FROM sale_contracts WHERE sale_id = ?", saleId)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return contract, fmt.Errorf("contract for sale %s not found: %w", saleId, ErrNotFound)
+	}
+	if err != nil {
+		return contract, fmt.Errorf("failed to get contract by sale_id %s: %w", saleId, err)
+	}
+	return contract, nil
+}
@@ -0,0 +1,206 @@
+package mysql
+
+import (
+	"api-servers/internal/models/mysql"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// salesFixture is a lot with the given vehicles, three salespeople and a
+// customer to record sales against
+type salesFixture struct {
+	t     *testing.T
+	sales SaleRepository
+	n     int
+}
+
+func newSalesFixture(t *testing.T, db *Database, lot ...mysql.Vehicle) *salesFixture {
+	t.Helper()
+	ctx := context.Background()
+	created := time.Date(2026, time.January, 2, 9, 0, 0, 0, time.UTC)
+
+	vehicles := NewVehicleRepository(db)
+	for _, vehicle := range lot {
+		if err := vehicles.Create(ctx, vehicle); err != nil {
+			t.Fatalf("failed to create vehicle: %v", err)
+		}
+	}
+
+	customer := mysql.Customer{ID: "customer-1", First_Name: "Pat", Last_Name: "Lee", Email: "pat.lee@example.com", Created_At: created, Updated_At: created}
+	if err := NewCustomerRepository(db).Create(ctx, customer); err != nil {
+		t.Fatalf("failed to create customer: %v", err)
+	}
+
+	salespeople := NewSalespersonRepository(db)
+	for i, name := range []string{"Avery", "Blake", "Casey"} {
+		salesperson := mysql.Salesperson{
+			ID: fmt.Sprintf("salesperson-%d", i+1), Employee_ID: fmt.Sprintf("EMP%03d", i+1),
+			First_Name: name, Last_Name: "Smith", Email: fmt.Sprintf("%s@dealership.test", name),
+			Hire_Date: created, Commission: 0.03, Role: mysql.SalesPersonRoleSales, Status: mysql.SalesPersonStatusActive,
+			Created_At: created, Updated_At: created,
+		}
+		if err := salespeople.Create(ctx, salesperson); err != nil {
+			t.Fatalf("failed to create salesperson: %v", err)
+		}
+	}
+
+	return &salesFixture{t: t, sales: NewSaleRepository(db)}
+}
+
+// sell records a sale. Completed and cancelled-after-completion sales get a
+// completed_at; cancelled ones get a cancelled_at.
+func (f *salesFixture) sell(vehicleID, salespersonID string, at time.Time, price float64, status mysql.SaleStatus, wasCompleted bool) {
+	f.t.Helper()
+	f.n++
+
+	sale := mysql.Sale{
+		ID: fmt.Sprintf("sale-%d", f.n), Vehicle_ID: vehicleID, Customer_ID: "customer-1", Salesperson_ID: salespersonID,
+		Sale_Date: at, Sale_Price: price, Out_The_Door_Price: price, Payment_Method: mysql.PaymentMethodCash,
+		Commission: math.Round(price*3) / 100, Status: status, Created_At: at, Updated_At: at,
+	}
+	if wasCompleted {
+		sale.Completed_At = &at
+	}
+	if status == mysql.SaleStatusCancelled {
+		sale.Cancelled_At = &at
+		sale.Cancellation_Reason = "customer changed their mind"
+	}
+	if err := f.sales.Create(context.Background(), sale); err != nil {
+		f.t.Fatalf("failed to create sale: %v", err)
+	}
+}
+
+// soldModels is a sold Accord and Civic. Sales don't move vehicle status, so
+// one vehicle can stand in for every sale of its model.
+func soldModels() []mysql.Vehicle {
+	var vehicles []mysql.Vehicle
+	for i, model := range []string{"Accord", "Civic"} {
+		vehicle := testVehicleRow("vehicle-"+strings.ToLower(model), fmt.Sprintf("1HGCM82633A00435%d", i), time.Date(2026, time.January, 2, 9, 0, 0, 0, time.UTC))
+		vehicle.Model = model
+		vehicle.Status = mysql.VehicleStatusSold
+		vehicles = append(vehicles, vehicle)
+	}
+	return vehicles
+}
+
+func TestSaleSummaries(t *testing.T) {
+	f := newSalesFixture(t, newTestDatabase(t), soldModels()...)
+	ctx := context.Background()
+
+	march := func(day int) time.Time { return time.Date(2026, time.March, day, 14, 0, 0, 0, time.UTC) }
+	f.sell("vehicle-accord", "salesperson-1", march(2), 30000, mysql.SaleStatusCompleted, true)
+	f.sell("vehicle-civic", "salesperson-1", march(3), 25000, mysql.SaleStatusCompleted, true)
+	f.sell("vehicle-civic", "salesperson-1", march(4), 20000, mysql.SaleStatusPending, false)
+	f.sell("vehicle-accord", "salesperson-2", march(5), 40000, mysql.SaleStatusCompleted, true)
+	f.sell("vehicle-civic", "salesperson-2", march(6), 10000, mysql.SaleStatusCancelled, true)
+	f.sell("vehicle-civic", "salesperson-2", march(7), 5000, mysql.SaleStatusCancelled, false)
+	// outside the period
+	f.sell("vehicle-accord", "salesperson-3", time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC), 99000, mysql.SaleStatusCompleted, true)
+
+	start := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, time.March, 31, 23, 59, 59, 0, time.UTC)
+
+	t.Run("by vehicle", func(t *testing.T) {
+		summaries, err := f.sales.SummarizeByVehicle(ctx, start, end)
+		if err != nil {
+			t.Fatalf("SummarizeByVehicle returned error: %v", err)
+		}
+
+		want := []mysql.VehicleSalesSummary{
+			{Model: "Accord", Status: mysql.SaleStatusCompleted, Units_Sold: 2, Total_Revenue: 70000},
+			{Model: "Civic", Status: mysql.SaleStatusCompleted, Units_Sold: 1, Total_Revenue: 25000},
+			{Model: "Civic", Status: mysql.SaleStatusPending, Units_Sold: 1, Total_Revenue: 20000},
+			{Model: "Civic", Status: mysql.SaleStatusCancelled, Units_Sold: 2, Total_Revenue: 15000},
+		}
+		if len(summaries) != len(want) {
+			t.Fatalf("got %d groups, want %d: %+v", len(summaries), len(want), summaries)
+		}
+		for i, summary := range summaries {
+			w := want[i]
+			if summary.Model != w.Model || summary.Status != w.Status || summary.Units_Sold != w.Units_Sold || summary.Total_Revenue != w.Total_Revenue {
+				t.Errorf("group %d = %s %s %d for %v, want %s %s %d for %v", i,
+					summary.Model, summary.Status, summary.Units_Sold, summary.Total_Revenue,
+					w.Model, w.Status, w.Units_Sold, w.Total_Revenue)
+			}
+		}
+	})
+
+	t.Run("by salesperson", func(t *testing.T) {
+		summaries, err := f.sales.SummarizeBySalesperson(ctx, start, end)
+		if err != nil {
+			t.Fatalf("SummarizeBySalesperson returned error: %v", err)
+		}
+		if len(summaries) != 3 {
+			t.Fatalf("got %d salespeople, want all 3", len(summaries))
+		}
+
+		avery, blake, casey := summaries[0], summaries[1], summaries[2]
+		if avery.ID != "salesperson-1" || blake.ID != "salesperson-2" || casey.ID != "salesperson-3" {
+			t.Fatalf("order = %s, %s, %s, want by completed revenue", summaries[0].ID, summaries[1].ID, summaries[2].ID)
+		}
+
+		if avery.Total_Sales != 2 || avery.Total_Revenue != 55000 || avery.Commission_Earned != 1650 {
+			t.Errorf("salesperson-1 completed %d for %v earning %v, want 2 for 55000 earning 1650",
+				avery.Total_Sales, avery.Total_Revenue, avery.Commission_Earned)
+		}
+		if avery.Pending_Sales != 1 || avery.Pending_Revenue != 20000 {
+			t.Errorf("salesperson-1 has %d pending for %v, want 1 for 20000", avery.Pending_Sales, avery.Pending_Revenue)
+		}
+		// only the cancellation that had been completed reverses its commission
+		if blake.Cancelled_Sales != 2 || blake.Commission_Reversed != 300 {
+			t.Errorf("salesperson-2 has %d cancelled reversing %v, want 2 reversing 300", blake.Cancelled_Sales, blake.Commission_Reversed)
+		}
+		if casey.Total_Sales != 0 || casey.Total_Revenue != 0 {
+			t.Errorf("salesperson-3 has %d sales for %v in the period, want none", casey.Total_Sales, casey.Total_Revenue)
+		}
+	})
+}
+
+func TestSalesTimeSeriesSlots(t *testing.T) {
+	f := newSalesFixture(t, newTestDatabase(t), soldModels()...)
+	ctx := context.Background()
+
+	at := func(hour, minute int) time.Time { return time.Date(2026, time.March, 2, hour, minute, 30, 0, time.UTC) }
+	f.sell("vehicle-accord", "salesperson-1", at(14, 0), 30000, mysql.SaleStatusCompleted, true)
+	f.sell("vehicle-civic", "salesperson-2", at(14, 14), 25000, mysql.SaleStatusCompleted, true)
+	f.sell("vehicle-civic", "salesperson-2", at(14, 10), 20000, mysql.SaleStatusPending, false)
+	f.sell("vehicle-accord", "salesperson-1", at(14, 15), 40000, mysql.SaleStatusCompleted, true)
+
+	query := mysql.SalesTimeSeriesQuery{Start: at(0, 0), End: at(23, 59)}
+	slot := func(hour, minute int) int64 {
+		return time.Date(2026, time.March, 2, hour, minute, 0, 0, time.UTC).Unix()
+	}
+
+	rows, err := f.sales.TimeSeries(ctx, query)
+	if err != nil {
+		t.Fatalf("TimeSeries returned error: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d slots, want 2: %+v", len(rows), rows)
+	}
+	if rows[0].Slot_Start != slot(14, 0) || rows[0].Units_Sold != 2 || rows[0].Total_Revenue != 55000 {
+		t.Errorf("first slot = %+v, want 2 completed sales for 55000 from 14:00", rows[0])
+	}
+	if rows[1].Slot_Start != slot(14, 15) || rows[1].Units_Sold != 1 {
+		t.Errorf("second slot = %+v, want the 14:15 sale", rows[1])
+	}
+
+	query.GroupBy = mysql.SalesGroupingSalesperson
+	rows, err = f.sales.TimeSeries(ctx, query)
+	if err != nil {
+		t.Fatalf("TimeSeries by salesperson returned error: %v", err)
+	}
+	if len(rows) != 3 || rows[0].Group_Key != "salesperson-1" || rows[0].Group_Label != "Avery Smith" || rows[1].Group_Key != "salesperson-2" {
+		t.Errorf("rows by salesperson = %+v, want 14:00 split between salesperson-1 and salesperson-2, then 14:15", rows)
+	}
+
+	query.GroupBy = "customer"
+	if _, err := f.sales.TimeSeries(ctx, query); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("TimeSeries by customer error = %v, want ErrInvalidQuery", err)
+	}
+}
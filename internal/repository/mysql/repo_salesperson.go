@@ -2,46 +2,50 @@ package mysql
 
 import (
 	"api-servers/internal/models/mysql"
+	"context"
 	"database/sql"
 	"fmt"
 )
 
 type salespersonRepository struct {
-	db *Database
+	conn executor
 }
 
 func NewSalespersonRepository(db *Database) SalespersonRepository {
 	return &salespersonRepository{
-		db: db,
+		conn: db.Connection,
 	}
 }
 
-func (r *salespersonRepository) Create(salesperson mysql.Salesperson) error {
-	query := `INSERT INTO salespersons (id, employee_id, first_name, last_name, email, phone, hire_date, commission, department, status, created_at, updated_at)
-			  VALUES (:id, :employee_id, :first_name, :last_name, :email, :phone, :hire_date, :commission, :department, :status, :created_at, :updated_at)`
-	_, err := r.db.Connection.NamedExec(query, salesperson)
+func (r *salespersonRepository) Create(ctx context.Context, salesperson mysql.Salesperson) error {
+	query := `INSERT INTO salespersons (id, employee_id, first_name, last_name, email, phone, hire_date, commission, department, role, status, created_at, updated_at)
+			  VALUES (:id, :employee_id, :first_name, :last_name, :email, :phone, :hire_date, :commission, :department, :role, :status, :created_at, :updated_at)`
+	_, err := r.conn.NamedExecContext(ctx, query, salesperson)
+	if isDuplicateKey(err) {
+		return fmt.Errorf("salesperson with employee_id %s or email %s: %w", salesperson.Employee_ID, salesperson.Email, ErrDuplicate)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create salesperson with employee_id %s: %w", salesperson.Employee_ID, err)
 	}
 	return nil
 }
 
-func (r *salespersonRepository) GetByID(id string) (mysql.Salesperson, error) {
+func (r *salespersonRepository) GetByID(ctx context.Context, id string) (mysql.Salesperson, error) {
 	var salesperson mysql.Salesperson
-	err := r.db.Connection.Get(&salesperson, "SELECT * FROM salespersons WHERE id = ?", id)
+	err := r.conn.GetContext(ctx, &salesperson, "SELECT * FROM salespersons WHERE id = ?", id)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return salesperson, fmt.Errorf("salesperson with id %s not found", id)
+			return salesperson, fmt.Errorf("salesperson with id %s: %w", id, ErrNotFound)
 		}
 		return salesperson, fmt.Errorf("failed to get salesperson by id %s: %w", id, err)
 	}
 	return salesperson, nil
 }
 
-func (r *salespersonRepository) GetByEmployeeId(employeeId string) (mysql.Salesperson, error) {
+func (r *salespersonRepository) GetByEmployeeId(ctx context.Context, employeeId string) (mysql.Salesperson, error) {
 	var salesperson mysql.Salesperson
-	err := r.db.Connection.Get(&salesperson, "SELECT * FROM salespersons WHERE employee_id = ?", employeeId)
+	err := r.conn.GetContext(ctx, &salesperson, "SELECT * FROM salespersons WHERE employee_id = ?", employeeId)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -52,9 +56,9 @@ func (r *salespersonRepository) GetByEmployeeId(employeeId string) (mysql.Salesp
 	return salesperson, nil
 }
 
-func (r *salespersonRepository) GetByEmail(email string) (mysql.Salesperson, error) {
+func (r *salespersonRepository) GetByEmail(ctx context.Context, email string) (mysql.Salesperson, error) {
 	var salesperson mysql.Salesperson
-	err := r.db.Connection.Get(&salesperson, "SELECT * FROM salespersons WHERE email = ?", email)
+	err := r.conn.GetContext(ctx, &salesperson, "SELECT * FROM salespersons WHERE email = ?", email)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -65,9 +69,9 @@ func (r *salespersonRepository) GetByEmail(email string) (mysql.Salesperson, err
 	return salesperson, nil
 }
 
-func (r *salespersonRepository) GetByDepartment(department string) ([]mysql.Salesperson, error) {
+func (r *salespersonRepository) GetByDepartment(ctx context.Context, department string) ([]mysql.Salesperson, error) {
 	var salespersons []mysql.Salesperson
-	err := r.db.Connection.Select(&salespersons, "SELECT * FROM salespersons WHERE department = ?", department)
+	err := r.conn.SelectContext(ctx, &salespersons, "SELECT * FROM salespersons WHERE department = ?", department)
 
 	if err != nil {
 		return salespersons, fmt.Errorf("failed to get salespersons by department %s: %w", department, err)
@@ -75,9 +79,9 @@ func (r *salespersonRepository) GetByDepartment(department string) ([]mysql.Sale
 	return salespersons, nil
 }
 
-func (r *salespersonRepository) GetByStatus(status mysql.SalesPersonStatus) ([]mysql.Salesperson, error) {
+func (r *salespersonRepository) GetByStatus(ctx context.Context, status mysql.SalesPersonStatus) ([]mysql.Salesperson, error) {
 	var salespersons []mysql.Salesperson
-	err := r.db.Connection.Select(&salespersons, "SELECT * FROM salespersons WHERE status = ?", status)
+	err := r.conn.SelectContext(ctx, &salespersons, "SELECT * FROM salespersons WHERE status = ?", status)
 
 	if err != nil {
 		return salespersons, fmt.Errorf("failed to get salespersons by status %s: %w", status, err)
@@ -85,16 +89,51 @@ func (r *salespersonRepository) GetByStatus(status mysql.SalesPersonStatus) ([]m
 	return salespersons, nil
 }
 
-func (r *salespersonRepository) GetAll() ([]mysql.Salesperson, error) {
+func (r *salespersonRepository) GetAll(ctx context.Context) ([]mysql.Salesperson, error) {
 	var salespersons []mysql.Salesperson
-	err := r.db.Connection.Select(&salespersons, "SELECT * FROM salespersons")
+	err := r.conn.SelectContext(ctx, &salespersons, "SELECT * FROM salespersons")
 	if err != nil {
 		return salespersons, fmt.Errorf("failed to get all salespersons: %w", err)
 	}
 	return salespersons, nil
 }
 
-func (r *salespersonRepository) Update(id string, salesperson mysql.Salesperson) error {
+var salespersonSortColumns = map[string]bool{
+	"employee_id": true,
+	"first_name":  true,
+	"last_name":   true,
+	"hire_date":   true,
+	"department":  true,
+	"created_at":  true,
+}
+
+func (r *salespersonRepository) List(ctx context.Context, filter mysql.SalespersonFilter, opts mysql.ListOptions) ([]mysql.Salesperson, error) {
+	where := salespersonWhere(filter)
+	order, page_args, err := orderAndPage(opts, salespersonSortColumns, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	salespersons := []mysql.Salesperson{}
+	err = r.conn.SelectContext(ctx, &salespersons, "SELECT * FROM salespersons"+where.String()+order, append(where.args, page_args...)...)
+	if err != nil {
+		return salespersons, fmt.Errorf("failed to list salespersons: %w", err)
+	}
+	return salespersons, nil
+}
+
+func (r *salespersonRepository) Count(ctx context.Context, filter mysql.SalespersonFilter) (int, error) {
+	where := salespersonWhere(filter)
+
+	var count int
+	err := r.conn.GetContext(ctx, &count, "SELECT COUNT(*) FROM salespersons"+where.String(), where.args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count salespersons: %w", err)
+	}
+	return count, nil
+}
+
+func (r *salespersonRepository) Update(ctx context.Context, id string, salesperson mysql.Salesperson) error {
 	query := `UPDATE salespersons SET
 				employee_id = :employee_id,
 				first_name = :first_name,
@@ -104,12 +143,16 @@ func (r *salespersonRepository) Update(id string, salesperson mysql.Salesperson)
 				hire_date = :hire_date,
 				commission = :commission,
 				department = :department,
+				role = :role,
 				status = :status,
 				updated_at = :updated_at
 				WHERE id = :id`
 	salesperson.ID = id
 
-	result, err := r.db.Connection.NamedExec(query, salesperson)
+	result, err := r.conn.NamedExecContext(ctx, query, salesperson)
+	if isDuplicateKey(err) {
+		return fmt.Errorf("salesperson with employee_id %s or email %s: %w", salesperson.Employee_ID, salesperson.Email, ErrDuplicate)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update salesperson %s: %w", id, err)
 	}
@@ -119,15 +162,15 @@ func (r *salespersonRepository) Update(id string, salesperson mysql.Salesperson)
 		return fmt.Errorf("failed to get rows affected for salesperson %s update: %w", id, err)
 	}
 	if rows_affected == 0 {
-		return fmt.Errorf("salesperson with id %s not found for update", id)
+		return fmt.Errorf("salesperson with id %s not found for update: %w", id, ErrNotFound)
 	}
 	return nil
 }
 
-func (r *salespersonRepository) Delete(id string) error {
+func (r *salespersonRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM salespersons WHERE id = ?`
 
-	result, err := r.db.Connection.Exec(query, id)
+	result, err := r.conn.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete salesperson %s: %w", id, err)
 	}
@@ -139,4 +182,15 @@ func (r *salespersonRepository) Delete(id string) error {
 		return fmt.Errorf("salesperson with id %s not found for deletion", id)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func salespersonWhere(filter mysql.SalespersonFilter) whereClause {
+	var where whereClause
+	if filter.Department != "" {
+		where.add("department = ?", filter.Department)
+	}
+	if filter.Status != "" {
+		where.add("status = ?", filter.Status)
+	}
+	return where
+}
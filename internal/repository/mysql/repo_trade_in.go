@@ -0,0 +1,98 @@
+package mysql
+
+import (
+	"api-servers/internal/models/mysql"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type tradeInRepository struct {
+	conn executor
+}
+
+func NewTradeInRepository(db *Database) TradeInRepository {
+	return &tradeInRepository{
+		conn: db.Connection,
+	}
+}
+
+func (r *tradeInRepository) Create(ctx context.Context, tradeIn mysql.TradeIn) error {
+	query := `INSERT INTO trade_ins (id, customer_id, vin, make, model, year, color, mileage, ` + "`condition`" + `, offer_amount, status, sale_id, vehicle_id, expires_at, created_at, updated_at)
+			  VALUES (:id, :customer_id, :vin, :make, :model, :year, :color, :mileage, :condition, :offer_amount, :status, :sale_id, :vehicle_id, :expires_at, :created_at, :updated_at)`
+	_, err := r.conn.NamedExecContext(ctx, query, tradeIn)
+	if err != nil {
+		return fmt.Errorf("failed to create trade-in for customer %s: %w", tradeIn.Customer_ID, err)
+	}
+	return nil
+}
+
+func (r *tradeInRepository) GetByID(ctx context.Context, id string) (mysql.TradeIn, error) {
+	var tradeIn mysql.TradeIn
+	err := r.conn.GetContext(ctx, &tradeIn, "SELECT * FROM trade_ins WHERE id = ?", id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return tradeIn, fmt.Errorf("trade-in with id %s not found: %w", id, ErrNotFound)
+		}
+		return tradeIn, fmt.Errorf("failed to get trade-in by id %s: %w", id, err)
+	}
+	return tradeIn, nil
+}
+
+func (r *tradeInRepository) GetBySaleId(ctx context.Context, saleId string) (mysql.TradeIn, error) {
+	var tradeIn mysql.TradeIn
+	err := r.conn.GetContext(ctx, &tradeIn, "SELECT * FROM trade_ins WHERE sale_id = ?", saleId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return tradeIn, fmt.Errorf("trade-in for sale %s not found: %w", saleId, ErrNotFound)
+		}
+		return tradeIn, fmt.Errorf("failed to get trade-in by sale_id %s: %w", saleId, err)
+	}
+	return tradeIn, nil
+}
+
+func (r *tradeInRepository) GetByCustomerId(ctx context.Context, customerId string) ([]mysql.TradeIn, error) {
+	tradeIns := []mysql.TradeIn{}
+	err := r.conn.SelectContext(ctx, &tradeIns, "SELECT * FROM trade_ins WHERE customer_id = ? ORDER BY created_at DESC, id", customerId)
+
+	if err != nil {
+		return tradeIns, fmt.Errorf("failed to get trade-ins by customer_id %s: %w", customerId, err)
+	}
+	return tradeIns, nil
+}
+
+// Update moves a trade-in on from status from. Updating one that has already
+// moved on, such as an offer that was applied by another sale, is a conflict.
+func (r *tradeInRepository) Update(ctx context.Context, id string, tradeIn mysql.TradeIn, from mysql.TradeInStatus) error {
+	query := `UPDATE trade_ins SET
+				status = :status,
+				sale_id = :sale_id,
+				vehicle_id = :vehicle_id,
+				updated_at = :updated_at
+				WHERE id = :id AND status = :from`
+	tradeIn.ID = id
+
+	args := struct {
+		mysql.TradeIn
+		From mysql.TradeInStatus `db:"from"`
+	}{tradeIn, from}
+
+	result, err := r.conn.NamedExecContext(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("failed to update trade-in %s: %w", id, err)
+	}
+
+	rows_affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected for trade-in %s update: %w", id, err)
+	}
+	if rows_affected == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return fmt.Errorf("trade-in with id %s not found for update: %w", id, ErrNotFound)
+		}
+		return fmt.Errorf("trade-in %s is no longer %s: %w", id, from, ErrConflict)
+	}
+	return nil
+}
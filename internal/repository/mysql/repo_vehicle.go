@@ -2,50 +2,78 @@ package mysql
 
 import (
 	"api-servers/internal/models/mysql"
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 )
 
 type vehicleRepository struct {
-	db *Database
+	conn executor
 }
 
 func NewVehicleRepository(db *Database) VehicleRepository {
 	return &vehicleRepository{
-		db: db,
+		conn: db.Connection,
 	}
 }
 
-func (r *vehicleRepository) Create(vehicle mysql.Vehicle) error {
-	query := `INSERT INTO vehicles (id, vin, make, model, year, color, mileage, price, status, engine_type, transmission, fuel_type, created_at, updated_at)
-			VALUES (:id, :vin, :make, :model, :year, :color, :mileage, :price, :status, :engine_type, :transmission, :fuel_type, :created_at, :updated_at)`
-	_, err := r.db.Connection.NamedExec(query, vehicle)
+func (r *vehicleRepository) Create(ctx context.Context, vehicle mysql.Vehicle) error {
+	query := `INSERT INTO vehicles (id, vin, make, model, year, color, mileage, price, status, engine_type, transmission, fuel_type, listed_at, created_at, updated_at)
+			VALUES (:id, :vin, :make, :model, :year, :color, :mileage, :price, :status, :engine_type, :transmission, :fuel_type, :listed_at, :created_at, :updated_at)`
+	_, err := r.conn.NamedExecContext(ctx, query, vehicle)
+	if isDuplicateKey(err) {
+		return fmt.Errorf("vehicle with VIN %s: %w", vehicle.VIN, ErrDuplicate)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create vehicle with VIN %s: %w", vehicle.VIN, err)
 	}
 	return nil
 }
 
-func (r *vehicleRepository) GetByID(id string) (mysql.Vehicle, error) {
+func (r *vehicleRepository) GetByID(ctx context.Context, id string) (mysql.Vehicle, error) {
 	var vehicle mysql.Vehicle
-	err := r.db.Connection.Get(&vehicle, "SELECT * FROM vehicles WHERE id = ?", id)
+	err := r.conn.GetContext(ctx, &vehicle, "SELECT * FROM vehicles WHERE id = ?", id)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return vehicle, fmt.Errorf("vehicle with id %s not found", id)
+			return vehicle, fmt.Errorf("vehicle with id %s not found: %w", id, ErrNotFound)
 		}
 		return vehicle, fmt.Errorf("failed to get vehicle by id %s: %w", id, err)
 	}
 	return vehicle, nil
 }
 
-func (r *vehicleRepository) GetByVin(vin string) (mysql.Vehicle, error) {
+// GetByIDs loads the given vehicles in one query, including soft-deleted
+// ones so that sales history can still show what was sold
+func (r *vehicleRepository) GetByIDs(ctx context.Context, ids []string) ([]mysql.Vehicle, error) {
+	vehicles := []mysql.Vehicle{}
+	if len(ids) == 0 {
+		return vehicles, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	var where whereClause
+	where.in("id", args)
+
+	err := r.conn.SelectContext(ctx, &vehicles, "SELECT * FROM vehicles"+where.String(), where.args...)
+	if err != nil {
+		return vehicles, fmt.Errorf("failed to get vehicles by id: %w", err)
+	}
+	return vehicles, nil
+}
+
+func (r *vehicleRepository) GetByVin(ctx context.Context, vin string) (mysql.Vehicle, error) {
 	var vehicle mysql.Vehicle
-	err := r.db.Connection.Get(&vehicle, "SELECT * FROM vehicles WHERE vin = ?", vin)
+	err := r.conn.GetContext(ctx, &vehicle, "SELECT * FROM vehicles WHERE vin = ? AND deleted_at IS NULL", vin)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return vehicle, fmt.Errorf("vehicle with vin %s not found", vin)
+			return vehicle, fmt.Errorf("vehicle with vin %s not found: %w", vin, ErrNotFound)
 		}
 		return vehicle, fmt.Errorf("failed to get vehicle by vin %s: %w", vin, err)
 	}
@@ -53,9 +81,9 @@ func (r *vehicleRepository) GetByVin(vin string) (mysql.Vehicle, error) {
 
 }
 
-func (r *vehicleRepository) GetByMake(make string) ([]mysql.Vehicle, error) {
+func (r *vehicleRepository) GetByMake(ctx context.Context, make string) ([]mysql.Vehicle, error) {
 	var vehicle []mysql.Vehicle
-	err := r.db.Connection.Select(&vehicle, "SELECT * FROM vehicles WHERE make = ?", make)
+	err := r.conn.SelectContext(ctx, &vehicle, "SELECT * FROM vehicles WHERE make = ? AND deleted_at IS NULL", make)
 
 	if err != nil {
 		return vehicle, fmt.Errorf("failed to get vehicles by make %s: %w", make, err)
@@ -63,9 +91,9 @@ func (r *vehicleRepository) GetByMake(make string) ([]mysql.Vehicle, error) {
 	return vehicle, nil
 }
 
-func (r *vehicleRepository) GetByStatus(status string) ([]mysql.Vehicle, error) {
+func (r *vehicleRepository) GetByStatus(ctx context.Context, status string) ([]mysql.Vehicle, error) {
 	var vehicle []mysql.Vehicle
-	err := r.db.Connection.Select(&vehicle, "SELECT * FROM vehicles WHERE status = ?", status)
+	err := r.conn.SelectContext(ctx, &vehicle, "SELECT * FROM vehicles WHERE status = ? AND deleted_at IS NULL", status)
 
 	if err != nil {
 		return vehicle, fmt.Errorf("failed to get vehicles by status %s: %w", status, err)
@@ -73,9 +101,9 @@ func (r *vehicleRepository) GetByStatus(status string) ([]mysql.Vehicle, error)
 	return vehicle, nil
 }
 
-func (r *vehicleRepository) GetByPriceRange(min_price, max_price float64) ([]mysql.Vehicle, error) {
+func (r *vehicleRepository) GetByPriceRange(ctx context.Context, min_price, max_price float64) ([]mysql.Vehicle, error) {
 	var vehicle []mysql.Vehicle
-	err := r.db.Connection.Select(&vehicle, "SELECT * FROM vehicles WHERE price BETWEEN ? AND ?", min_price, max_price)
+	err := r.conn.SelectContext(ctx, &vehicle, "SELECT * FROM vehicles WHERE price BETWEEN ? AND ? AND deleted_at IS NULL", min_price, max_price)
 
 	if err != nil {
 		return vehicle, fmt.Errorf("failed to get vehicles by price range $%.2f-$%.2f: %w", min_price, max_price, err)
@@ -83,16 +111,126 @@ func (r *vehicleRepository) GetByPriceRange(min_price, max_price float64) ([]mys
 	return vehicle, nil
 }
 
-func (r *vehicleRepository) GetAll() ([]mysql.Vehicle, error) {
+func (r *vehicleRepository) GetAll(ctx context.Context) ([]mysql.Vehicle, error) {
 	var vehicles []mysql.Vehicle
-	err := r.db.Connection.Select(&vehicles, "SELECT * FROM vehicles")
+	err := r.conn.SelectContext(ctx, &vehicles, "SELECT * FROM vehicles WHERE deleted_at IS NULL")
 	if err != nil {
 		return vehicles, fmt.Errorf("failed to get all vehicles: %w", err)
 	}
 	return vehicles, nil
 }
 
-func (r *vehicleRepository) Update(id string, vehicle mysql.Vehicle) error {
+var vehicleSortColumns = map[string]bool{
+	"make":       true,
+	"model":      true,
+	"year":       true,
+	"price":      true,
+	"mileage":    true,
+	"created_at": true,
+}
+
+func (r *vehicleRepository) List(ctx context.Context, filter mysql.VehicleFilter, opts mysql.ListOptions) ([]mysql.Vehicle, error) {
+	where := vehicleWhere(filter)
+	order, page_args, err := orderAndPage(opts, vehicleSortColumns, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	vehicles := []mysql.Vehicle{}
+	err = r.conn.SelectContext(ctx, &vehicles, "SELECT * FROM vehicles"+where.String()+order, append(where.args, page_args...)...)
+	if err != nil {
+		return vehicles, fmt.Errorf("failed to list vehicles: %w", err)
+	}
+	return vehicles, nil
+}
+
+// Search returns a page of vehicles matching filter, cheapest first unless
+// opts sorts otherwise. Without an explicit status only available vehicles
+// are returned.
+func (r *vehicleRepository) Search(ctx context.Context, filter mysql.VehicleFilter, opts mysql.ListOptions) ([]mysql.Vehicle, error) {
+	if filter.Status == "" {
+		filter.Status = mysql.VehicleStatusAvailable
+	}
+	where := vehicleWhere(filter)
+	order, page_args, err := orderAndPage(opts, vehicleSortColumns, "price")
+	if err != nil {
+		return nil, err
+	}
+
+	vehicles := []mysql.Vehicle{}
+	err = r.conn.SelectContext(ctx, &vehicles, "SELECT * FROM vehicles"+where.String()+order, append(where.args, page_args...)...)
+	if err != nil {
+		return vehicles, fmt.Errorf("failed to search vehicles: %w", err)
+	}
+	return vehicles, nil
+}
+
+// SummarizeInventory groups vehicles by make, status and how many days they
+// have been on the lot as of asOf, counted from when they were last listed
+func (r *vehicleRepository) SummarizeInventory(ctx context.Context, asOf time.Time) ([]mysql.InventorySummary, error) {
+	query := `SELECT make, status,
+				CASE
+					WHEN days_on_lot <= 30 THEN '0-30'
+					WHEN days_on_lot <= 60 THEN '31-60'
+					WHEN days_on_lot <= 90 THEN '61-90'
+					ELSE '90+'
+				END AS age_bucket,
+				COUNT(*) AS units,
+				SUM(price) AS total_value,
+				SUM(days_on_lot) AS total_days_on_lot
+			  FROM (
+				SELECT make, status, price, GREATEST(DATEDIFF(?, listed_at), 0) AS days_on_lot
+				FROM vehicles
+				WHERE deleted_at IS NULL
+			  ) v
+			  GROUP BY make, status, age_bucket
+			  ORDER BY make, status, age_bucket`
+
+	summaries := []mysql.InventorySummary{}
+	err := r.conn.SelectContext(ctx, &summaries, query, asOf)
+	if err != nil {
+		return summaries, fmt.Errorf("failed to summarize inventory: %w", err)
+	}
+	return summaries, nil
+}
+
+// TurnoverByModel counts, per make/model, the vehicles still on the lot and
+// the completed sales since since. Days to sell count from the listing the
+// sale came out of: the current one, or for a sale made before a trade-in
+// brought the car back, its first listing. A car that sold more than once
+// joins a row per sale, so units on the lot count distinct vehicles.
+func (r *vehicleRepository) TurnoverByModel(ctx context.Context, since time.Time) ([]mysql.ModelTurnover, error) {
+	query := `SELECT v.make, v.model,
+				COUNT(DISTINCT CASE WHEN v.status <> 'sold' THEN v.id END) AS units_on_lot,
+				COUNT(s.id) AS units_sold,
+				COALESCE(AVG(DATEDIFF(s.sale_date, IF(s.sale_date >= v.listed_at, v.listed_at, v.created_at))), 0) AS average_days_to_sell
+			  FROM vehicles v
+			  LEFT JOIN sales s ON s.vehicle_id = v.id AND s.status = 'completed' AND s.sale_date >= ?
+			  WHERE v.deleted_at IS NULL
+			  GROUP BY v.make, v.model
+			  HAVING units_on_lot > 0 OR units_sold > 0
+			  ORDER BY v.make, v.model`
+
+	turnover := []mysql.ModelTurnover{}
+	err := r.conn.SelectContext(ctx, &turnover, query, since)
+	if err != nil {
+		return turnover, fmt.Errorf("failed to get turnover by model: %w", err)
+	}
+	return turnover, nil
+}
+
+func (r *vehicleRepository) Count(ctx context.Context, filter mysql.VehicleFilter) (int, error) {
+	where := vehicleWhere(filter)
+
+	var count int
+	err := r.conn.GetContext(ctx, &count, "SELECT COUNT(*) FROM vehicles"+where.String(), where.args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count vehicles: %w", err)
+	}
+	return count, nil
+}
+
+func (r *vehicleRepository) Update(ctx context.Context, id string, vehicle mysql.Vehicle) error {
 	query := `UPDATE vehicles SET
 				vin = :vin,
 				make = :make,
@@ -105,11 +243,16 @@ func (r *vehicleRepository) Update(id string, vehicle mysql.Vehicle) error {
 				engine_type = :engine_type,
 				transmission = :transmission,
 				fuel_type = :fuel_type,
-				updated_at = :updated_at
-				WHERE id = :id`
+				listed_at = :listed_at,
+				updated_at = :updated_at,
+				version = version + 1
+				WHERE id = :id AND version = :version AND deleted_at IS NULL`
 	vehicle.ID = id
 
-	result, err := r.db.Connection.NamedExec(query, vehicle)
+	result, err := r.conn.NamedExecContext(ctx, query, vehicle)
+	if isDuplicateKey(err) {
+		return fmt.Errorf("vehicle with VIN %s: %w", vehicle.VIN, ErrDuplicate)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update vehicle %s: %w", id, err)
 	}
@@ -118,15 +261,58 @@ func (r *vehicleRepository) Update(id string, vehicle mysql.Vehicle) error {
 		return fmt.Errorf("failed to get rows affected for vehicle %s update: %w", id, err)
 	}
 	if rows_affected == 0 {
-		return fmt.Errorf("vehicle with id %s not found for update", id)
+		if r.exists(ctx, id) {
+			return fmt.Errorf("vehicle %s was modified since version %d: %w", id, vehicle.Version, ErrConflict)
+		}
+		return fmt.Errorf("vehicle with id %s not found for update: %w", id, ErrNotFound)
+	}
+	return nil
+}
+
+// UpdateStatus moves the vehicle to status, but only while it is still in one of the from statuses
+func (r *vehicleRepository) UpdateStatus(ctx context.Context, id string, status mysql.VehicleStatus, from ...mysql.VehicleStatus) error {
+	query := `UPDATE vehicles SET status = ?, updated_at = ?, version = version + 1 WHERE id = ? AND deleted_at IS NULL`
+	args := []interface{}{status, time.Now(), id}
+
+	if len(from) > 0 {
+		query += ` AND status IN (?` + strings.Repeat(`, ?`, len(from)-1) + `)`
+		for _, allowed := range from {
+			args = append(args, allowed)
+		}
+	}
+
+	result, err := r.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update status of vehicle %s: %w", id, err)
+	}
+	rows_affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected for vehicle %s status update: %w", id, err)
+	}
+	if rows_affected == 0 {
+		if r.exists(ctx, id) {
+			return fmt.Errorf("vehicle %s is no longer %s: %w", id, joinStatuses(from), ErrConflict)
+		}
+		return fmt.Errorf("vehicle with id %s not found for status update: %w", id, ErrNotFound)
 	}
 	return nil
 }
 
-func (r *vehicleRepository) Delete(id string) error {
-	query := `DELETE FROM vehicles WHERE id = ?`
+// Delete soft-deletes a vehicle. Given from statuses, it only deletes the
+// vehicle while it is in one of them and returns ErrConflict otherwise.
+func (r *vehicleRepository) Delete(ctx context.Context, id string, from ...mysql.VehicleStatus) error {
+	query := `UPDATE vehicles SET deleted_at = ?, updated_at = ?, version = version + 1 WHERE id = ? AND deleted_at IS NULL`
 
-	result, err := r.db.Connection.Exec(query, id)
+	now := time.Now()
+	args := []interface{}{now, now, id}
+	if len(from) > 0 {
+		query += ` AND status IN (?` + strings.Repeat(`, ?`, len(from)-1) + `)`
+		for _, allowed := range from {
+			args = append(args, allowed)
+		}
+	}
+
+	result, err := r.conn.ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to delete vehicle %s: %w", id, err)
 	}
@@ -135,7 +321,73 @@ func (r *vehicleRepository) Delete(id string) error {
 		return fmt.Errorf("failed to get rows affected for vehicle %s deletion: %w", id, err)
 	}
 	if rows_affected == 0 {
-		return fmt.Errorf("vehicle with id %s not found for deletion", id)
+		if len(from) > 0 && r.exists(ctx, id) {
+			return fmt.Errorf("vehicle %s is no longer %s: %w", id, joinStatuses(from), ErrConflict)
+		}
+		return fmt.Errorf("vehicle with id %s not found for deletion: %w", id, ErrNotFound)
 	}
 	return nil
 }
+
+func (r *vehicleRepository) exists(ctx context.Context, id string) bool {
+	var count int
+	err := r.conn.GetContext(ctx, &count, "SELECT COUNT(*) FROM vehicles WHERE id = ? AND deleted_at IS NULL", id)
+	return err == nil && count > 0
+}
+
+func joinStatuses[S ~string](statuses []S) string {
+	names := make([]string, len(statuses))
+	for i, status := range statuses {
+		names[i] = string(status)
+	}
+	return strings.Join(names, " or ")
+}
+
+func vehicleWhere(filter mysql.VehicleFilter) whereClause {
+	var where whereClause
+	where.add("deleted_at IS NULL")
+
+	if filter.Make != "" {
+		where.add("make = ?", filter.Make)
+	}
+	if len(filter.Makes) > 0 {
+		makes := make([]interface{}, len(filter.Makes))
+		for i, name := range filter.Makes {
+			makes[i] = name
+		}
+		where.in("make", makes)
+	}
+	if filter.Status != "" {
+		where.add("status = ?", filter.Status)
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]interface{}, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = status
+		}
+		where.in("status", statuses)
+	}
+	if len(filter.FuelTypes) > 0 {
+		fuel_types := make([]interface{}, len(filter.FuelTypes))
+		for i, fuel_type := range filter.FuelTypes {
+			fuel_types[i] = fuel_type
+		}
+		where.in("fuel_type", fuel_types)
+	}
+	if filter.MinPrice > 0 {
+		where.add("price >= ?", filter.MinPrice)
+	}
+	if filter.MaxPrice > 0 {
+		where.add("price <= ?", filter.MaxPrice)
+	}
+	if filter.MinYear > 0 {
+		where.add("year >= ?", filter.MinYear)
+	}
+	if filter.MaxYear > 0 {
+		where.add("year <= ?", filter.MaxYear)
+	}
+	if filter.MaxMileage > 0 {
+		where.add("mileage <= ?", filter.MaxMileage)
+	}
+	return where
+}
@@ -0,0 +1,91 @@
+package mysql
+
+import (
+	"api-servers/internal/models/mysql"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestInventoryAgingAndTurnover(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 9, 0, 0, 0, time.UTC) }
+	n := 0
+	vehicle := func(id, vehicleMake, model string, status mysql.VehicleStatus, listed time.Time, price float64) mysql.Vehicle {
+		n++
+		v := testVehicleRow(id, fmt.Sprintf("JTDKB20U0930%05d", n), listed)
+		v.Make, v.Model, v.Status, v.Price = vehicleMake, model, status, price
+		return v
+	}
+
+	// a car that sold twice in the window before a trade-in brought it back
+	relisted := vehicle("accord-1", "Honda", "Accord", mysql.VehicleStatusAvailable, day(time.May, 1), 25000)
+	relisted.Created_At = day(time.April, 2)
+
+	f := newSalesFixture(t, db,
+		vehicle("civic-1", "Honda", "Civic", mysql.VehicleStatusAvailable, day(time.June, 20), 20000),
+		vehicle("civic-2", "Honda", "Civic", mysql.VehicleStatusAvailable, day(time.May, 16), 22000),
+		vehicle("camry-1", "Toyota", "Camry", mysql.VehicleStatusReserved, day(time.March, 22), 28000),
+		vehicle("camry-2", "Toyota", "Camry", mysql.VehicleStatusSold, day(time.April, 1), 27000),
+		vehicle("accord-deleted", "Honda", "Accord", mysql.VehicleStatusAvailable, day(time.June, 1), 24000),
+		relisted,
+	)
+	if err := NewVehicleRepository(db).Delete(ctx, "accord-deleted"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	f.sell("camry-2", "salesperson-1", day(time.April, 21), 27000, mysql.SaleStatusCompleted, true)
+	f.sell("accord-1", "salesperson-1", day(time.April, 10), 24000, mysql.SaleStatusCompleted, true)
+	f.sell("accord-1", "salesperson-2", day(time.April, 20), 24500, mysql.SaleStatusCompleted, true)
+	f.sell("civic-1", "salesperson-2", day(time.June, 25), 20000, mysql.SaleStatusPending, false)
+
+	repo := NewVehicleRepository(db)
+	asOf := time.Date(2026, time.June, 30, 12, 0, 0, 0, time.UTC)
+
+	t.Run("aging", func(t *testing.T) {
+		summaries, err := repo.SummarizeInventory(ctx, asOf)
+		if err != nil {
+			t.Fatalf("SummarizeInventory returned error: %v", err)
+		}
+
+		want := []mysql.InventorySummary{
+			{Make: "Honda", Status: mysql.VehicleStatusAvailable, Age_Bucket: mysql.InventoryAge0To30, Units: 1, Total_Value: 20000, Total_Days_On_Lot: 10},
+			// the relisted Accord is 60 days from its relisting, not 89 from its first listing
+			{Make: "Honda", Status: mysql.VehicleStatusAvailable, Age_Bucket: mysql.InventoryAge31To60, Units: 2, Total_Value: 47000, Total_Days_On_Lot: 105},
+			{Make: "Toyota", Status: mysql.VehicleStatusReserved, Age_Bucket: mysql.InventoryAgeOver90, Units: 1, Total_Value: 28000, Total_Days_On_Lot: 100},
+			{Make: "Toyota", Status: mysql.VehicleStatusSold, Age_Bucket: mysql.InventoryAge61To90, Units: 1, Total_Value: 27000, Total_Days_On_Lot: 90},
+		}
+		if len(summaries) != len(want) {
+			t.Fatalf("got %d groups, want %d: %+v", len(summaries), len(want), summaries)
+		}
+		for i := range want {
+			if summaries[i] != want[i] {
+				t.Errorf("group %d = %+v, want %+v", i, summaries[i], want[i])
+			}
+		}
+	})
+
+	t.Run("turnover", func(t *testing.T) {
+		turnover, err := repo.TurnoverByModel(ctx, asOf.AddDate(0, 0, -90))
+		if err != nil {
+			t.Fatalf("TurnoverByModel returned error: %v", err)
+		}
+
+		want := []mysql.ModelTurnover{
+			// both sales predate the relisting, so they count from the first listing
+			{Make: "Honda", Model: "Accord", Units_On_Lot: 1, Units_Sold: 2, Average_Days_To_Sell: 13},
+			{Make: "Honda", Model: "Civic", Units_On_Lot: 2},
+			{Make: "Toyota", Model: "Camry", Units_On_Lot: 1, Units_Sold: 1, Average_Days_To_Sell: 20},
+		}
+		if len(turnover) != len(want) {
+			t.Fatalf("got %d models, want %d: %+v", len(turnover), len(want), turnover)
+		}
+		for i := range want {
+			if turnover[i] != want[i] {
+				t.Errorf("model %d = %+v, want %+v", i, turnover[i], want[i])
+			}
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// executor is satisfied by both *sqlx.DB and *sqlx.Tx, so the same repository
+// code runs either directly against the pool or inside a transaction
+type executor interface {
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
+}
+
+// Repositories groups repository instances that share one connection or transaction
+type Repositories struct {
+	Vehicles     VehicleRepository
+	Customers    CustomerRepository
+	Salespeople  SalespersonRepository
+	Sales        SaleRepository
+	Reservations ReservationRepository
+	TradeIns     TradeInRepository
+	Approvals    SaleApprovalRepository
+	Contracts    SaleContractRepository
+}
+
+type Transactor interface {
+	WithTx(ctx context.Context, fn func(repos Repositories) error) error
+}
+
+// WithTx runs fn with repositories bound to a single transaction. The transaction
+// is committed when fn returns nil and rolled back otherwise.
+func (d *Database) WithTx(ctx context.Context, fn func(repos Repositories) error) error {
+	tx, err := d.Connection.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(newRepositories(tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func newRepositories(tx *sqlx.Tx) Repositories {
+	return Repositories{
+		Vehicles:     &vehicleRepository{conn: tx},
+		Customers:    &customerRepository{conn: tx},
+		Salespeople:  &salespersonRepository{conn: tx},
+		Sales:        &saleRepository{conn: tx},
+		Reservations: &reservationRepository{conn: tx},
+		TradeIns:     &tradeInRepository{conn: tx},
+		Approvals:    &saleApprovalRepository{conn: tx},
+		Contracts:    &saleContractRepository{conn: tx},
+	}
+}
@@ -0,0 +1,7 @@
+package redis
+
+import "errors"
+
+// ErrNotFound is wrapped when a key doesn't exist, or has expired, and
+// callers need to tell that apart from a failed command
+var ErrNotFound = errors.New("not found")
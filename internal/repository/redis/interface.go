@@ -18,6 +18,14 @@ type SessionRepository interface {
 	DeleteExpired(ctx context.Context) error
 }
 
+type SalesSessionRepository interface {
+	Create(ctx context.Context, session redis.SalesSession) error
+	GetByID(ctx context.Context, id string) (redis.SalesSession, error)
+	GetBySalespersonID(ctx context.Context, salespersonID string) ([]redis.SalesSession, error)
+	Update(ctx context.Context, id string, session redis.SalesSession) error
+	Delete(ctx context.Context, id string) error
+}
+
 type CacheRepository interface {
 	Set(ctx context.Context, key string, value string, ttl time.Duration) error
 	Get(ctx context.Context, key string) (string, error)
@@ -0,0 +1,147 @@
+package redis
+
+import (
+	"api-servers/internal/models/redis"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// sales sessions are kept around after they expire so that callers can still
+// see them as expired instead of getting a not found
+const sales_session_retention = 24 * time.Hour
+
+type salesSessionRepository struct {
+	db *Database
+}
+
+func NewSalesSessionRepository(db *Database) SalesSessionRepository {
+	return &salesSessionRepository{
+		db: db,
+	}
+}
+
+func (r *salesSessionRepository) Create(ctx context.Context, session redis.SalesSession) error {
+	ttl := time.Until(session.Expires_At)
+	if ttl <= 0 {
+		return fmt.Errorf("sales session expired")
+	}
+	ttl += sales_session_retention
+
+	session_data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sales session: %w", err)
+	}
+
+	key := "sales_session:" + session.ID
+	err = r.db.Connection.Set(ctx, key, session_data, ttl).Err()
+	if err != nil {
+		return fmt.Errorf("failed to create sales session: %w", err)
+	}
+
+	index_key := "sales_session:salesperson:" + session.Salesperson_ID
+	err = r.db.Connection.SAdd(ctx, index_key, session.ID).Err()
+	if err != nil {
+		return fmt.Errorf("failed to index sales session for salesperson %s: %w", session.Salesperson_ID, err)
+	}
+
+	err = r.db.Connection.Expire(ctx, index_key, ttl).Err()
+	if err != nil {
+		return fmt.Errorf("failed to set TTL for salesperson %s session index: %w", session.Salesperson_ID, err)
+	}
+	return nil
+}
+
+func (r *salesSessionRepository) GetByID(ctx context.Context, id string) (redis.SalesSession, error) {
+	var session redis.SalesSession
+
+	key := "sales_session:" + id
+	result := r.db.Connection.Get(ctx, key)
+
+	if err := result.Err(); err != nil {
+		if err == goredis.Nil {
+			return session, fmt.Errorf("sales session with id %s not found: %w", id, ErrNotFound)
+		}
+		return session, fmt.Errorf("failed to get sales session: %w", err)
+	}
+
+	err := json.Unmarshal([]byte(result.Val()), &session)
+	if err != nil {
+		return session, fmt.Errorf("failed to unmarshal sales session: %w", err)
+	}
+
+	return session, nil
+}
+
+func (r *salesSessionRepository) GetBySalespersonID(ctx context.Context, salespersonID string) ([]redis.SalesSession, error) {
+	var sessions []redis.SalesSession
+
+	index_key := "sales_session:salesperson:" + salespersonID
+	members := r.db.Connection.SMembers(ctx, index_key)
+
+	if err := members.Err(); err != nil {
+		return sessions, fmt.Errorf("failed to get sales sessions for salesperson %s: %w", salespersonID, err)
+	}
+
+	for _, session_id := range members.Val() {
+		session, err := r.GetByID(ctx, session_id)
+		if errors.Is(err, ErrNotFound) {
+			// the session key has aged out, drop it from the index
+			r.db.Connection.SRem(ctx, index_key, session_id)
+			continue
+		}
+		if err != nil {
+			return sessions, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+func (r *salesSessionRepository) Update(ctx context.Context, id string, session redis.SalesSession) error {
+	session.ID = id
+
+	session_data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sales session: %w", err)
+	}
+
+	key := "sales_session:" + id
+	err = r.db.Connection.SetArgs(ctx, key, session_data, goredis.SetArgs{
+		Mode:    "XX",
+		KeepTTL: true,
+	}).Err()
+	if err != nil {
+		if err == goredis.Nil {
+			return fmt.Errorf("sales session with id %s not found for update: %w", id, ErrNotFound)
+		}
+		return fmt.Errorf("failed to update sales session %s: %w", id, err)
+	}
+	return nil
+}
+
+func (r *salesSessionRepository) Delete(ctx context.Context, id string) error {
+	session, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	key := "sales_session:" + id
+	err = r.db.Connection.Del(ctx, key).Err()
+	if err != nil {
+		return fmt.Errorf("failed to delete sales session: %w", err)
+	}
+
+	index_key := "sales_session:salesperson:" + session.Salesperson_ID
+	err = r.db.Connection.SRem(ctx, index_key, id).Err()
+	if err != nil {
+		return fmt.Errorf("failed to remove sales session from salesperson index: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package redis
+
+import (
+	"api-servers/internal/models/redis"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func newTestSalesSessionRepository(t *testing.T) (*miniredis.Miniredis, SalesSessionRepository) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return server, NewSalesSessionRepository(&Database{Connection: client})
+}
+
+func testSalesSession(id string, expiresIn time.Duration) redis.SalesSession {
+	now := time.Now()
+	return redis.SalesSession{
+		ID:             id,
+		Customer_ID:    "customer-1",
+		Vehicle_ID:     "vehicle-1",
+		Salesperson_ID: "salesperson-1",
+		Status:         "active",
+		Started_At:     now,
+		Expires_At:     now.Add(expiresIn),
+		Updated_At:     now,
+	}
+}
+
+func TestSalesSessionIsKeptUntilTheRetentionWindowEnds(t *testing.T) {
+	server, repo := newTestSalesSessionRepository(t)
+	ctx := context.Background()
+
+	if err := repo.Create(ctx, testSalesSession("session-1", time.Hour)); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	ttl := server.TTL("sales_session:session-1")
+	if want := time.Hour + sales_session_retention; ttl > want || ttl < want-time.Minute {
+		t.Errorf("session TTL = %v, want about %v", ttl, want)
+	}
+	// EXPIRE works in whole seconds
+	if indexTTL := server.TTL("sales_session:salesperson:salesperson-1"); indexTTL != ttl.Truncate(time.Second) {
+		t.Errorf("salesperson index TTL = %v, want the session's %v", indexTTL, ttl)
+	}
+
+	// past its expiry the session is still there to be reported as expired
+	server.FastForward(2 * time.Hour)
+	if _, err := repo.GetByID(ctx, "session-1"); err != nil {
+		t.Fatalf("GetByID after expiry returned error: %v", err)
+	}
+
+	server.FastForward(sales_session_retention)
+	if _, err := repo.GetByID(ctx, "session-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetByID after retention error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCreateRefusesAnExpiredSalesSession(t *testing.T) {
+	server, repo := newTestSalesSessionRepository(t)
+
+	if err := repo.Create(context.Background(), testSalesSession("session-1", -time.Minute)); err == nil {
+		t.Fatal("Create of an expired session returned nil error")
+	}
+	if server.Exists("sales_session:session-1") {
+		t.Error("expired session was stored")
+	}
+}
+
+func TestUpdateKeepsTheSalesSessionTTL(t *testing.T) {
+	server, repo := newTestSalesSessionRepository(t)
+	ctx := context.Background()
+
+	session := testSalesSession("session-1", time.Hour)
+	if err := repo.Create(ctx, session); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	server.FastForward(30 * time.Minute)
+	before := server.TTL("sales_session:session-1")
+
+	session.Status = "completed"
+	if err := repo.Update(ctx, session.ID, session); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+
+	if after := server.TTL("sales_session:session-1"); after != before {
+		t.Errorf("TTL after update = %v, want it kept at %v", after, before)
+	}
+	stored, err := repo.GetByID(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if stored.Status != "completed" {
+		t.Errorf("status = %q, want completed", stored.Status)
+	}
+}
+
+func TestUpdateDoesNotRecreateAnAgedOutSalesSession(t *testing.T) {
+	server, repo := newTestSalesSessionRepository(t)
+	ctx := context.Background()
+
+	session := testSalesSession("session-1", time.Hour)
+	if err := repo.Create(ctx, session); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	server.FastForward(time.Hour + sales_session_retention)
+
+	session.Status = "expired"
+	if err := repo.Update(ctx, session.ID, session); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update error = %v, want ErrNotFound", err)
+	}
+	if server.Exists("sales_session:session-1") {
+		t.Error("update brought the aged-out session back")
+	}
+}
+
+func TestGetBySalespersonIDDropsAgedOutSessions(t *testing.T) {
+	server, repo := newTestSalesSessionRepository(t)
+	ctx := context.Background()
+
+	if err := repo.Create(ctx, testSalesSession("session-short", time.Hour)); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if err := repo.Create(ctx, testSalesSession("session-long", 48*time.Hour)); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	// the short session has aged out; the index lives as long as the newest
+	// session, so it still lists it
+	server.FastForward(time.Hour + sales_session_retention + time.Minute)
+
+	sessions, err := repo.GetBySalespersonID(ctx, "salesperson-1")
+	if err != nil {
+		t.Fatalf("GetBySalespersonID returned error: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != "session-long" {
+		t.Fatalf("sessions = %v, want only session-long", sessions)
+	}
+
+	members, err := server.Members("sales_session:salesperson:salesperson-1")
+	if err != nil {
+		t.Fatalf("failed to read the salesperson index: %v", err)
+	}
+	if len(members) != 1 || members[0] != "session-long" {
+		t.Errorf("salesperson index = %v, want the aged-out session dropped", members)
+	}
+}
@@ -0,0 +1,129 @@
+package dealership
+
+import (
+	"api-servers/internal/models/mysql"
+	"context"
+	"fmt"
+	"math"
+)
+
+// QuoteAmortization schedules the loan a customer would take out to finance
+// a vehicle over one of the offered terms, before any sale is made
+func (s *service) QuoteAmortization(ctx context.Context, request AmortizationQuoteRequest) (*AmortizationSchedule, error) {
+	quote, err := s.quoteFinancing(ctx, request.VehicleID, request.DownPayment, request.CustomerID, request.TradeInID)
+	if err != nil {
+		return nil, err
+	}
+
+	financing, err := s.financeSale(quote.creditDecision, quote.loanAmount, request.TermMonths)
+	if err != nil {
+		return nil, err
+	}
+
+	return amortize(financing.LoanAmount, financing.InterestRate, financing.TermMonths, roundCents(financing.MonthlyPayment), request.ExtraPayments)
+}
+
+// GetSaleAmortization schedules the loan of a financed sale on the rate,
+// term and monthly payment it was sold at
+func (s *service) GetSaleAmortization(ctx context.Context, saleID string, extra ExtraPayments) (*AmortizationSchedule, error) {
+	sale, err := s.sales_repo.GetByID(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	if sale.Payment_Method != mysql.PaymentMethodFinance || sale.Finance_Amount <= 0 || sale.Finance_Term <= 0 {
+		return nil, fmt.Errorf("sale %s was paid by %s, only financed sales have an amortization schedule: %w", saleID, sale.Payment_Method, ErrInvalidState)
+	}
+
+	schedule, err := amortize(sale.Finance_Amount, sale.Interest_Rate, sale.Finance_Term, sale.Monthly_Payment, extra)
+	if err != nil {
+		return nil, err
+	}
+	schedule.SaleID = sale.ID
+	return schedule, nil
+}
+
+// amortization helper functions
+
+// amortize builds the month-by-month schedule. Interest is charged on the
+// balance each month and rounded to the cent; the final scheduled payment
+// absorbs the rounding so the balance always ends at zero.
+func amortize(loanAmount, annualRate float64, termMonths int, payment float64, extra ExtraPayments) (*AmortizationSchedule, error) {
+	if err := validateExtraPayments(extra, termMonths); err != nil {
+		return nil, err
+	}
+
+	schedule := &AmortizationSchedule{
+		LoanAmount:     loanAmount,
+		InterestRate:   annualRate,
+		TermMonths:     termMonths,
+		MonthlyPayment: payment,
+		ExtraPayments:  extra,
+		Payments:       amortizationPayments(loanAmount, annualRate, termMonths, payment, extra),
+	}
+	if schedule.ExtraPayments.LumpSums == nil {
+		schedule.ExtraPayments.LumpSums = []LumpSumPayment{}
+	}
+
+	for _, p := range schedule.Payments {
+		schedule.TotalInterest += p.Interest
+		schedule.TotalPaid += p.Payment + p.Extra
+	}
+	schedule.TotalInterest = roundCents(schedule.TotalInterest)
+	schedule.TotalPaid = roundCents(schedule.TotalPaid)
+	schedule.PayoffMonths = len(schedule.Payments)
+
+	var scheduledInterest float64
+	for _, p := range amortizationPayments(loanAmount, annualRate, termMonths, payment, ExtraPayments{}) {
+		scheduledInterest += p.Interest
+	}
+	schedule.InterestSaved = roundCents(scheduledInterest - schedule.TotalInterest)
+	schedule.MonthsSaved = termMonths - schedule.PayoffMonths
+
+	return schedule, nil
+}
+
+func amortizationPayments(loanAmount, annualRate float64, termMonths int, payment float64, extra ExtraPayments) []AmortizationPayment {
+	lumpSums := make(map[int]float64, len(extra.LumpSums))
+	for _, lumpSum := range extra.LumpSums {
+		lumpSums[lumpSum.Month] += lumpSum.Amount
+	}
+
+	monthlyRate := annualRate / 100 / 12
+	balance := roundCents(loanAmount)
+	payments := make([]AmortizationPayment, 0, termMonths)
+
+	for month := 1; month <= termMonths && balance > 0; month++ {
+		interest := roundCents(balance * monthlyRate)
+		principal := roundCents(payment - interest)
+		if principal > balance || month == termMonths {
+			principal = balance
+		}
+		extraPaid := math.Min(roundCents(extra.Monthly+lumpSums[month]), roundCents(balance-principal))
+		balance = roundCents(balance - principal - extraPaid)
+
+		payments = append(payments, AmortizationPayment{
+			Month:     month,
+			Payment:   roundCents(principal + interest),
+			Principal: principal,
+			Interest:  interest,
+			Extra:     extraPaid,
+			Balance:   balance,
+		})
+	}
+	return payments
+}
+
+func validateExtraPayments(extra ExtraPayments, termMonths int) error {
+	if extra.Monthly < 0 {
+		return fmt.Errorf("extra monthly payment cannot be negative: %w", ErrInvalidInput)
+	}
+	for _, lumpSum := range extra.LumpSums {
+		if lumpSum.Month < 1 || lumpSum.Month > termMonths {
+			return fmt.Errorf("lump sum month %d is outside the %d month term: %w", lumpSum.Month, termMonths, ErrInvalidInput)
+		}
+		if lumpSum.Amount <= 0 {
+			return fmt.Errorf("lump sum for month %d must be positive: %w", lumpSum.Month, ErrInvalidInput)
+		}
+	}
+	return nil
+}
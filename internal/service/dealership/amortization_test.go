@@ -0,0 +1,183 @@
+package dealership
+
+import (
+	"api-servers/internal/models/mysql"
+	mysqlrepo "api-servers/internal/repository/mysql"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestLoanPayment(t *testing.T) {
+	tests := []struct {
+		name       string
+		loanAmount float64
+		annualRate float64
+		termMonths int
+		want       float64
+	}{
+		{name: "zero APR splits the loan evenly", loanAmount: 12000, annualRate: 0, termMonths: 12, want: 1000},
+		{name: "zero APR with a remainder", loanAmount: 1000, annualRate: 0, termMonths: 3, want: 333.33},
+		{name: "standard annuity", loanAmount: 20000, annualRate: 6, termMonths: 60, want: 386.66},
+		{name: "single month", loanAmount: 5000, annualRate: 12, termMonths: 1, want: 5050},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := roundCents(loanPayment(tt.loanAmount, tt.annualRate, tt.termMonths))
+			if got != tt.want {
+				t.Errorf("loanPayment(%v, %v, %d) = %v, want %v", tt.loanAmount, tt.annualRate, tt.termMonths, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAmortize(t *testing.T) {
+	tests := []struct {
+		name          string
+		loanAmount    float64
+		annualRate    float64
+		termMonths    int
+		extra         ExtraPayments
+		wantMonths    int
+		wantFinal     float64
+		wantInterest  float64
+		wantSaved     float64
+		wantMonthsCut int
+	}{
+		{
+			name:       "zero APR final payment picks up the leftover cent",
+			loanAmount: 1000, annualRate: 0, termMonths: 3,
+			wantMonths: 3, wantFinal: 333.34,
+		},
+		{
+			name:       "final payment absorbs interest rounding",
+			loanAmount: 20000, annualRate: 6, termMonths: 60,
+			wantMonths: 60, wantFinal: 386.41, wantInterest: 3199.35,
+		},
+		{
+			name:       "lump sum pays the loan off early",
+			loanAmount: 1000, annualRate: 0, termMonths: 3,
+			extra:      ExtraPayments{LumpSums: []LumpSumPayment{{Month: 1, Amount: 600}}},
+			wantMonths: 2, wantFinal: 66.67, wantMonthsCut: 1,
+		},
+		{
+			name:       "extra monthly and lump sum save interest",
+			loanAmount: 12000, annualRate: 12, termMonths: 12,
+			extra:      ExtraPayments{Monthly: 200, LumpSums: []LumpSumPayment{{Month: 6, Amount: 3000}}},
+			wantMonths: 8, wantFinal: 708.94, wantInterest: 572.27, wantSaved: 221.96, wantMonthsCut: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := roundCents(loanPayment(tt.loanAmount, tt.annualRate, tt.termMonths))
+			schedule, err := amortize(tt.loanAmount, tt.annualRate, tt.termMonths, payment, tt.extra)
+			if err != nil {
+				t.Fatalf("amortize returned error: %v", err)
+			}
+
+			if len(schedule.Payments) != tt.wantMonths || schedule.PayoffMonths != tt.wantMonths {
+				t.Fatalf("paid off in %d months (%d payments), want %d", schedule.PayoffMonths, len(schedule.Payments), tt.wantMonths)
+			}
+			final := schedule.Payments[len(schedule.Payments)-1]
+			if final.Payment != tt.wantFinal {
+				t.Errorf("final payment = %v, want %v", final.Payment, tt.wantFinal)
+			}
+			if final.Balance != 0 {
+				t.Errorf("final balance = %v, want 0", final.Balance)
+			}
+
+			var principal float64
+			for _, p := range schedule.Payments {
+				principal += p.Principal + p.Extra
+			}
+			if roundCents(principal) != tt.loanAmount {
+				t.Errorf("principal repaid = %v, want %v", roundCents(principal), tt.loanAmount)
+			}
+
+			if schedule.TotalInterest != tt.wantInterest {
+				t.Errorf("total interest = %v, want %v", schedule.TotalInterest, tt.wantInterest)
+			}
+			if schedule.InterestSaved != tt.wantSaved {
+				t.Errorf("interest saved = %v, want %v", schedule.InterestSaved, tt.wantSaved)
+			}
+			if schedule.MonthsSaved != tt.wantMonthsCut {
+				t.Errorf("months saved = %d, want %d", schedule.MonthsSaved, tt.wantMonthsCut)
+			}
+		})
+	}
+}
+
+func TestValidateExtraPayments(t *testing.T) {
+	valid := []ExtraPayments{
+		{},
+		{Monthly: 50, LumpSums: []LumpSumPayment{{Month: 12, Amount: 1000}}},
+		{LumpSums: []LumpSumPayment{{Month: 1, Amount: 100}, {Month: 1, Amount: 200}}},
+	}
+	for _, extra := range valid {
+		if err := validateExtraPayments(extra, 12); err != nil {
+			t.Errorf("validateExtraPayments(%+v) = %v, want nil", extra, err)
+		}
+	}
+
+	invalid := []ExtraPayments{
+		{Monthly: -1},
+		{LumpSums: []LumpSumPayment{{Month: 0, Amount: 100}}},
+		{LumpSums: []LumpSumPayment{{Month: 13, Amount: 100}}},
+		{LumpSums: []LumpSumPayment{{Month: 3, Amount: 0}}},
+	}
+	for _, extra := range invalid {
+		if err := validateExtraPayments(extra, 12); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("validateExtraPayments(%+v) = %v, want ErrInvalidInput", extra, err)
+		}
+	}
+}
+
+// memorySaleRepository serves sales from memory
+type memorySaleRepository struct {
+	mysqlrepo.SaleRepository
+	sales map[string]mysql.Sale
+}
+
+func (r *memorySaleRepository) GetByID(ctx context.Context, id string) (mysql.Sale, error) {
+	sale, ok := r.sales[id]
+	if !ok {
+		return sale, fmt.Errorf("sale with id %s not found: %w", id, mysqlrepo.ErrNotFound)
+	}
+	return sale, nil
+}
+
+func TestGetSaleAmortizationUsesTheTermsSoldAt(t *testing.T) {
+	financed := mysql.Sale{
+		ID: "sale-1", Payment_Method: mysql.PaymentMethodFinance,
+		Finance_Amount: 12000, Finance_Term: 12, Interest_Rate: 0, Monthly_Payment: 1000,
+	}
+	s := &service{sales_repo: &memorySaleRepository{sales: map[string]mysql.Sale{financed.ID: financed}}}
+
+	schedule, err := s.GetSaleAmortization(context.Background(), financed.ID, ExtraPayments{})
+	if err != nil {
+		t.Fatalf("GetSaleAmortization returned error: %v", err)
+	}
+	if schedule.PayoffMonths != 12 || schedule.Payments[0].Payment != 1000 {
+		t.Errorf("paid off in %d months starting at %v, want 12 months of 1000", schedule.PayoffMonths, schedule.Payments[0].Payment)
+	}
+}
+
+func TestGetSaleAmortizationRefusesSalesNotFinanced(t *testing.T) {
+	sales := map[string]mysql.Sale{
+		"cash":  {ID: "cash", Payment_Method: mysql.PaymentMethodCash},
+		"lease": {ID: "lease", Payment_Method: mysql.PaymentMethodLease, Finance_Amount: 20000, Finance_Term: 36},
+	}
+	s := &service{sales_repo: &memorySaleRepository{sales: sales}}
+
+	for id := range sales {
+		if _, err := s.GetSaleAmortization(context.Background(), id, ExtraPayments{}); !errors.Is(err, ErrInvalidState) {
+			t.Errorf("GetSaleAmortization(%s) error = %v, want ErrInvalidState", id, err)
+		}
+	}
+	if _, err := s.GetSaleAmortization(context.Background(), "missing", ExtraPayments{}); !errors.Is(err, mysqlrepo.ErrNotFound) {
+		t.Errorf("GetSaleAmortization(missing) error = %v, want ErrNotFound", err)
+	}
+}
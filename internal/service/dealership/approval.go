@@ -0,0 +1,225 @@
+package dealership
+
+import (
+	"api-servers/internal/models/mysql"
+	mysqlrepo "api-servers/internal/repository/mysql"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ApprovalPolicy decides which sales need a manager's approval before the
+// vehicle is sold
+type ApprovalPolicy struct {
+	// discounts above this share of the list price, e.g. 0.1 for 10%
+	MaxDiscount float64
+
+	// financed and leased sales to customers scoring below this
+	MinCreditScore int
+}
+
+var DefaultApprovalPolicy = ApprovalPolicy{
+	MaxDiscount:    0.1,
+	MinCreditScore: 700,
+}
+
+// reasons lists why a sale needs approval; none means it can complete
+// straight away. creditDecision is nil for cash sales.
+func (p ApprovalPolicy) reasons(listPrice, discount float64, creditDecision *CreditDecision) []string {
+	var reasons []string
+	if listPrice > 0 && discount/listPrice > p.MaxDiscount {
+		reasons = append(reasons, fmt.Sprintf("discount of %.1f%% is above the maximum of %.1f%%", discount/listPrice*100, p.MaxDiscount*100))
+	}
+	if creditDecision != nil && creditDecision.CreditScore < p.MinCreditScore {
+		reasons = append(reasons, fmt.Sprintf("credit score %d is below the minimum of %d", creditDecision.CreditScore, p.MinCreditScore))
+	}
+	return reasons
+}
+
+func (s *service) GetPendingSales(ctx context.Context) ([]mysql.Sale, error) {
+	sales, err := s.sales_repo.GetByStatus(ctx, mysql.SaleStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending sales: %w", err)
+	}
+	if sales == nil {
+		sales = []mysql.Sale{}
+	}
+	return sales, nil
+}
+
+func (s *service) GetSaleApprovals(ctx context.Context, saleID string) ([]mysql.SaleApproval, error) {
+	if _, err := s.sales_repo.GetByID(ctx, saleID); err != nil {
+		return nil, err
+	}
+
+	approvals, err := s.approval_repo.GetBySaleId(ctx, saleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approvals for sale %s: %w", saleID, err)
+	}
+	return approvals, nil
+}
+
+// ApproveSale completes a pending sale: the vehicle is sold, any trade-in is
+// taken into inventory and the contract is generated
+func (s *service) ApproveSale(ctx context.Context, saleID string, review SaleReviewRequest) (*SaleReview, error) {
+	sale, err := s.reviewableSale(ctx, saleID, review.ManagerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	approval := newSaleApproval(sale.ID, review, mysql.ApprovalDecisionApproved, now)
+
+	parties, err := s.contractParties(ctx, sale)
+	if err != nil {
+		return nil, err
+	}
+	contract, err := s.newSaleContract(parties, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var tradedVehicle *mysql.Vehicle
+	err = s.transactor.WithTx(ctx, func(repos mysqlrepo.Repositories) error {
+		err := repos.Sales.Complete(ctx, sale.ID, now)
+		if errors.Is(err, mysqlrepo.ErrConflict) {
+			return &ConflictError{Resource: "sale", ID: sale.ID, Err: err}
+		}
+		if err != nil {
+			return err
+		}
+
+		err = repos.Vehicles.UpdateStatus(ctx, sale.Vehicle_ID, mysql.VehicleStatusSold, mysql.VehicleStatusPending)
+		if errors.Is(err, mysqlrepo.ErrConflict) {
+			return &ConflictError{Resource: "vehicle", ID: sale.Vehicle_ID, Err: err}
+		}
+		if err != nil {
+			return err
+		}
+
+		tradeIn, err := repos.TradeIns.GetBySaleId(ctx, sale.ID)
+		switch {
+		case err == nil:
+			traded, err := stockTradeIn(ctx, repos, &tradeIn, now)
+			if err != nil {
+				return err
+			}
+			tradedVehicle = &traded
+		case !errors.Is(err, mysqlrepo.ErrNotFound):
+			return err
+		}
+
+		if err := repos.Contracts.Create(ctx, contract); err != nil {
+			return err
+		}
+		return repos.Approvals.Create(ctx, approval)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve sale %s: %w", saleID, err)
+	}
+
+	sale.Status = mysql.SaleStatusCompleted
+	sale.Completed_At = &now
+	sale.Updated_At = now
+
+	return &SaleReview{
+		Sale:           sale,
+		Approval:       approval,
+		Contract:       &contract,
+		TradeInVehicle: tradedVehicle,
+	}, nil
+}
+
+// RejectSale turns down a pending sale, releasing the vehicle and returning
+// any trade-in. Rejected sales are kept apart from cancellations: they never
+// completed, so there is nothing to refund or reverse.
+func (s *service) RejectSale(ctx context.Context, saleID string, review SaleReviewRequest) (*SaleReview, error) {
+	sale, err := s.reviewableSale(ctx, saleID, review.ManagerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	approval := newSaleApproval(sale.ID, review, mysql.ApprovalDecisionRejected, now)
+
+	var returned *mysql.TradeIn
+	err = s.transactor.WithTx(ctx, func(repos mysqlrepo.Repositories) error {
+		err := repos.Sales.UpdateStatus(ctx, sale.ID, mysql.SaleStatusRejected, mysql.SaleStatusPending)
+		if errors.Is(err, mysqlrepo.ErrConflict) {
+			return &ConflictError{Resource: "sale", ID: sale.ID, Err: err}
+		}
+		if err != nil {
+			return err
+		}
+
+		returned, err = releaseSale(ctx, repos, sale, now)
+		if err != nil {
+			return err
+		}
+		return repos.Approvals.Create(ctx, approval)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject sale %s: %w", saleID, err)
+	}
+
+	sale.Status = mysql.SaleStatusRejected
+	sale.Updated_At = now
+
+	return &SaleReview{
+		Sale:            sale,
+		Approval:        approval,
+		ReturnedTradeIn: returned,
+	}, nil
+}
+
+// approval helper functions
+
+// reviewableSale loads a pending sale and checks managerID may decide on it.
+// Managers can't approve their own sales. managerID comes from the request
+// body and is not authenticated, so these checks only stop mistakes, not a
+// caller claiming to be someone else.
+func (s *service) reviewableSale(ctx context.Context, saleID, managerID string) (mysql.Sale, error) {
+	if strings.TrimSpace(managerID) == "" {
+		return mysql.Sale{}, fmt.Errorf("manager_id is required: %w", ErrInvalidInput)
+	}
+
+	sale, err := s.sales_repo.GetByID(ctx, saleID)
+	if err != nil {
+		return sale, err
+	}
+	if sale.Status != mysql.SaleStatusPending {
+		return sale, fmt.Errorf("sale %s is %s, not pending: %w", saleID, sale.Status, ErrInvalidState)
+	}
+
+	// an unknown manager is refused like any other salesperson who can't
+	// review, rather than reported as the sale not being found
+	manager, err := s.salesperson_repo.GetByID(ctx, managerID)
+	if errors.Is(err, mysqlrepo.ErrNotFound) {
+		return sale, fmt.Errorf("salesperson %s is not an active manager: %w", managerID, ErrForbidden)
+	}
+	if err != nil {
+		return sale, fmt.Errorf("failed to load manager %s: %w", managerID, err)
+	}
+	if manager.Role != mysql.SalesPersonRoleManager || manager.Status != mysql.SalesPersonStatusActive {
+		return sale, fmt.Errorf("salesperson %s is not an active manager: %w", managerID, ErrForbidden)
+	}
+	if manager.ID == sale.Salesperson_ID {
+		return sale, fmt.Errorf("managers cannot review their own sales: %w", ErrForbidden)
+	}
+	return sale, nil
+}
+
+func newSaleApproval(saleID string, review SaleReviewRequest, decision mysql.ApprovalDecision, now time.Time) mysql.SaleApproval {
+	return mysql.SaleApproval{
+		ID:         uuid.New().String(),
+		Sale_ID:    saleID,
+		Manager_ID: review.ManagerID,
+		Decision:   decision,
+		Notes:      strings.TrimSpace(review.Notes),
+		Decided_At: now,
+	}
+}
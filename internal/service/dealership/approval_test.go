@@ -0,0 +1,291 @@
+package dealership
+
+import (
+	"api-servers/internal/models/mysql"
+	mysqlrepo "api-servers/internal/repository/mysql"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// memoryTransactor runs a transaction's work straight against the memory
+// repositories; nothing is rolled back if it fails
+type memoryTransactor struct {
+	repos mysqlrepo.Repositories
+}
+
+func (t memoryTransactor) WithTx(ctx context.Context, fn func(repos mysqlrepo.Repositories) error) error {
+	return fn(t.repos)
+}
+
+func (r *memorySaleRepository) UpdateStatus(ctx context.Context, id string, status mysql.SaleStatus, from ...mysql.SaleStatus) error {
+	sale, ok := r.sales[id]
+	if !ok {
+		return fmt.Errorf("sale with id %s not found: %w", id, mysqlrepo.ErrNotFound)
+	}
+	if len(from) > 0 && !slices.Contains(from, sale.Status) {
+		return fmt.Errorf("sale %s is %s: %w", id, sale.Status, mysqlrepo.ErrConflict)
+	}
+	sale.Status = status
+	r.sales[id] = sale
+	return nil
+}
+
+func (r *memorySaleRepository) Complete(ctx context.Context, id string, at time.Time) error {
+	if err := r.UpdateStatus(ctx, id, mysql.SaleStatusCompleted, mysql.SaleStatusPending); err != nil {
+		return err
+	}
+	sale := r.sales[id]
+	sale.Completed_At = &at
+	r.sales[id] = sale
+	return nil
+}
+
+func (r *memoryTradeInRepository) GetBySaleId(ctx context.Context, saleID string) (mysql.TradeIn, error) {
+	for _, tradeIn := range r.tradeIns {
+		if tradeIn.Sale_ID != nil && *tradeIn.Sale_ID == saleID {
+			return tradeIn, nil
+		}
+	}
+	return mysql.TradeIn{}, fmt.Errorf("trade-in for sale %s not found: %w", saleID, mysqlrepo.ErrNotFound)
+}
+
+type memorySalespersonRepository struct {
+	mysqlrepo.SalespersonRepository
+	salespeople map[string]mysql.Salesperson
+}
+
+func (r *memorySalespersonRepository) GetByID(ctx context.Context, id string) (mysql.Salesperson, error) {
+	salesperson, ok := r.salespeople[id]
+	if !ok {
+		return salesperson, fmt.Errorf("salesperson with id %s not found: %w", id, mysqlrepo.ErrNotFound)
+	}
+	return salesperson, nil
+}
+
+type memoryCustomerRepository struct {
+	mysqlrepo.CustomerRepository
+	customers map[string]mysql.Customer
+}
+
+func (r *memoryCustomerRepository) GetByID(ctx context.Context, id string) (mysql.Customer, error) {
+	customer, ok := r.customers[id]
+	if !ok {
+		return customer, fmt.Errorf("customer with id %s not found: %w", id, mysqlrepo.ErrNotFound)
+	}
+	return customer, nil
+}
+
+// recordingApprovalRepository and recordingContractRepository keep what was
+// written to them
+type recordingApprovalRepository struct {
+	mysqlrepo.SaleApprovalRepository
+	approvals []mysql.SaleApproval
+}
+
+func (r *recordingApprovalRepository) Create(ctx context.Context, approval mysql.SaleApproval) error {
+	r.approvals = append(r.approvals, approval)
+	return nil
+}
+
+type recordingContractRepository struct {
+	mysqlrepo.SaleContractRepository
+	contracts []mysql.SaleContract
+}
+
+func (r *recordingContractRepository) Create(ctx context.Context, contract mysql.SaleContract) error {
+	r.contracts = append(r.contracts, contract)
+	return nil
+}
+
+// approvalDesk is a sale waiting on approval and the people who might review
+// it: an active manager, an inactive one and the salesperson who made it
+type approvalDesk struct {
+	service   *service
+	sales     *memorySaleRepository
+	vehicles  *memoryVehicleRepository
+	approvals *recordingApprovalRepository
+	contracts *recordingContractRepository
+}
+
+func newApprovalDesk() *approvalDesk {
+	vehicle := testVehicle(0)
+	vehicle.Status = mysql.VehicleStatusPending
+
+	hired := time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)
+	staff := map[string]mysql.Salesperson{}
+	for _, sp := range []mysql.Salesperson{
+		{ID: "manager-1", First_Name: "Morgan", Role: mysql.SalesPersonRoleManager, Status: mysql.SalesPersonStatusActive},
+		{ID: "manager-2", First_Name: "Riley", Role: mysql.SalesPersonRoleManager, Status: mysql.SalesPersonStatusInactive},
+		{ID: "salesperson-1", First_Name: "Sam", Role: mysql.SalesPersonRoleSales, Status: mysql.SalesPersonStatusActive},
+	} {
+		sp.Last_Name, sp.Hire_Date, sp.Commission = "Jones", hired, 0.03
+		staff[sp.ID] = sp
+	}
+
+	desk := &approvalDesk{
+		sales: &memorySaleRepository{sales: map[string]mysql.Sale{
+			"sale-1": {
+				ID: "sale-1", Vehicle_ID: vehicle.ID, Customer_ID: "customer-1", Salesperson_ID: "salesperson-1",
+				Sale_Date: time.Now(), Sale_Price: 20000, Discount: 4000, Out_The_Door_Price: 21500,
+				Payment_Method: mysql.PaymentMethodCash, Status: mysql.SaleStatusPending,
+				Approval_Reason: "discount of 16.7% is above the maximum of 10.0%",
+			},
+		}},
+		vehicles:  newMemoryVehicleRepository(vehicle),
+		approvals: &recordingApprovalRepository{},
+		contracts: &recordingContractRepository{},
+	}
+	salespeople := &memorySalespersonRepository{salespeople: staff}
+	customers := &memoryCustomerRepository{customers: map[string]mysql.Customer{
+		"customer-1": {ID: "customer-1", First_Name: "Pat", Last_Name: "Lee", Email: "pat.lee@example.com"},
+	}}
+	tradeIns := &memoryTradeInRepository{tradeIns: map[string]mysql.TradeIn{}}
+
+	desk.service = &service{
+		sales_repo:       desk.sales,
+		vehicle_repo:     desk.vehicles,
+		salesperson_repo: salespeople,
+		customer_repo:    customers,
+		trade_in_repo:    tradeIns,
+		contract_key:     []byte("approval-test-key"),
+		transactor: memoryTransactor{repos: mysqlrepo.Repositories{
+			Sales:       desk.sales,
+			Vehicles:    desk.vehicles,
+			Salespeople: salespeople,
+			Customers:   customers,
+			TradeIns:    tradeIns,
+			Approvals:   desk.approvals,
+			Contracts:   desk.contracts,
+		}},
+	}
+	return desk
+}
+
+func (d *approvalDesk) vehicleStatus(t *testing.T) mysql.VehicleStatus {
+	t.Helper()
+	vehicle, err := d.vehicles.GetByID(context.Background(), "vehicle-1")
+	if err != nil {
+		t.Fatalf("failed to load vehicle: %v", err)
+	}
+	return vehicle.Status
+}
+
+func TestApproveSaleSellsTheVehicle(t *testing.T) {
+	desk := newApprovalDesk()
+
+	review, err := desk.service.ApproveSale(context.Background(), "sale-1", SaleReviewRequest{ManagerID: "manager-1", Notes: "  regular customer  "})
+	if err != nil {
+		t.Fatalf("ApproveSale returned error: %v", err)
+	}
+
+	if stored := desk.sales.sales["sale-1"]; stored.Status != mysql.SaleStatusCompleted || stored.Completed_At == nil {
+		t.Errorf("stored sale is %s completed at %v, want completed with a time", stored.Status, stored.Completed_At)
+	}
+	if status := desk.vehicleStatus(t); status != mysql.VehicleStatusSold {
+		t.Errorf("vehicle is %s, want sold", status)
+	}
+
+	if len(desk.approvals.approvals) != 1 {
+		t.Fatalf("recorded %d approvals, want 1", len(desk.approvals.approvals))
+	}
+	approval := desk.approvals.approvals[0]
+	if approval.Manager_ID != "manager-1" || approval.Decision != mysql.ApprovalDecisionApproved || approval.Notes != "regular customer" {
+		t.Errorf("approval = %+v, want approved by manager-1 with trimmed notes", approval)
+	}
+
+	if len(desk.contracts.contracts) != 1 || review.Contract == nil {
+		t.Fatalf("created %d contracts, want the approved sale's", len(desk.contracts.contracts))
+	}
+	if err := desk.service.verifyContract(desk.contracts.contracts[0]); err != nil {
+		t.Errorf("contract from the approval doesn't verify: %v", err)
+	}
+}
+
+func TestRejectSaleReleasesTheVehicle(t *testing.T) {
+	desk := newApprovalDesk()
+
+	review, err := desk.service.RejectSale(context.Background(), "sale-1", SaleReviewRequest{ManagerID: "manager-1"})
+	if err != nil {
+		t.Fatalf("RejectSale returned error: %v", err)
+	}
+
+	if stored := desk.sales.sales["sale-1"]; stored.Status != mysql.SaleStatusRejected || stored.Cancelled_At != nil {
+		t.Errorf("stored sale is %s cancelled at %v, want rejected and not cancelled", stored.Status, stored.Cancelled_At)
+	}
+	if status := desk.vehicleStatus(t); status != mysql.VehicleStatusAvailable {
+		t.Errorf("vehicle is %s, want available", status)
+	}
+	if review.Contract != nil || len(desk.contracts.contracts) != 0 {
+		t.Error("rejecting a sale generated a contract")
+	}
+	if len(desk.approvals.approvals) != 1 || desk.approvals.approvals[0].Decision != mysql.ApprovalDecisionRejected {
+		t.Errorf("approvals = %+v, want one rejection", desk.approvals.approvals)
+	}
+}
+
+// Once a sale has been decided it can't be decided again either way.
+func TestReviewedSalesCannotBeReviewedAgain(t *testing.T) {
+	ctx := context.Background()
+	review := SaleReviewRequest{ManagerID: "manager-1"}
+
+	for _, first := range []string{"approve", "reject"} {
+		desk := newApprovalDesk()
+		decide := map[string]func(context.Context, string, SaleReviewRequest) (*SaleReview, error){
+			"approve": desk.service.ApproveSale,
+			"reject":  desk.service.RejectSale,
+		}
+		if _, err := decide[first](ctx, "sale-1", review); err != nil {
+			t.Fatalf("first %s returned error: %v", first, err)
+		}
+
+		for then, again := range decide {
+			if _, err := again(ctx, "sale-1", review); !errors.Is(err, ErrInvalidState) {
+				t.Errorf("%s after %s error = %v, want ErrInvalidState", then, first, err)
+			}
+		}
+		if len(desk.approvals.approvals) != 1 {
+			t.Errorf("after %s, %d decisions were recorded, want 1", first, len(desk.approvals.approvals))
+		}
+	}
+}
+
+func TestWhoMayReviewASale(t *testing.T) {
+	tests := []struct {
+		name      string
+		managerID string
+		soldBy    string
+		wantErr   error
+	}{
+		{name: "no manager_id", managerID: " ", wantErr: ErrInvalidInput},
+		{name: "unknown salesperson", managerID: "manager-9", wantErr: ErrForbidden},
+		{name: "not a manager", managerID: "salesperson-1", soldBy: "manager-1", wantErr: ErrForbidden},
+		{name: "inactive manager", managerID: "manager-2", wantErr: ErrForbidden},
+		{name: "manager's own sale", managerID: "manager-1", soldBy: "manager-1", wantErr: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desk := newApprovalDesk()
+			if tt.soldBy != "" {
+				sale := desk.sales.sales["sale-1"]
+				sale.Salesperson_ID = tt.soldBy
+				desk.sales.sales["sale-1"] = sale
+			}
+
+			_, err := desk.service.ApproveSale(context.Background(), "sale-1", SaleReviewRequest{ManagerID: tt.managerID})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ApproveSale error = %v, want %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrNotFound) {
+				t.Errorf("ApproveSale error %v reads as the sale not being found", err)
+			}
+			if status := desk.sales.sales["sale-1"].Status; status != mysql.SaleStatusPending {
+				t.Errorf("sale is %s after a refused review, want pending", status)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package dealership
+
+import (
+	"api-servers/internal/document"
+	"api-servers/internal/models/mysql"
+	mysqlrepo "api-servers/internal/repository/mysql"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// contractParties is everything a contract is filled in from. TradeIn is nil
+// when the customer didn't trade a vehicle in.
+type contractParties struct {
+	Sale        mysql.Sale
+	Customer    mysql.Customer
+	Vehicle     mysql.Vehicle
+	Salesperson mysql.Salesperson
+	TradeIn     *mysql.TradeIn
+}
+
+// GetSaleContract returns the stored contract for a sale after checking it
+// still matches its signed content hashes. Contracts are only generated when
+// a sale completes; a sale without one is not rebuilt from the customer and
+// vehicle as they are now.
+func (s *service) GetSaleContract(ctx context.Context, saleID string) (*mysql.SaleContract, error) {
+	if _, err := s.sales_repo.GetByID(ctx, saleID); err != nil {
+		return nil, err
+	}
+
+	contract, err := s.contract_repo.GetBySaleId(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyContract(contract); err != nil {
+		return nil, err
+	}
+	return &contract, nil
+}
+
+// contract helper functions
+
+// contractParties loads the customer, vehicle, salesperson and trade-in of a
+// sale being approved, so its contract describes them as they are when the
+// sale completes. Deleted customers and vehicles are still loaded.
+func (s *service) contractParties(ctx context.Context, sale mysql.Sale) (contractParties, error) {
+	parties := contractParties{Sale: sale}
+
+	var err error
+	parties.Customer, err = s.customer_repo.GetByID(ctx, sale.Customer_ID)
+	if err != nil {
+		return parties, fmt.Errorf("failed to load customer for contract: %w", err)
+	}
+	parties.Vehicle, err = s.vehicle_repo.GetByID(ctx, sale.Vehicle_ID)
+	if err != nil {
+		return parties, fmt.Errorf("failed to load vehicle for contract: %w", err)
+	}
+	parties.Salesperson, err = s.salesperson_repo.GetByID(ctx, sale.Salesperson_ID)
+	if err != nil {
+		return parties, fmt.Errorf("failed to load salesperson for contract: %w", err)
+	}
+
+	tradeIn, err := s.trade_in_repo.GetBySaleId(ctx, sale.ID)
+	switch {
+	case err == nil:
+		parties.TradeIn = &tradeIn
+	case !errors.Is(err, mysqlrepo.ErrNotFound):
+		return parties, fmt.Errorf("failed to load trade-in for contract: %w", err)
+	}
+	return parties, nil
+}
+
+// newSaleContract renders the agreement as HTML and PDF from the same
+// content, hashes each rendering and signs the hashes
+func (s *service) newSaleContract(parties contractParties, now time.Time) (mysql.SaleContract, error) {
+	if len(s.contract_key) == 0 {
+		return mysql.SaleContract{}, errors.New("no contract signing key is configured")
+	}
+
+	contract := mysql.SaleContract{
+		ID:           uuid.New().String(),
+		Sale_ID:      parties.Sale.ID,
+		Terms:        string(contractTermsFor(parties.Sale)),
+		Generated_At: now,
+	}
+
+	htmlDoc := document.NewHTML(contract.Terms)
+	writeContract(htmlDoc, contract.ID, parties)
+	var html bytes.Buffer
+	if _, err := htmlDoc.WriteTo(&html); err != nil {
+		return contract, fmt.Errorf("failed to render contract as HTML: %w", err)
+	}
+
+	pdfDoc := document.NewPDF(contract.Terms)
+	writeContract(pdfDoc, contract.ID, parties)
+	var pdf bytes.Buffer
+	if _, err := pdfDoc.WriteTo(&pdf); err != nil {
+		return contract, fmt.Errorf("failed to render contract as PDF: %w", err)
+	}
+
+	contract.HTML = html.String()
+	contract.HTML_SHA256 = contentHash(html.Bytes())
+	contract.PDF = pdf.Bytes()
+	contract.PDF_SHA256 = contentHash(contract.PDF)
+	contract.Signature = s.contractSignature(contract)
+	return contract, nil
+}
+
+// verifyContract checks each rendering against its hash and the hashes
+// against the signature, which can't be recomputed without the key
+func (s *service) verifyContract(contract mysql.SaleContract) error {
+	if len(s.contract_key) == 0 {
+		return errors.New("no contract signing key is configured")
+	}
+	expected := s.contractSignature(contract)
+	if !hmac.Equal([]byte(expected), []byte(contract.Signature)) {
+		return fmt.Errorf("signature of contract %s for sale %s: %w", contract.ID, contract.Sale_ID, ErrContractAltered)
+	}
+	if contentHash([]byte(contract.HTML)) != contract.HTML_SHA256 {
+		return fmt.Errorf("HTML of contract %s for sale %s: %w", contract.ID, contract.Sale_ID, ErrContractAltered)
+	}
+	if contentHash(contract.PDF) != contract.PDF_SHA256 {
+		return fmt.Errorf("PDF of contract %s for sale %s: %w", contract.ID, contract.Sale_ID, ErrContractAltered)
+	}
+	return nil
+}
+
+// contractSignature is an HMAC-SHA256 over what identifies a contract and
+// its content hashes, so a contract can't be moved to another sale or have
+// its renderings and hashes replaced together
+func (s *service) contractSignature(contract mysql.SaleContract) string {
+	mac := hmac.New(sha256.New, s.contract_key)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%s\n%d", contract.ID, contract.Sale_ID, contract.Terms,
+		contract.HTML_SHA256, contract.PDF_SHA256, contract.Generated_At.Unix())
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func contractTermsFor(sale mysql.Sale) ContractTerms {
+	if sale.Payment_Method == mysql.PaymentMethodLease {
+		return ContractTermsLease
+	}
+	return ContractTermsStandard
+}
+
+// writeContract lays out the agreement: the parties, the vehicle, the money
+// and the numbered terms, then signature lines
+func writeContract(doc document.Builder, contractID string, p contractParties) {
+	sale := p.Sale
+	dealer, customer := partyNames(sale)
+
+	doc.Text(fmt.Sprintf("Contract %s for sale %s, dated %s.", contractID, sale.ID, sale.Sale_Date.Format("January 2, 2006")))
+	doc.Blank()
+
+	doc.Heading("Parties")
+	doc.Text(fmt.Sprintf("%s: %s %s, %s, %s, %s %s. Email %s, phone %s.",
+		customer, p.Customer.First_Name, p.Customer.Last_Name,
+		p.Customer.Address, p.Customer.City, p.Customer.State, p.Customer.Zip_Code,
+		p.Customer.Email, p.Customer.Phone))
+	doc.Text(fmt.Sprintf("%s: the dealership, represented by %s %s.", dealer, p.Salesperson.First_Name, p.Salesperson.Last_Name))
+	doc.Blank()
+
+	doc.Table(document.Table{
+		Title:   "Vehicle",
+		Columns: []string{"Year", "Make", "Model", "Color", "VIN", "Odometer"},
+		Rows: [][]interface{}{
+			{p.Vehicle.Year, p.Vehicle.Make, p.Vehicle.Model, p.Vehicle.Color, p.Vehicle.VIN, p.Vehicle.Mileage},
+		},
+	})
+
+	doc.Table(document.Table{
+		Title:   "Price and payment",
+		Columns: []string{"Item", "Amount"},
+		Rows:    contractAmounts(sale),
+	})
+
+	if p.TradeIn != nil {
+		doc.Heading("Trade-in")
+		doc.Text(fmt.Sprintf("%d %s %s, VIN %s, %d miles, credited at $%.2f.",
+			p.TradeIn.Year, p.TradeIn.Make, p.TradeIn.Model, p.TradeIn.VIN, p.TradeIn.Mileage, p.TradeIn.Offer_Amount))
+		doc.Blank()
+	}
+
+	doc.Heading("Terms")
+	for i, clause := range contractClauses(p) {
+		doc.Text(fmt.Sprintf("%d. %s", i+1, clause))
+	}
+	doc.Blank()
+
+	doc.Heading("Signatures")
+	doc.Text(fmt.Sprintf("%s: ______________________________  Date: ____________", customer))
+	doc.Blank()
+	doc.Text(fmt.Sprintf("%s: ______________________________  Date: ____________", dealer))
+}
+
+func contractAmounts(sale mysql.Sale) [][]interface{} {
+	money := func(amount float64) string { return fmt.Sprintf("%.2f", amount) }
+
+	rows := [][]interface{}{
+		{"List price", money(sale.Sale_Price + sale.Discount)},
+	}
+	if sale.Discount > 0 {
+		rows = append(rows, []interface{}{"Discount", money(-sale.Discount)})
+	}
+	rows = append(rows, []interface{}{"Sale price", money(sale.Sale_Price)})
+	if sale.Out_The_Door_Price > sale.Sale_Price {
+		rows = append(rows,
+			[]interface{}{"Sales tax", money(sale.Sales_Tax)},
+			[]interface{}{"Doc fee", money(sale.Doc_Fee)},
+			[]interface{}{"Registration fee", money(sale.Registration_Fee)},
+			[]interface{}{"Title fee", money(sale.Title_Fee)},
+			[]interface{}{"Out-the-door price", money(sale.Out_The_Door_Price)},
+		)
+	}
+	if sale.Trade_In_Credit > 0 {
+		rows = append(rows, []interface{}{"Trade-in credit", money(-sale.Trade_In_Credit)})
+	}
+	rows = append(rows, []interface{}{"Down payment", money(sale.Down_Payment)})
+
+	totalOfPayments := roundCents(sale.Monthly_Payment * float64(sale.Finance_Term))
+	switch sale.Payment_Method {
+	case mysql.PaymentMethodFinance:
+		rows = append(rows,
+			[]interface{}{"Amount financed", money(sale.Finance_Amount)},
+			[]interface{}{"Annual percentage rate", fmt.Sprintf("%.2f%%", sale.Interest_Rate)},
+			[]interface{}{"Term (months)", sale.Finance_Term},
+			[]interface{}{"Monthly payment", money(sale.Monthly_Payment)},
+			[]interface{}{"Finance charge", money(totalOfPayments - sale.Finance_Amount)},
+			[]interface{}{"Total of payments", money(totalOfPayments)},
+		)
+	case mysql.PaymentMethodLease:
+		rows = append(rows,
+			[]interface{}{"Adjusted capitalized cost", money(sale.Finance_Amount)},
+			[]interface{}{"Residual value", money(sale.Residual_Value)},
+			[]interface{}{"Money factor", fmt.Sprintf("%.5f", sale.Money_Factor)},
+			[]interface{}{"Equivalent APR", fmt.Sprintf("%.2f%%", sale.Interest_Rate)},
+			[]interface{}{"Term (months)", sale.Finance_Term},
+			[]interface{}{"Monthly payment", money(sale.Monthly_Payment)},
+			[]interface{}{"Total of payments", money(totalOfPayments)},
+		)
+	}
+	return rows
+}
+
+// contractClauses are the numbered terms for the sale's payment method
+func contractClauses(p contractParties) []string {
+	sale := p.Sale
+	var clauses []string
+
+	switch sale.Payment_Method {
+	case mysql.PaymentMethodLease:
+		clauses = append(clauses,
+			fmt.Sprintf("Lease. The lessor leases the vehicle described above to the lessee for %d months. The lessee does not own the vehicle.", sale.Finance_Term),
+			fmt.Sprintf("Payments. The lessee will make %d monthly payments of $%.2f. The down payment and first monthly payment are due at signing.", sale.Finance_Term, sale.Monthly_Payment),
+			fmt.Sprintf("Mileage. The lease allows %d miles a year. Miles driven over the allowance for the full term are charged at $%.2f a mile when the vehicle is returned.", sale.Annual_Mileage, leaseExcessMileageRate),
+			fmt.Sprintf("End of lease. At the end of the term the lessee may return the vehicle or buy it for the residual value of $%.2f.", sale.Residual_Value),
+			"Care and insurance. The lessee will keep the vehicle insured, maintained to the manufacturer's schedule and free of damage beyond normal wear.",
+		)
+	case mysql.PaymentMethodFinance:
+		clauses = append(clauses,
+			"Sale. The seller sells and the buyer buys the vehicle described above for the sale price shown.",
+			fmt.Sprintf("Financing. The buyer will repay the amount financed of $%.2f in %d monthly payments of $%.2f at an annual percentage rate of %.2f%%. Payments may be made early without penalty.", sale.Finance_Amount, sale.Finance_Term, sale.Monthly_Payment, sale.Interest_Rate),
+			"Title. Ownership passes to the buyer on signing, subject to the lender's security interest until the amount financed is repaid.",
+		)
+	default:
+		clauses = append(clauses,
+			"Sale. The seller sells and the buyer buys the vehicle described above for the sale price shown.",
+			fmt.Sprintf("Payment. The balance of $%.2f is paid in full at signing.", sale.Down_Payment),
+			"Title. Ownership passes to the buyer on signing.",
+		)
+	}
+
+	if p.TradeIn != nil {
+		_, customer := partyNames(sale)
+		clauses = append(clauses, fmt.Sprintf("Trade-in. The %s transfers ownership of the trade-in vehicle to the dealership and confirms it is free of liens and in the %s condition described at appraisal.",
+			strings.ToLower(customer), p.TradeIn.Condition))
+	}
+	clauses = append(clauses, fmt.Sprintf("Entire agreement. This document is the whole agreement between the parties. The vehicle's odometer reading at sale was %d miles.", p.Vehicle.Mileage))
+	return clauses
+}
+
+// partyNames is what the contract calls the dealership and the customer
+func partyNames(sale mysql.Sale) (dealer, customer string) {
+	if sale.Payment_Method == mysql.PaymentMethodLease {
+		return "Lessor", "Lessee"
+	}
+	return "Seller", "Buyer"
+}
@@ -0,0 +1,122 @@
+package dealership
+
+import (
+	"api-servers/internal/models/mysql"
+	mysqlrepo "api-servers/internal/repository/mysql"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type memoryContractRepository struct {
+	mysqlrepo.SaleContractRepository
+	contracts map[string]mysql.SaleContract
+}
+
+func (r *memoryContractRepository) GetBySaleId(ctx context.Context, saleID string) (mysql.SaleContract, error) {
+	contract, ok := r.contracts[saleID]
+	if !ok {
+		return contract, fmt.Errorf("contract for sale %s not found: %w", saleID, mysqlrepo.ErrNotFound)
+	}
+	return contract, nil
+}
+
+func signedTestContract(t *testing.T, s *service) mysql.SaleContract {
+	t.Helper()
+
+	sale := mysql.Sale{
+		ID: "sale-1", Sale_Date: time.Date(2026, time.March, 2, 15, 0, 0, 0, time.UTC),
+		Sale_Price: 24000, Out_The_Door_Price: 25800, Payment_Method: mysql.PaymentMethodCash,
+	}
+	parties := contractParties{
+		Sale:        sale,
+		Customer:    mysql.Customer{First_Name: "Pat", Last_Name: "Lee", Email: "pat.lee@example.com"},
+		Vehicle:     testVehicle(0),
+		Salesperson: mysql.Salesperson{First_Name: "Sam", Last_Name: "Jones"},
+	}
+
+	contract, err := s.newSaleContract(parties, sale.Sale_Date)
+	if err != nil {
+		t.Fatalf("newSaleContract returned error: %v", err)
+	}
+	return contract
+}
+
+func TestContractVerifiesUntilItIsChanged(t *testing.T) {
+	s := &service{contract_key: []byte("contract-test-key")}
+	signed := signedTestContract(t, s)
+
+	if err := s.verifyContract(signed); err != nil {
+		t.Fatalf("freshly signed contract doesn't verify: %v", err)
+	}
+	if !strings.Contains(signed.HTML, "24000.00") || len(signed.PDF) == 0 {
+		t.Fatal("contract wasn't rendered as HTML and PDF")
+	}
+
+	rehash := func(c *mysql.SaleContract) {
+		c.HTML_SHA256 = contentHash([]byte(c.HTML))
+		c.PDF_SHA256 = contentHash(c.PDF)
+	}
+	changes := map[string]func(c *mysql.SaleContract){
+		"HTML edited":              func(c *mysql.SaleContract) { c.HTML = strings.Replace(c.HTML, "24000.00", "14000.00", 1) },
+		"PDF replaced":             func(c *mysql.SaleContract) { c.PDF = append([]byte{}, c.PDF[:len(c.PDF)-1]...) },
+		"HTML edited and rehashed": func(c *mysql.SaleContract) { c.HTML += "<p>extra</p>"; rehash(c) },
+		"moved to another sale":    func(c *mysql.SaleContract) { c.Sale_ID = "sale-2" },
+		"terms swapped":            func(c *mysql.SaleContract) { c.Terms = string(ContractTermsLease) },
+		"backdated":                func(c *mysql.SaleContract) { c.Generated_At = c.Generated_At.AddDate(0, 0, -1) },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			altered := signed
+			change(&altered)
+			if err := s.verifyContract(altered); !errors.Is(err, ErrContractAltered) {
+				t.Errorf("verifyContract error = %v, want ErrContractAltered", err)
+			}
+		})
+	}
+}
+
+func TestContractSignedUnderAnotherKeyIsRejected(t *testing.T) {
+	signed := signedTestContract(t, &service{contract_key: []byte("previous-key")})
+
+	rotated := &service{contract_key: []byte("current-key")}
+	if err := rotated.verifyContract(signed); !errors.Is(err, ErrContractAltered) {
+		t.Errorf("verifyContract under another key error = %v, want ErrContractAltered", err)
+	}
+
+	unconfigured := &service{}
+	if err := unconfigured.verifyContract(signed); err == nil || errors.Is(err, ErrContractAltered) {
+		t.Errorf("verifyContract without a key error = %v, want a configuration error", err)
+	}
+	if _, err := unconfigured.newSaleContract(contractParties{}, time.Now()); err == nil {
+		t.Error("newSaleContract without a key returned nil error")
+	}
+}
+
+func TestGetSaleContractVerifiesWhatIsStored(t *testing.T) {
+	s := &service{contract_key: []byte("contract-test-key")}
+	signed := signedTestContract(t, s)
+	tampered := signed
+	tampered.Sale_ID = "sale-2"
+
+	s.sales_repo = &memorySaleRepository{sales: map[string]mysql.Sale{
+		"sale-1": {ID: "sale-1"}, "sale-2": {ID: "sale-2"}, "sale-3": {ID: "sale-3"},
+	}}
+	s.contract_repo = &memoryContractRepository{contracts: map[string]mysql.SaleContract{
+		"sale-1": signed, "sale-2": tampered,
+	}}
+	ctx := context.Background()
+
+	if contract, err := s.GetSaleContract(ctx, "sale-1"); err != nil || contract.ID != signed.ID {
+		t.Errorf("GetSaleContract(sale-1) = contract %s, %v, want the signed contract", contract.ID, err)
+	}
+	if _, err := s.GetSaleContract(ctx, "sale-2"); !errors.Is(err, ErrContractAltered) {
+		t.Errorf("GetSaleContract(sale-2) error = %v, want ErrContractAltered", err)
+	}
+	if _, err := s.GetSaleContract(ctx, "sale-3"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetSaleContract of a sale without a contract error = %v, want ErrNotFound", err)
+	}
+}
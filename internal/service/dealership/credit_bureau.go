@@ -0,0 +1,82 @@
+package dealership
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+)
+
+// CreditBureau pulls a customer's credit report
+type CreditBureau interface {
+	GetCreditReport(ctx context.Context, inquiry CreditInquiry) (*CreditReport, error)
+}
+
+type CreditInquiry struct {
+	CustomerID  string
+	FirstName   string
+	LastName    string
+	Email       string
+	DateOfBirth *time.Time
+}
+
+type CreditReport struct {
+	Bureau      string    `json:"bureau"`
+	Score       int       `json:"score"`
+	MonthlyDebt float64   `json:"monthly_debt"`
+	PulledAt    time.Time `json:"pulled_at"`
+}
+
+type creditFixture struct {
+	score       int
+	monthlyDebt float64
+}
+
+// stubCreditBureau answers from fixtures for known emails and otherwise
+// derives a stable report from the applicant's email and date of birth, so
+// the same customer always gets the same report
+type stubCreditBureau struct {
+	fixtures map[string]creditFixture
+}
+
+// NewStubCreditBureau returns a local bureau for development and demos. The
+// fixtures match the customers created by cmd/seed.
+func NewStubCreditBureau() CreditBureau {
+	return &stubCreditBureau{
+		fixtures: map[string]creditFixture{
+			"michael.johnson@email.com": {score: 720, monthlyDebt: 1450},
+			"sarah.williams@email.com":  {score: 680, monthlyDebt: 1900},
+			"robert.davis@email.com":    {score: 750, monthlyDebt: 2100},
+		},
+	}
+}
+
+func (b *stubCreditBureau) GetCreditReport(ctx context.Context, inquiry CreditInquiry) (*CreditReport, error) {
+	if inquiry.Email == "" {
+		return nil, fmt.Errorf("credit inquiry for customer %s has no email", inquiry.CustomerID)
+	}
+
+	fixture, ok := b.fixtures[strings.ToLower(inquiry.Email)]
+	if !ok {
+		key := strings.ToLower(inquiry.Email)
+		if inquiry.DateOfBirth != nil {
+			key += inquiry.DateOfBirth.Format("2006-01-02")
+		}
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		sum := h.Sum32()
+
+		fixture = creditFixture{
+			score:       520 + int(sum%330),
+			monthlyDebt: float64((sum / 330) % 3000),
+		}
+	}
+
+	return &CreditReport{
+		Bureau:      "stub",
+		Score:       fixture.score,
+		MonthlyDebt: fixture.monthlyDebt,
+		PulledAt:    time.Now(),
+	}, nil
+}
@@ -0,0 +1,40 @@
+package dealership
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStubCreditBureauIsStable(t *testing.T) {
+	bureau := NewStubCreditBureau()
+	ctx := context.Background()
+	dob := time.Date(1988, time.May, 14, 0, 0, 0, 0, time.UTC)
+
+	first, err := bureau.GetCreditReport(ctx, CreditInquiry{CustomerID: "customer-1", Email: "Pat.Lee@example.com", DateOfBirth: &dob})
+	if err != nil {
+		t.Fatalf("GetCreditReport returned error: %v", err)
+	}
+	again, err := bureau.GetCreditReport(ctx, CreditInquiry{CustomerID: "customer-1", Email: "pat.lee@example.com", DateOfBirth: &dob})
+	if err != nil {
+		t.Fatalf("GetCreditReport returned error: %v", err)
+	}
+	if first.Score != again.Score || first.MonthlyDebt != again.MonthlyDebt {
+		t.Errorf("reports differ: %+v and %+v", first, again)
+	}
+	if first.Score < 520 || first.Score >= 850 {
+		t.Errorf("score %d is outside 520-849", first.Score)
+	}
+
+	seeded, err := bureau.GetCreditReport(ctx, CreditInquiry{Email: "Robert.Davis@email.com"})
+	if err != nil {
+		t.Fatalf("GetCreditReport returned error: %v", err)
+	}
+	if seeded.Score != 750 || seeded.MonthlyDebt != 2100 {
+		t.Errorf("seeded customer got %d and %v, want the fixture's 750 and 2100", seeded.Score, seeded.MonthlyDebt)
+	}
+
+	if _, err := bureau.GetCreditReport(ctx, CreditInquiry{CustomerID: "customer-2"}); err == nil {
+		t.Error("GetCreditReport without an email returned nil error")
+	}
+}
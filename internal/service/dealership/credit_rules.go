@@ -0,0 +1,87 @@
+package dealership
+
+import "math"
+
+// creditTier prices applicants whose score is at least minScore
+type creditTier struct {
+	minScore       int
+	interestRate   float64
+	incomeMultiple float64
+	reason         CreditApprovalReason
+}
+
+// creditPolicy turns a bureau score, income and debt into a decision.
+// Tiers are checked in order, so list them from the highest score down.
+type creditPolicy struct {
+	tiers []creditTier
+
+	// applicants above maxDebtToIncome are declined; above highDebtToIncome
+	// they pay highDebtRateSpread on top of their tier's rate
+	maxDebtToIncome    float64
+	highDebtToIncome   float64
+	highDebtRateSpread float64
+
+	maxCreditLimit float64
+	declinedRate   float64
+}
+
+var defaultCreditPolicy = creditPolicy{
+	tiers: []creditTier{
+		{minScore: 750, interestRate: 3.5, incomeMultiple: 0.8, reason: CreditApprovalReasonExcellent},
+		{minScore: 700, interestRate: 5.9, incomeMultiple: 0.6, reason: CreditApprovalReasonGood},
+		{minScore: 650, interestRate: 8.9, incomeMultiple: 0.4, reason: CreditApprovalReasonFair},
+	},
+	maxDebtToIncome:    0.43,
+	highDebtToIncome:   0.36,
+	highDebtRateSpread: 1.0,
+	maxCreditLimit:     150000,
+	declinedRate:       15.0,
+}
+
+// decide applies the policy. The credit limit is a multiple of annual income
+// reduced by the share of income already going to debt, rounded down to the
+// nearest hundred.
+func (p creditPolicy) decide(score int, annualIncome, monthlyDebt float64) CreditDecision {
+	decision := CreditDecision{
+		CreditScore:    score,
+		InterestRate:   p.declinedRate,
+		ApprovalReason: CreditApprovalReasonLowScore,
+	}
+
+	if annualIncome <= 0 {
+		decision.ApprovalReason = CreditApprovalReasonNoIncome
+		return decision
+	}
+
+	decision.DebtToIncome = math.Round(monthlyDebt/(annualIncome/12)*1000) / 1000
+
+	var tier *creditTier
+	for i := range p.tiers {
+		if score >= p.tiers[i].minScore {
+			tier = &p.tiers[i]
+			break
+		}
+	}
+	if tier == nil {
+		return decision
+	}
+
+	if decision.DebtToIncome > p.maxDebtToIncome {
+		decision.ApprovalReason = CreditApprovalReasonHighDebt
+		return decision
+	}
+
+	limit := annualIncome * tier.incomeMultiple * (1 - decision.DebtToIncome)
+	limit = math.Min(math.Floor(limit/100)*100, p.maxCreditLimit)
+
+	rate := tier.interestRate
+	if decision.DebtToIncome > p.highDebtToIncome {
+		rate += p.highDebtRateSpread
+	}
+
+	decision.Approved = true
+	decision.CreditLimit = limit
+	decision.InterestRate = rate
+	decision.ApprovalReason = tier.reason
+	return decision
+}
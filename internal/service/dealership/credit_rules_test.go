@@ -0,0 +1,121 @@
+package dealership
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCreditPolicyDecide(t *testing.T) {
+	tests := []struct {
+		name         string
+		score        int
+		annualIncome float64
+		monthlyDebt  float64
+		wantApproved bool
+		wantReason   CreditApprovalReason
+		wantLimit    float64
+		wantRate     float64
+	}{
+		{
+			name: "no income", score: 800, annualIncome: 0,
+			wantReason: CreditApprovalReasonNoIncome, wantRate: 15,
+		},
+		{
+			name: "score below every tier", score: 600, annualIncome: 60000,
+			wantReason: CreditApprovalReasonLowScore, wantRate: 15,
+		},
+		{
+			name: "excellent", score: 760, annualIncome: 100000,
+			wantApproved: true, wantReason: CreditApprovalReasonExcellent, wantLimit: 80000, wantRate: 3.5,
+		},
+		{
+			name: "fair", score: 660, annualIncome: 50000,
+			wantApproved: true, wantReason: CreditApprovalReasonFair, wantLimit: 20000, wantRate: 8.9,
+		},
+		{
+			name: "high debt pays the spread and has a reduced limit", score: 720, annualIncome: 60000, monthlyDebt: 2000,
+			wantApproved: true, wantReason: CreditApprovalReasonGood, wantLimit: 21600, wantRate: 6.9,
+		},
+		{
+			name: "debt over the maximum ratio", score: 760, annualIncome: 60000, monthlyDebt: 2500,
+			wantReason: CreditApprovalReasonHighDebt, wantRate: 15,
+		},
+		{
+			name: "limit is capped", score: 800, annualIncome: 300000,
+			wantApproved: true, wantReason: CreditApprovalReasonExcellent, wantLimit: 150000, wantRate: 3.5,
+		},
+		{
+			name: "limit rounds down to the nearest hundred", score: 700, annualIncome: 45550,
+			wantApproved: true, wantReason: CreditApprovalReasonGood, wantLimit: 27300, wantRate: 5.9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := defaultCreditPolicy.decide(tt.score, tt.annualIncome, tt.monthlyDebt)
+
+			if decision.Approved != tt.wantApproved {
+				t.Errorf("approved = %v, want %v", decision.Approved, tt.wantApproved)
+			}
+			if decision.ApprovalReason != tt.wantReason {
+				t.Errorf("reason = %q, want %q", decision.ApprovalReason, tt.wantReason)
+			}
+			if decision.CreditLimit != tt.wantLimit {
+				t.Errorf("credit limit = %v, want %v", decision.CreditLimit, tt.wantLimit)
+			}
+			if decision.InterestRate != tt.wantRate {
+				t.Errorf("interest rate = %v, want %v", decision.InterestRate, tt.wantRate)
+			}
+			if decision.CreditScore != tt.score {
+				t.Errorf("credit score = %d, want %d", decision.CreditScore, tt.score)
+			}
+		})
+	}
+}
+
+// Each tier starts exactly at its minimum score; one point less drops the
+// applicant into the next tier down, or out of the policy after the last.
+func TestCreditTierBoundaries(t *testing.T) {
+	tiers := defaultCreditPolicy.tiers
+	for i, tier := range tiers {
+		atMin := defaultCreditPolicy.decide(tier.minScore, 100000, 0)
+		if !atMin.Approved || atMin.ApprovalReason != tier.reason || atMin.InterestRate != tier.interestRate {
+			t.Errorf("score %d: approved %v as %q at %v%%, want %q at %v%%",
+				tier.minScore, atMin.Approved, atMin.ApprovalReason, atMin.InterestRate, tier.reason, tier.interestRate)
+		}
+
+		below := defaultCreditPolicy.decide(tier.minScore-1, 100000, 0)
+		wantReason := CreditApprovalReasonLowScore
+		if i+1 < len(tiers) {
+			wantReason = tiers[i+1].reason
+		}
+		if below.ApprovalReason != wantReason {
+			t.Errorf("score %d: reason %q, want %q", tier.minScore-1, below.ApprovalReason, wantReason)
+		}
+	}
+}
+
+// With 10,000 a month in income the monthly debt reads directly as the ratio.
+func TestDebtToIncomeThresholds(t *testing.T) {
+	const annualIncome = 120000
+	good := defaultCreditPolicy.tiers[1]
+
+	for _, tt := range []struct {
+		monthlyDebt  float64
+		wantApproved bool
+		wantRate     float64
+	}{
+		{monthlyDebt: 3600, wantApproved: true, wantRate: good.interestRate},
+		{monthlyDebt: 3610, wantApproved: true, wantRate: good.interestRate + defaultCreditPolicy.highDebtRateSpread},
+		{monthlyDebt: 4300, wantApproved: true, wantRate: good.interestRate + defaultCreditPolicy.highDebtRateSpread},
+		{monthlyDebt: 4310, wantRate: defaultCreditPolicy.declinedRate},
+	} {
+		t.Run(fmt.Sprintf("debt %v", tt.monthlyDebt), func(t *testing.T) {
+			decision := defaultCreditPolicy.decide(good.minScore, annualIncome, tt.monthlyDebt)
+			if decision.Approved != tt.wantApproved || decision.InterestRate != tt.wantRate {
+				t.Errorf("approved %v at %v%% with a %v ratio, want approved %v at %v%%",
+					decision.Approved, decision.InterestRate, decision.DebtToIncome, tt.wantApproved, tt.wantRate)
+			}
+		})
+	}
+}
@@ -3,14 +3,19 @@ package dealership
 import (
 	"api-servers/internal/models/mysql"
 	"context"
+	"errors"
 	"fmt"
-	"hash/fnv"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 func (s *service) RegisterNewCustomer(ctx context.Context, application CustomerApplication) (*mysql.Customer, error) {
+	if application.AnnualIncome < 0 {
+		return nil, fmt.Errorf("annual_income cannot be negative")
+	}
+
 	customer := mysql.Customer{
 		ID:            uuid.New().String(),
 		First_Name:    application.FirstName,
@@ -23,11 +28,12 @@ func (s *service) RegisterNewCustomer(ctx context.Context, application CustomerA
 		Zip_Code:      application.ZipCode,
 		Date_Of_Birth: &application.DateOfBirth,
 		Credit_Score:  0,
+		Annual_Income: application.AnnualIncome,
 		Created_At:    time.Now(),
 		Updated_At:    time.Now(),
 	}
 
-	err := s.customer_repo.Create(customer)
+	err := s.customer_repo.Create(ctx, customer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to register customer %s %s: %w", application.FirstName, application.LastName, err)
 	}
@@ -36,103 +42,324 @@ func (s *service) RegisterNewCustomer(ctx context.Context, application CustomerA
 }
 
 func (s *service) ProcessCreditApplication(ctx context.Context, customerID string) (*CreditDecision, error) {
-	customer, err := s.customer_repo.GetByID(customerID)
+	customer, err := s.getCustomer(ctx, customerID)
 	if err != nil {
 		return nil, fmt.Errorf("customer %s not found for credit application: %w", customerID, err)
 	}
 
-	creditScore := s.calculateCreditScore(customer)
-	approved := creditScore >= 650
-	creditLimit := float64(0)
-	interestRate := 15.0
-	approvalReason := CreditApprovalReasonLowScore
-
-	if approved {
-		creditLimit = float64(creditScore)
-		if creditScore >= 750 {
-			interestRate = 3.5
-			approvalReason = CreditApprovalReasonExcellent
-		} else if creditScore >= 700 {
-			interestRate = 5.9
-			approvalReason = CreditApprovalReasonGood
-		} else {
-			interestRate = 8.9
-			approvalReason = CreditApprovalReasonFair
-		}
+	report, err := s.credit_bureau.GetCreditReport(ctx, CreditInquiry{
+		CustomerID:  customer.ID,
+		FirstName:   customer.First_Name,
+		LastName:    customer.Last_Name,
+		Email:       customer.Email,
+		DateOfBirth: customer.Date_Of_Birth,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull credit report for customer %s: %w", customerID, err)
 	}
 
-	return &CreditDecision{
-		CustomerID:     customerID,
-		Approved:       approved,
-		CreditLimit:    creditLimit,
-		InterestRate:   interestRate,
-		ApprovalReason: approvalReason,
-		CreditScore:    creditScore,
-	}, nil
+	decision := defaultCreditPolicy.decide(report.Score, customer.Annual_Income, report.MonthlyDebt)
+
+	now := time.Now()
+	application := mysql.CreditApplication{
+		ID:             uuid.New().String(),
+		Customer_ID:    customerID,
+		Bureau:         report.Bureau,
+		Annual_Income:  customer.Annual_Income,
+		Monthly_Debt:   report.MonthlyDebt,
+		Credit_Score:   decision.CreditScore,
+		Debt_To_Income: decision.DebtToIncome,
+		Approved:       decision.Approved,
+		Credit_Limit:   decision.CreditLimit,
+		Interest_Rate:  decision.InterestRate,
+		Reason:         string(decision.ApprovalReason),
+		Created_At:     now,
+		Expires_At:     now.Add(s.credit_ttl),
+	}
+
+	err = s.credit_repo.Create(ctx, application)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record credit decision for customer %s: %w", customerID, err)
+	}
+
+	return creditDecisionFrom(application), nil
+}
+
+func (s *service) GetCreditApplications(ctx context.Context, customerID string) ([]mysql.CreditApplication, error) {
+	if _, err := s.getCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	applications, err := s.credit_repo.GetByCustomerId(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credit applications for customer %s: %w", customerID, err)
+	}
+	return applications, nil
+}
+
+func (s *service) GetCustomer(ctx context.Context, customerID string) (*mysql.Customer, error) {
+	customer, err := s.getCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
 }
 
 func (s *service) GetCustomerProfile(ctx context.Context, customerID string) (*CustomerProfile, error) {
-	customer, err := s.customer_repo.GetByID(customerID)
+	customer, err := s.getCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	purchaseHistory, err := s.sales_repo.GetByCustomerId(ctx, customerID)
 	if err != nil {
-		return nil, fmt.Errorf("customer %s not found for profile: %w", customerID, err)
+		return nil, fmt.Errorf("failed to get purchase history for customer %s: %w", customerID, err)
 	}
 
-	var purchaseHistory []mysql.Sale
-	var ownedVehicles []mysql.Vehicle
-	var preferredBrands []string
+	var vehicleIDs []string
+	for _, sale := range purchaseHistory {
+		if sale.Status == mysql.SaleStatusCompleted {
+			vehicleIDs = append(vehicleIDs, sale.Vehicle_ID)
+		}
+	}
+
+	vehicles, err := s.vehicle_repo.GetByIDs(ctx, vehicleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vehicles owned by customer %s: %w", customerID, err)
+	}
+	vehiclesByID := make(map[string]mysql.Vehicle, len(vehicles))
+	for _, vehicle := range vehicles {
+		vehiclesByID[vehicle.ID] = vehicle
+	}
+
+	// only completed sales count towards what the customer owns and spent
+	ownedVehicles := []mysql.Vehicle{}
+	brandPurchases := make(map[string]int)
+	brandSpend := make(map[string]float64)
 	totalSpent := float64(0)
 
-	creditDecision, err := s.ProcessCreditApplication(ctx, customerID)
+	for _, sale := range purchaseHistory {
+		if sale.Status != mysql.SaleStatusCompleted {
+			continue
+		}
+		totalSpent += sale.Sale_Price
+
+		vehicle, ok := vehiclesByID[sale.Vehicle_ID]
+		if !ok {
+			continue
+		}
+		ownedVehicles = append(ownedVehicles, vehicle)
+		brandPurchases[vehicle.Make]++
+		brandSpend[vehicle.Make] += sale.Sale_Price
+	}
+
+	// the profile only reads the latest decision; pulling credit is left to
+	// credit applications, quotes and sales
+	latest, err := s.latestCreditApplication(ctx, customerID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get credit status for customer %s: %w", customerID, err)
 	}
+	var creditStatus *CreditDecision
+	creditCurrent := false
+	if latest != nil {
+		creditStatus = creditDecisionFrom(*latest)
+		creditCurrent = creditApplicationCurrent(*latest, customer, time.Now())
+	}
+
+	if purchaseHistory == nil {
+		purchaseHistory = []mysql.Sale{}
+	}
 
 	return &CustomerProfile{
-		Customer:        customer,
-		PurchaseHistory: purchaseHistory,
-		OwnedVehicles:   ownedVehicles,
-		CreditStatus:    *creditDecision,
-		TotalSpent:      totalSpent,
-		PreferredBrands: preferredBrands,
+		Customer:            customer,
+		PurchaseHistory:     purchaseHistory,
+		OwnedVehicles:       ownedVehicles,
+		CreditStatus:        creditStatus,
+		CreditStatusCurrent: creditCurrent,
+		TotalSpent:          totalSpent,
+		PreferredBrands:     topBrands(brandPurchases, brandSpend, maxPreferredBrands),
 	}, nil
 }
 
-func (s *service) GetAllCustomers(ctx context.Context) ([]mysql.Customer, error) {
-	customers, err := s.customer_repo.GetAll()
+func (s *service) GetAllCustomers(ctx context.Context, filter mysql.CustomerFilter, opts mysql.ListOptions) ([]mysql.Customer, int, error) {
+	customers, err := s.customer_repo.List(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve customers: %w", err)
+	}
+
+	total, err := s.customer_repo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count customers: %w", err)
+	}
+	return customers, total, nil
+}
+
+func (s *service) UpdateCustomer(ctx context.Context, customerID string, application CustomerApplication) (*mysql.Customer, error) {
+	customer, err := s.getCustomer(ctx, customerID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve customers: %w", err)
+		return nil, err
 	}
-	return customers, nil
+
+	return s.saveCustomer(ctx, customer, application)
+}
+
+func (s *service) PatchCustomer(ctx context.Context, customerID string, patch []byte) (*mysql.Customer, error) {
+	customer, err := s.getCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var application CustomerApplication
+	err = applyMergePatch(customerApplicationFrom(customer), patch, &application)
+	if err != nil {
+		return nil, fmt.Errorf("failed to patch customer %s: %w", customerID, err)
+	}
+
+	return s.saveCustomer(ctx, customer, application)
+}
+
+func (s *service) DeleteCustomer(ctx context.Context, customerID string) error {
+	err := s.customer_repo.Delete(ctx, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to delete customer %s: %w", customerID, err)
+	}
+	return nil
 }
 
 // customer helper functions
 
-func (s *service) calculateCreditScore(customer mysql.Customer) int {
-	score := 300
-	if customer.Credit_Score > 0 {
-		return customer.Credit_Score
+// getCustomer loads a customer, treating soft-deleted customers as not found
+func (s *service) getCustomer(ctx context.Context, customerID string) (mysql.Customer, error) {
+	customer, err := s.customer_repo.GetByID(ctx, customerID)
+	if err != nil {
+		return customer, fmt.Errorf("customer %s not found: %w", customerID, err)
+	}
+	if customer.Deleted_At != nil {
+		return customer, fmt.Errorf("customer %s has been deleted: %w", customerID, ErrNotFound)
 	}
+	return customer, nil
+}
 
-	if customer.Date_Of_Birth != nil {
-		age := time.Now().Year() - customer.Date_Of_Birth.Year()
-		if age >= 25 {
-			score += 100
-		}
-		if age >= 35 {
-			score += 50
-		}
+func (s *service) saveCustomer(ctx context.Context, customer mysql.Customer, application CustomerApplication) (*mysql.Customer, error) {
+	if application.FirstName == "" || application.LastName == "" || application.Email == "" {
+		return nil, fmt.Errorf("first_name, last_name and email are required")
+	}
+	if application.AnnualIncome < 0 {
+		return nil, fmt.Errorf("annual_income cannot be negative")
+	}
+
+	customer.First_Name = application.FirstName
+	customer.Last_Name = application.LastName
+	customer.Email = application.Email
+	customer.Phone = application.Phone
+	customer.Address = application.Address
+	customer.City = application.City
+	customer.State = application.State
+	customer.Zip_Code = application.ZipCode
+	customer.Annual_Income = application.AnnualIncome
+	customer.Date_Of_Birth = nil
+	if !application.DateOfBirth.IsZero() {
+		customer.Date_Of_Birth = &application.DateOfBirth
+	}
+	customer.Updated_At = time.Now()
+
+	err := s.customer_repo.Update(ctx, customer.ID, customer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update customer %s: %w", customer.ID, err)
+	}
+
+	return &customer, nil
+}
+
+// currentCreditDecision returns the customer's latest stored decision while
+// it is still current and runs a new application otherwise
+func (s *service) currentCreditDecision(ctx context.Context, customer mysql.Customer) (*CreditDecision, error) {
+	latest, err := s.latestCreditApplication(ctx, customer.ID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && creditApplicationCurrent(*latest, customer, time.Now()) {
+		return creditDecisionFrom(*latest), nil
 	}
 
-	score += hashString(customer.ID)
-	if score > 850 {
-		return 850
+	return s.ProcessCreditApplication(ctx, customer.ID)
+}
+
+// latestCreditApplication reads the customer's most recent decision without
+// pulling credit. It returns nil if they have never applied.
+func (s *service) latestCreditApplication(ctx context.Context, customerID string) (*mysql.CreditApplication, error) {
+	latest, err := s.credit_repo.GetLatestByCustomerId(ctx, customerID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
 	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credit decision for customer %s: %w", customerID, err)
+	}
+	return &latest, nil
+}
+
+// creditApplicationCurrent reports whether a decision can still be relied
+// on: it hasn't expired and was made on the income the customer has now.
+// Changing a customer's annual income invalidates their decision.
+func creditApplicationCurrent(application mysql.CreditApplication, customer mysql.Customer, now time.Time) bool {
+	return now.Before(application.Expires_At) && application.Annual_Income == customer.Annual_Income
+}
 
-	return score
+func creditDecisionFrom(application mysql.CreditApplication) *CreditDecision {
+	return &CreditDecision{
+		ApplicationID:  application.ID,
+		CustomerID:     application.Customer_ID,
+		Approved:       application.Approved,
+		CreditLimit:    application.Credit_Limit,
+		InterestRate:   application.Interest_Rate,
+		ApprovalReason: CreditApprovalReason(application.Reason),
+		CreditScore:    application.Credit_Score,
+		DebtToIncome:   application.Debt_To_Income,
+		DecidedAt:      application.Created_At,
+		ExpiresAt:      application.Expires_At,
+	}
 }
 
-func hashString(score string) int {
-	h := fnv.New32a()
-	h.Write([]byte(score))
-	return int(h.Sum32() % 200)
+const maxPreferredBrands = 3
+
+// topBrands ranks makes by number of purchases, then by amount spent
+func topBrands(purchases map[string]int, spend map[string]float64, limit int) []string {
+	brands := make([]string, 0, len(purchases))
+	for brand := range purchases {
+		brands = append(brands, brand)
+	}
+
+	sort.Slice(brands, func(i, j int) bool {
+		a, b := brands[i], brands[j]
+		if purchases[a] != purchases[b] {
+			return purchases[a] > purchases[b]
+		}
+		if spend[a] != spend[b] {
+			return spend[a] > spend[b]
+		}
+		return a < b
+	})
+
+	if len(brands) > limit {
+		brands = brands[:limit]
+	}
+	return brands
+}
+
+func customerApplicationFrom(customer mysql.Customer) CustomerApplication {
+	application := CustomerApplication{
+		FirstName:    customer.First_Name,
+		LastName:     customer.Last_Name,
+		Email:        customer.Email,
+		Phone:        customer.Phone,
+		Address:      customer.Address,
+		City:         customer.City,
+		State:        customer.State,
+		ZipCode:      customer.Zip_Code,
+		AnnualIncome: customer.Annual_Income,
+	}
+	if customer.Date_Of_Birth != nil {
+		application.DateOfBirth = *customer.Date_Of_Birth
+	}
+	return application
 }
@@ -0,0 +1,54 @@
+package dealership
+
+import (
+	mysqlrepo "api-servers/internal/repository/mysql"
+	"errors"
+	"fmt"
+)
+
+// ConflictError reports that a resource was changed by another request
+// between being read and being written
+type ConflictError struct {
+	Resource string
+	ID       string
+	Err      error
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s %s was changed by another request: %v", e.Resource, e.ID, e.Err)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// ErrNotFound is wrapped by service errors for customers and vehicles that do
+// not exist or have been deleted
+var ErrNotFound = mysqlrepo.ErrNotFound
+
+// ErrDuplicate is wrapped by service errors for customers and vehicles whose
+// email or VIN is already in use
+var ErrDuplicate = mysqlrepo.ErrDuplicate
+
+// ErrInvalidQuery is wrapped by list and report methods given unsupported
+// sort, filter or period options
+var ErrInvalidQuery = mysqlrepo.ErrInvalidQuery
+
+// ErrInvalidInput is wrapped by service errors for requests with values the
+// dealership can't accept, such as a term it doesn't offer or a discount
+// larger than the price
+var ErrInvalidInput = errors.New("invalid input")
+
+// ErrInvalidState is wrapped by service errors for changes a resource's
+// current state doesn't allow, such as selling from a session that has
+// already been completed
+var ErrInvalidState = errors.New("not allowed in its current state")
+
+// ErrForbidden is wrapped by service errors for callers who may not make a
+// change, such as a salesperson who isn't a manager reviewing a sale
+var ErrForbidden = errors.New("not permitted")
+
+// ErrContractAltered is returned when a stored contract no longer matches the
+// content hash recorded when it was generated, or its hashes no longer match
+// the server's signature
+var ErrContractAltered = errors.New("contract does not match its signed content hash")
@@ -0,0 +1,143 @@
+package dealership
+
+import (
+	"api-servers/internal/models/mysql"
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Fees are the sales tax and flat fees charged on a vehicle registered in
+// one state
+type Fees struct {
+	// share of the taxable price, e.g. 0.0625 for 6.25%
+	SalesTaxRate float64
+
+	DocFee          float64
+	RegistrationFee float64
+	TitleFee        float64
+}
+
+// FeeTable holds the taxes and fees the dealership charges, keyed by the
+// customer's state. Where city or county taxes apply, ZipTaxRates replaces
+// the state's sales tax rate for a zip code in that state; it is keyed by
+// state and then zip code, so a zip code given with the wrong state doesn't
+// pick up another state's rate. Customers in states the table doesn't list
+// are charged Default.
+type FeeTable struct {
+	States      map[string]Fees
+	ZipTaxRates map[string]map[string]float64
+	Default     Fees
+}
+
+var DefaultFeeTable = FeeTable{
+	States: map[string]Fees{
+		"CA": {SalesTaxRate: 0.0725, DocFee: 85, RegistrationFee: 65, TitleFee: 23},
+		"FL": {SalesTaxRate: 0.06, DocFee: 799, RegistrationFee: 225, TitleFee: 75.75},
+		"IL": {SalesTaxRate: 0.0625, DocFee: 358.03, RegistrationFee: 151, TitleFee: 165},
+		"NY": {SalesTaxRate: 0.04, DocFee: 175, RegistrationFee: 50, TitleFee: 50},
+		"TX": {SalesTaxRate: 0.0625, DocFee: 150, RegistrationFee: 51.75, TitleFee: 33},
+	},
+	ZipTaxRates: map[string]map[string]float64{
+		"CA": {
+			"90001": 0.095, // Los Angeles
+		},
+		"IL": {
+			"60601": 0.1025, // Chicago
+		},
+		"NY": {
+			"10001": 0.08875, // New York City
+		},
+		"TX": {
+			"73301": 0.0825, // Austin
+			"75201": 0.0825, // Dallas
+			"77001": 0.0825, // Houston
+		},
+	},
+	Default: Fees{SalesTaxRate: 0.06, DocFee: 200, RegistrationFee: 75, TitleFee: 50},
+}
+
+// lookup returns the fees for a customer's address and the jurisdiction
+// they came from: a state, a state and zip code, or "default"
+func (t FeeTable) lookup(state, zipCode string) (Fees, string) {
+	state = strings.ToUpper(strings.TrimSpace(state))
+	zipCode = strings.TrimSpace(zipCode)
+	if len(zipCode) > 5 {
+		zipCode = zipCode[:5]
+	}
+
+	fees, ok := t.States[state]
+	if !ok {
+		return t.Default, "default"
+	}
+	if rate, ok := t.ZipTaxRates[state][zipCode]; ok {
+		fees.SalesTaxRate = rate
+		return fees, state + " " + zipCode
+	}
+	return fees, state
+}
+
+// QuoteOutTheDoor breaks down what a customer would pay in total for a
+// vehicle: the sale price plus the taxes and fees for where they live
+func (s *service) QuoteOutTheDoor(ctx context.Context, request OutTheDoorRequest) (*OutTheDoorPrice, error) {
+	customer, err := s.getCustomer(ctx, request.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	vehicle, err := s.getVehicle(ctx, request.VehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateDiscount(vehicle.Price, request.Discount); err != nil {
+		return nil, err
+	}
+
+	tradeIn, err := s.tradeInCredit(ctx, request.TradeInID, customer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	pricing := s.priceOutTheDoor(customer, vehicle, request.Discount, tradeInOffer(tradeIn))
+	return &pricing, nil
+}
+
+// fee helper functions
+
+// priceOutTheDoor adds the customer's taxes and fees to the discounted price.
+// Sales tax is charged on the price less any trade-in credit.
+func (s *service) priceOutTheDoor(customer mysql.Customer, vehicle mysql.Vehicle, discount, tradeInCredit float64) OutTheDoorPrice {
+	fees, jurisdiction := s.fee_table.lookup(customer.State, customer.Zip_Code)
+
+	salePrice := roundCents(vehicle.Price - discount)
+	taxable := math.Max(salePrice-tradeInCredit, 0)
+	salesTax := roundCents(taxable * fees.SalesTaxRate)
+	total := roundCents(salesTax + fees.DocFee + fees.RegistrationFee + fees.TitleFee)
+
+	return OutTheDoorPrice{
+		CustomerID:        customer.ID,
+		VehicleID:         vehicle.ID,
+		TaxJurisdiction:   jurisdiction,
+		VehiclePrice:      vehicle.Price,
+		Discount:          discount,
+		SalePrice:         salePrice,
+		TradeInCredit:     tradeInCredit,
+		TaxableAmount:     roundCents(taxable),
+		SalesTaxRate:      fees.SalesTaxRate,
+		SalesTax:          salesTax,
+		DocFee:            fees.DocFee,
+		RegistrationFee:   fees.RegistrationFee,
+		TitleFee:          fees.TitleFee,
+		TotalTaxesAndFees: total,
+		OutTheDoorPrice:   roundCents(salePrice + total),
+	}
+}
+
+func validateDiscount(listPrice, discount float64) error {
+	if discount < 0 || discount >= listPrice {
+		return fmt.Errorf("discount must be between 0 and the list price of %.2f: %w", listPrice, ErrInvalidInput)
+	}
+	return nil
+}
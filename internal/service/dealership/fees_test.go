@@ -0,0 +1,111 @@
+package dealership
+
+import (
+	"api-servers/internal/models/mysql"
+	"errors"
+	"testing"
+)
+
+func TestFeeTableLookup(t *testing.T) {
+	tests := []struct {
+		name             string
+		state            string
+		zipCode          string
+		wantRate         float64
+		wantDocFee       float64
+		wantJurisdiction string
+	}{
+		{name: "state rate", state: "TX", zipCode: "78701", wantRate: 0.0625, wantDocFee: 150, wantJurisdiction: "TX"},
+		{name: "zip code rate", state: "TX", zipCode: "77001", wantRate: 0.0825, wantDocFee: 150, wantJurisdiction: "TX 77001"},
+		{name: "state is normalized", state: " tx ", zipCode: "77001", wantRate: 0.0825, wantDocFee: 150, wantJurisdiction: "TX 77001"},
+		{name: "zip+4 is cut to five digits", state: "IL", zipCode: "60601-1234", wantRate: 0.1025, wantDocFee: 358.03, wantJurisdiction: "IL 60601"},
+		{name: "zip code from another state is ignored", state: "CA", zipCode: "77001", wantRate: 0.0725, wantDocFee: 85, wantJurisdiction: "CA"},
+		{name: "unlisted state", state: "WA", zipCode: "98101", wantRate: 0.06, wantDocFee: 200, wantJurisdiction: "default"},
+		{name: "no state", state: "", zipCode: "77001", wantRate: 0.06, wantDocFee: 200, wantJurisdiction: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fees, jurisdiction := DefaultFeeTable.lookup(tt.state, tt.zipCode)
+
+			if fees.SalesTaxRate != tt.wantRate {
+				t.Errorf("sales tax rate = %v, want %v", fees.SalesTaxRate, tt.wantRate)
+			}
+			if fees.DocFee != tt.wantDocFee {
+				t.Errorf("doc fee = %v, want %v", fees.DocFee, tt.wantDocFee)
+			}
+			if jurisdiction != tt.wantJurisdiction {
+				t.Errorf("jurisdiction = %q, want %q", jurisdiction, tt.wantJurisdiction)
+			}
+		})
+	}
+}
+
+func TestPriceOutTheDoor(t *testing.T) {
+	s := &service{fee_table: DefaultFeeTable}
+
+	tests := []struct {
+		name          string
+		customer      mysql.Customer
+		price         float64
+		discount      float64
+		tradeInCredit float64
+		wantTaxable   float64
+		wantSalesTax  float64
+		wantTotalFees float64
+	}{
+		{
+			name:     "discount and trade-in reduce the taxable amount",
+			customer: mysql.Customer{State: "TX", Zip_Code: "77001"},
+			price:    30000, discount: 1000, tradeInCredit: 5000,
+			wantTaxable: 24000, wantSalesTax: 1980, wantTotalFees: 2214.75,
+		},
+		{
+			name:     "trade-in worth more than the car",
+			customer: mysql.Customer{State: "TX", Zip_Code: "78701"},
+			price:    10000, tradeInCredit: 12000,
+			wantTaxable: 0, wantSalesTax: 0, wantTotalFees: 234.75,
+		},
+		{
+			name:        "default fees",
+			customer:    mysql.Customer{State: "WA", Zip_Code: "98101"},
+			price:       20000,
+			wantTaxable: 20000, wantSalesTax: 1200, wantTotalFees: 1525,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pricing := s.priceOutTheDoor(tt.customer, mysql.Vehicle{Price: tt.price}, tt.discount, tt.tradeInCredit)
+
+			if pricing.TaxableAmount != tt.wantTaxable || pricing.SalesTax != tt.wantSalesTax {
+				t.Errorf("sales tax = %v on %v, want %v on %v", pricing.SalesTax, pricing.TaxableAmount, tt.wantSalesTax, tt.wantTaxable)
+			}
+			if pricing.TotalTaxesAndFees != tt.wantTotalFees {
+				t.Errorf("total taxes and fees = %v, want %v", pricing.TotalTaxesAndFees, tt.wantTotalFees)
+			}
+
+			// the trade-in only lowers the tax; it is credited against the
+			// out the door price later, as part of the down payment
+			if pricing.SalePrice != tt.price-tt.discount {
+				t.Errorf("sale price = %v, want %v", pricing.SalePrice, tt.price-tt.discount)
+			}
+			if want := roundCents(pricing.SalePrice + pricing.TotalTaxesAndFees); pricing.OutTheDoorPrice != want {
+				t.Errorf("out the door price = %v, want sale price plus taxes and fees %v", pricing.OutTheDoorPrice, want)
+			}
+		})
+	}
+}
+
+func TestValidateDiscount(t *testing.T) {
+	for _, discount := range []float64{0, 500, 19999.99} {
+		if err := validateDiscount(20000, discount); err != nil {
+			t.Errorf("validateDiscount(20000, %v) = %v, want nil", discount, err)
+		}
+	}
+	for _, discount := range []float64{-1, 20000, 25000} {
+		if err := validateDiscount(20000, discount); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("validateDiscount(20000, %v) = %v, want ErrInvalidInput", discount, err)
+		}
+	}
+}
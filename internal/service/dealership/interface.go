@@ -22,6 +22,8 @@ const (
 	CreditApprovalReasonExcellent CreditApprovalReason = "excellent credit score"
 	CreditApprovalReasonGood      CreditApprovalReason = "good credit score"
 	CreditApprovalReasonFair      CreditApprovalReason = "fair credit score"
+	CreditApprovalReasonHighDebt  CreditApprovalReason = "debt-to-income ratio too high"
+	CreditApprovalReasonNoIncome  CreditApprovalReason = "annual income required"
 )
 
 type FinancingTerm int
@@ -46,25 +48,62 @@ type DealershipService interface {
 	// customer
 	RegisterNewCustomer(ctx context.Context, application CustomerApplication) (*mysql.Customer, error)
 	ProcessCreditApplication(ctx context.Context, customerID string) (*CreditDecision, error)
+	GetCreditApplications(ctx context.Context, customerID string) ([]mysql.CreditApplication, error)
+	GetCustomer(ctx context.Context, customerID string) (*mysql.Customer, error)
 	GetCustomerProfile(ctx context.Context, customerID string) (*CustomerProfile, error)
-	GetAllCustomers(ctx context.Context) ([]mysql.Customer, error)
+	GetAllCustomers(ctx context.Context, filter mysql.CustomerFilter, opts mysql.ListOptions) ([]mysql.Customer, int, error)
+	UpdateCustomer(ctx context.Context, customerID string, application CustomerApplication) (*mysql.Customer, error)
+	PatchCustomer(ctx context.Context, customerID string, patch []byte) (*mysql.Customer, error)
+	DeleteCustomer(ctx context.Context, customerID string) error
 
 	// vehicle
 	AddVehicleToInventory(ctx context.Context, vehicle VehicleInput) (*mysql.Vehicle, error)
-	GetAllVehicles(ctx context.Context) ([]mysql.Vehicle, error)
+	GetAllVehicles(ctx context.Context, filter mysql.VehicleFilter, opts mysql.ListOptions) ([]mysql.Vehicle, int, error)
 	GetVehicleByID(ctx context.Context, vehicleID string) (*mysql.Vehicle, error)
-	FindVehiclesForCustomers(ctx context.Context, customerID string, preferences VehiclePreferences) ([]mysql.Vehicle, error)
-	ReserveVehicle(ctx context.Context, vehicleID, customerID string) error
+	UpdateVehicle(ctx context.Context, vehicleID string, vehicle VehicleInput) (*mysql.Vehicle, error)
+	PatchVehicle(ctx context.Context, vehicleID string, patch []byte, version *int) (*mysql.Vehicle, error)
+	DeleteVehicle(ctx context.Context, vehicleID string) error
+	FindVehiclesForCustomers(ctx context.Context, customerID string, preferences VehiclePreferences, opts mysql.ListOptions) ([]mysql.Vehicle, int, error)
+	ReserveVehicle(ctx context.Context, vehicleID, customerID string) (*mysql.Reservation, error)
+	ReleaseVehicleReservation(ctx context.Context, vehicleID string) (*mysql.Reservation, error)
+	GetCustomerReservations(ctx context.Context, customerID string) ([]mysql.Reservation, error)
+	ReleaseExpiredReservations(ctx context.Context) (int, error)
+
+	// salespeople
+	HireSalesperson(ctx context.Context, input SalespersonInput) (*mysql.Salesperson, error)
+	GetSalesperson(ctx context.Context, salespersonID string) (*mysql.Salesperson, error)
+	GetSalespeople(ctx context.Context, filter mysql.SalespersonFilter, opts mysql.ListOptions) ([]mysql.Salesperson, int, error)
+	UpdateSalesperson(ctx context.Context, salespersonID string, input SalespersonInput) (*mysql.Salesperson, error)
+	DeactivateSalesperson(ctx context.Context, salespersonID string) (*mysql.Salesperson, error)
 
 	// sales
 	StartSalesProcess(ctx context.Context, customerID, vehicleID, salespersonID string) (*SalesSession, error)
-	CalculateFinancingOperations(ctx context.Context, vehicleID string, downPayment float64, customerID string) (FinancingOptions, error)
+	QuoteOutTheDoor(ctx context.Context, request OutTheDoorRequest) (*OutTheDoorPrice, error)
+	QuoteLease(ctx context.Context, request LeaseQuoteRequest) (*LeaseQuote, error)
+	CalculateFinancingOperations(ctx context.Context, vehicleID string, downPayment float64, customerID, tradeInID string) (FinancingOptions, error)
+	QuoteAmortization(ctx context.Context, request AmortizationQuoteRequest) (*AmortizationSchedule, error)
+	GetSaleAmortization(ctx context.Context, saleID string, extra ExtraPayments) (*AmortizationSchedule, error)
 	ProcessVehicleSale(ctx context.Context, saleRequest SaleRequest) (*SaleResult, error)
+	CancelSale(ctx context.Context, saleID, reason string) (*SaleCancellation, error)
+	GetPendingSales(ctx context.Context) ([]mysql.Sale, error)
+	ApproveSale(ctx context.Context, saleID string, review SaleReviewRequest) (*SaleReview, error)
+	RejectSale(ctx context.Context, saleID string, review SaleReviewRequest) (*SaleReview, error)
+	GetSaleApprovals(ctx context.Context, saleID string) ([]mysql.SaleApproval, error)
+	GetSaleContract(ctx context.Context, saleID string) (*mysql.SaleContract, error)
+	GetSalesSession(ctx context.Context, sessionID string) (*SalesSession, error)
+	CancelSalesSession(ctx context.Context, sessionID string) (*SalesSession, error)
+	GetActiveSalesSessions(ctx context.Context, salespersonID string) ([]SalesSession, error)
+
+	// trade-ins
+	AppraiseTradeIn(ctx context.Context, request TradeInRequest) (*TradeInAppraisal, error)
+	GetTradeIn(ctx context.Context, tradeInID string) (*mysql.TradeIn, error)
+	GetCustomerTradeIns(ctx context.Context, customerID string) ([]mysql.TradeIn, error)
 
 	// reporting
 	GenerateSalesReport(ctx context.Context, period ReportPeriod) (*SalesReport, error)
 	GetTopPerformers(ctx context.Context, period ReportPeriod) (*PerformanceReport, error)
-	GetInventoryReport(ctx context.Context) (*InventoryReport, error)
+	GetSalesTimeSeries(ctx context.Context, period ReportPeriod, interval mysql.TimeBucket, groupBy mysql.SalesGrouping) (*SalesTimeSeries, error)
+	GetInventoryReport(ctx context.Context, options InventoryReportOptions) (*InventoryReport, error)
 }
 
 type CustomerApplication struct {
@@ -81,21 +120,30 @@ type CustomerApplication struct {
 }
 
 type CreditDecision struct {
+	ApplicationID  string               `json:"application_id"`
 	CustomerID     string               `json:"customer_id"`
 	Approved       bool                 `json:"approved"`
 	CreditLimit    float64              `json:"credit_limit"`
 	InterestRate   float64              `json:"interest_rate"`
 	ApprovalReason CreditApprovalReason `json:"approval_reason"`
 	CreditScore    int                  `json:"credit_score"`
+	DebtToIncome   float64              `json:"debt_to_income"`
+	DecidedAt      time.Time            `json:"decided_at"`
+	ExpiresAt      time.Time            `json:"expires_at"`
 }
 
 type CustomerProfile struct {
 	Customer        mysql.Customer  `json:"customer"`
 	PurchaseHistory []mysql.Sale    `json:"purchase_history"`
 	OwnedVehicles   []mysql.Vehicle `json:"owned_vehicles"`
-	CreditStatus    CreditDecision  `json:"credit_status"`
-	TotalSpent      float64         `json:"total_spent"`
-	PreferredBrands []string        `json:"preferred_brands"`
+
+	// latest credit decision, null if the customer has never applied.
+	// CreditStatusCurrent is false once it has expired or their income has
+	// changed, and the next quote or sale runs a new application.
+	CreditStatus        *CreditDecision `json:"credit_status"`
+	CreditStatusCurrent bool            `json:"credit_status_current"`
+	TotalSpent          float64         `json:"total_spent"`
+	PreferredBrands     []string        `json:"preferred_brands"`
 }
 
 type VehicleInput struct {
@@ -109,6 +157,10 @@ type VehicleInput struct {
 	EngineType   string          `json:"engine_type"`
 	Transmission string          `json:"transmission"`
 	FuelType     mysql.FuelType  `json:"fuel_type"`
+
+	// version the client last read; when set the update is refused if the
+	// vehicle has changed since. Versions start at 0, so nil means unset.
+	Version *int `json:"version,omitempty"`
 }
 
 type VehiclePreferences struct {
@@ -121,12 +173,26 @@ type VehiclePreferences struct {
 	FuelTypes  []mysql.FuelType `json:"fuel_types"`
 }
 
+type SalespersonInput struct {
+	EmployeeID string                  `json:"employee_id"`
+	FirstName  string                  `json:"first_name"`
+	LastName   string                  `json:"last_name"`
+	Email      string                  `json:"email"`
+	Phone      string                  `json:"phone"`
+	HireDate   time.Time               `json:"hire_date"`
+	Commission float64                 `json:"commission"`
+	Department string                  `json:"department"`
+	Role       mysql.SalesPersonRole   `json:"role"`
+	Status     mysql.SalesPersonStatus `json:"status"`
+}
+
 type SalesSession struct {
 	SessionID   string             `json:"session_id"`
 	Customer    mysql.Customer     `json:"customer"`
 	Vehicle     mysql.Vehicle      `json:"vehicle"`
 	Salesperson mysql.Salesperson  `json:"salesperson"`
 	StartedAt   time.Time          `json:"started_at"`
+	ExpiresAt   time.Time          `json:"expires_at"`
 	Status      SalesSessionStatus `json:"status"`
 }
 
@@ -134,22 +200,140 @@ type SaleRequest struct {
 	SessionID      string              `json:"session_id"`
 	PaymentMethod  mysql.PaymentMethod `json:"payment_method"`
 	DownPayment    float64             `json:"down_payment"`
-	TradeInVehicle *string             `json:"trade_in_vehicle"`
+	Discount       float64             `json:"discount"`
+	TradeInID      string              `json:"trade_in_id"`
 	FinancingTerm  int                 `json:"financing_term"`
+	AnnualMileage  int                 `json:"annual_mileage"`
 	Notes          string              `json:"notes"`
 }
 
+// SaleResult is a recorded sale. Contract is only generated once the sale
+// completes, so it is nil for sales waiting on approval.
 type SaleResult struct {
-	Sale             mysql.Sale       `json:"sale"`
-	Contract         SalesContract    `json:"contract"`
-	FinancingDetails FinancingDetails `json:"financing_details"`
-	Commission       float64          `json:"commission"`
+	Sale             mysql.Sale          `json:"sale"`
+	Contract         *mysql.SaleContract `json:"contract,omitempty"`
+	FinancingDetails FinancingDetails    `json:"financing_details"`
+	LeaseDetails     *LeaseQuote         `json:"lease_details,omitempty"`
+	TradeInVehicle   *mysql.Vehicle      `json:"trade_in_vehicle,omitempty"`
+	Commission       float64             `json:"commission"`
+}
+
+// SaleCancellation is a cancelled sale and what was unwound. RefundAmount is
+// the cash the customer paid; a trade-in is returned rather than refunded.
+type SaleCancellation struct {
+	Sale               mysql.Sale     `json:"sale"`
+	RefundAmount       float64        `json:"refund_amount"`
+	CommissionReversed float64        `json:"commission_reversed"`
+	ReturnedTradeIn    *mysql.TradeIn `json:"returned_trade_in,omitempty"`
+}
+
+// SaleReviewRequest is a manager's approval or rejection. The API has no
+// authentication, so ManagerID is taken on trust: anyone who knows an active
+// manager's ID can decide as them. It must only be exposed to trusted
+// callers until requests carry an authenticated identity.
+type SaleReviewRequest struct {
+	ManagerID string `json:"manager_id"`
+	Notes     string `json:"notes"`
+}
+
+// SaleReview is a manager's decision on a pending sale and its outcome
+type SaleReview struct {
+	Sale            mysql.Sale          `json:"sale"`
+	Approval        mysql.SaleApproval  `json:"approval"`
+	Contract        *mysql.SaleContract `json:"contract,omitempty"`
+	TradeInVehicle  *mysql.Vehicle      `json:"trade_in_vehicle,omitempty"`
+	ReturnedTradeIn *mysql.TradeIn      `json:"returned_trade_in,omitempty"`
+}
+
+type LeaseQuoteRequest struct {
+	CustomerID    string  `json:"customer_id"`
+	VehicleID     string  `json:"vehicle_id"`
+	DownPayment   float64 `json:"down_payment"`
+	TermMonths    int     `json:"term_months"`
+	AnnualMileage int     `json:"annual_mileage"`
+	TradeInID     string  `json:"trade_in_id"`
+}
+
+type LeaseQuote struct {
+	CustomerID        string  `json:"customer_id"`
+	VehicleID         string  `json:"vehicle_id"`
+	TermMonths        int     `json:"term_months"`
+	AnnualMileage     int     `json:"annual_mileage"`
+	VehiclePrice      float64 `json:"vehicle_price"`
+	SalePrice         float64 `json:"sale_price"`
+	ResidualPercent   float64 `json:"residual_percent"`
+	ResidualValue     float64 `json:"residual_value"`
+	MoneyFactor       float64 `json:"money_factor"`
+	EquivalentAPR     float64 `json:"equivalent_apr"`
+	AcquisitionFee    float64 `json:"acquisition_fee"`
+	TaxesAndFees      float64 `json:"taxes_and_fees"`
+	TradeInCredit     float64 `json:"trade_in_credit"`
+	CapCostReduction  float64 `json:"cap_cost_reduction"`
+	AdjustedCapCost   float64 `json:"adjusted_cap_cost"`
+	DepreciationFee   float64 `json:"depreciation_fee"`
+	FinanceFee        float64 `json:"finance_fee"`
+	MonthlyPayment    float64 `json:"monthly_payment"`
+	DueAtSigning      float64 `json:"due_at_signing"`
+	ExcessMileageRate float64 `json:"excess_mileage_rate"`
+}
+
+type TradeInRequest struct {
+	CustomerID string                 `json:"customer_id"`
+	VIN        string                 `json:"vin"`
+	Make       string                 `json:"make"`
+	Model      string                 `json:"model"`
+	Year       int                    `json:"year"`
+	Color      string                 `json:"color"`
+	Mileage    int                    `json:"mileage"`
+	Condition  mysql.TradeInCondition `json:"condition"`
+}
+
+// TradeInAppraisal is a recorded trade-in offer and how it was valued
+type TradeInAppraisal struct {
+	TradeIn           mysql.TradeIn `json:"trade_in"`
+	BaseValue         float64       `json:"base_value"`
+	AgeYears          int           `json:"age_years"`
+	RetainedPercent   float64       `json:"retained_percent"`
+	ExpectedMileage   int           `json:"expected_mileage"`
+	MileageAdjustment float64       `json:"mileage_adjustment"`
+	ConditionFactor   float64       `json:"condition_factor"`
+	OfferAmount       float64       `json:"offer_amount"`
+}
+
+type OutTheDoorRequest struct {
+	CustomerID string  `json:"customer_id"`
+	VehicleID  string  `json:"vehicle_id"`
+	Discount   float64 `json:"discount"`
+	TradeInID  string  `json:"trade_in_id"`
+}
+
+// OutTheDoorPrice is the sale price plus the taxes and fees charged where the
+// customer lives. TaxJurisdiction is the fee table entry used, e.g. "TX" or
+// "TX 75201".
+type OutTheDoorPrice struct {
+	CustomerID        string  `json:"customer_id"`
+	VehicleID         string  `json:"vehicle_id"`
+	TaxJurisdiction   string  `json:"tax_jurisdiction"`
+	VehiclePrice      float64 `json:"vehicle_price"`
+	Discount          float64 `json:"discount"`
+	SalePrice         float64 `json:"sale_price"`
+	TradeInCredit     float64 `json:"trade_in_credit"`
+	TaxableAmount     float64 `json:"taxable_amount"`
+	SalesTaxRate      float64 `json:"sales_tax_rate"`
+	SalesTax          float64 `json:"sales_tax"`
+	DocFee            float64 `json:"doc_fee"`
+	RegistrationFee   float64 `json:"registration_fee"`
+	TitleFee          float64 `json:"title_fee"`
+	TotalTaxesAndFees float64 `json:"total_taxes_and_fees"`
+	OutTheDoorPrice   float64 `json:"out_the_door_price"`
 }
 
 type FinancingOptions struct {
-	CustomerID string            `json:"customer_id"`
-	VehicleID  string            `json:"vehicle_id"`
-	Options    []FinancingOption `json:"options"`
+	CustomerID    string            `json:"customer_id"`
+	VehicleID     string            `json:"vehicle_id"`
+	TradeInCredit float64           `json:"trade_in_credit"`
+	Pricing       OutTheDoorPrice   `json:"pricing"`
+	Options       []FinancingOption `json:"options"`
 }
 
 type FinancingOption struct {
@@ -159,11 +343,51 @@ type FinancingOption struct {
 	TotalCost      float64 `json:"total_cost"`
 }
 
-type SalesContract struct {
-	ContractID  string        `json:"contract_id"`
-	SaleID      string        `json:"sale_id"`
-	Terms       ContractTerms `json:"terms"`
-	GeneratedAt time.Time     `json:"generated_at"`
+// ExtraPayments go straight to principal on top of the scheduled payment:
+// Monthly every month and each lump sum in the month it names
+type ExtraPayments struct {
+	Monthly  float64          `json:"extra_monthly"`
+	LumpSums []LumpSumPayment `json:"lump_sums,omitempty"`
+}
+
+type LumpSumPayment struct {
+	Month  int     `json:"month"`
+	Amount float64 `json:"amount"`
+}
+
+type AmortizationQuoteRequest struct {
+	CustomerID  string  `json:"customer_id"`
+	VehicleID   string  `json:"vehicle_id"`
+	DownPayment float64 `json:"down_payment"`
+	TradeInID   string  `json:"trade_in_id"`
+	TermMonths  int     `json:"term_months"`
+	ExtraPayments
+}
+
+// AmortizationSchedule is a loan paid off month by month. InterestSaved and
+// MonthsSaved compare it against paying only the scheduled amount.
+type AmortizationSchedule struct {
+	SaleID         string                `json:"sale_id,omitempty"`
+	LoanAmount     float64               `json:"loan_amount"`
+	InterestRate   float64               `json:"interest_rate"`
+	TermMonths     int                   `json:"term_months"`
+	MonthlyPayment float64               `json:"monthly_payment"`
+	ExtraPayments  ExtraPayments         `json:"extra_payments"`
+	PayoffMonths   int                   `json:"payoff_months"`
+	TotalInterest  float64               `json:"total_interest"`
+	TotalPaid      float64               `json:"total_paid"`
+	InterestSaved  float64               `json:"interest_saved"`
+	MonthsSaved    int                   `json:"months_saved"`
+	Payments       []AmortizationPayment `json:"payments"`
+}
+
+type AmortizationPayment struct {
+	Month     int     `json:"month"`
+	Payment   float64 `json:"payment"`
+	Principal float64 `json:"principal"`
+	Interest  float64 `json:"interest"`
+	Extra     float64 `json:"extra"`
+	Balance   float64 `json:"balance"`
 }
 
 type FinancingDetails struct {
@@ -176,6 +400,7 @@ type FinancingDetails struct {
 type ReportPeriod struct {
 	StartDate time.Time `json:"start_date"`
 	EndDate   time.Time `json:"end_date"`
+	Timezone  string    `json:"timezone,omitempty"`
 }
 
 type SalesReport struct {
@@ -185,6 +410,14 @@ type SalesReport struct {
 	AverageRevenue float64            `json:"average_revenue"`
 	TopVehicles    []VehicleSalesData `json:"top_vehicles"`
 	SalesByStatus  map[string]int     `json:"sales_by_status"`
+
+	// only completed sales count toward the totals above. Sales waiting on
+	// approval and cancelled sales are totalled here; rejected sales only
+	// appear in SalesByStatus.
+	PendingSales     int     `json:"pending_sales"`
+	PendingRevenue   float64 `json:"pending_revenue"`
+	CancelledSales   int     `json:"cancelled_sales"`
+	CancelledRevenue float64 `json:"cancelled_revenue"`
 }
 
 type PerformanceReport struct {
@@ -193,10 +426,32 @@ type PerformanceReport struct {
 	SalesPersonData []SalespersonPerformance `json:"salespeople"`
 }
 
+type SalesTimeSeries struct {
+	Period   ReportPeriod        `json:"period"`
+	Interval mysql.TimeBucket    `json:"interval"`
+	GroupBy  mysql.SalesGrouping `json:"group_by,omitempty"`
+	Series   []SalesSeries       `json:"series"`
+}
+
+type SalesSeries struct {
+	Key    string             `json:"key,omitempty"`
+	Label  string             `json:"label,omitempty"`
+	Points []SalesSeriesPoint `json:"points"`
+}
+
+type SalesSeriesPoint struct {
+	BucketStart  time.Time `json:"bucket_start"`
+	UnitsSold    int       `json:"units_sold"`
+	Revenue      float64   `json:"revenue"`
+	AveragePrice float64   `json:"average_price"`
+}
+
 type VehicleSalesData struct {
-	Vehicle      mysql.Vehicle `json:"vehicle"`
-	UnitsSold    int           `json:"units_sold"`
-	TotalRevenue float64       `json:"total_revenue"`
+	Make         string  `json:"make"`
+	Model        string  `json:"model"`
+	Year         int     `json:"year"`
+	UnitsSold    int     `json:"units_sold"`
+	TotalRevenue float64 `json:"total_revenue"`
 }
 
 type SalespersonPerformance struct {
@@ -204,12 +459,53 @@ type SalespersonPerformance struct {
 	TotalSales   int               `json:"total_sales"`
 	TotalRevenue float64           `json:"total_revenue"`
 	Commission   float64           `json:"commission"`
+
+	PendingSales       int     `json:"pending_sales"`
+	PendingRevenue     float64 `json:"pending_revenue"`
+	CancelledSales     int     `json:"cancelled_sales"`
+	CommissionReversed float64 `json:"commission_reversed"`
 }
 
+// InventoryReportOptions tunes the inventory report. Zero values use the
+// defaults: the top 10 vehicles by value and a 90 day turn window.
+type InventoryReportOptions struct {
+	TopN           int `json:"top_n"`
+	TurnWindowDays int `json:"turn_window_days"`
+}
+
+// InventoryReport describes the vehicles on the lot (anything not sold).
+// VehiclesByStatus also counts sold vehicles.
 type InventoryReport struct {
-	TotalVehicles    int                `json:"total_vehicles"`
-	ValueByMake      map[string]float64 `json:"value_by_make"`
-	VehiclesByStatus map[string]int     `json:"vehicles_by_status"`
-	AverageAge       int                `json:"average_age"`
-	TopValueVehicles []mysql.Vehicle    `json:"top_value_vehicles"`
+	AsOf             time.Time              `json:"as_of"`
+	TotalVehicles    int                    `json:"total_vehicles"`
+	ValueByMake      map[string]float64     `json:"value_by_make"`
+	VehiclesByStatus map[string]int         `json:"vehicles_by_status"`
+	AverageDaysOnLot float64                `json:"average_days_on_lot"`
+	Aging            []InventoryAgingBucket `json:"aging"`
+	TurnWindowDays   int                    `json:"turn_window_days"`
+	TurnRates        []ModelTurnRate        `json:"turn_rates"`
+	TopValueVehicles []AgedVehicle          `json:"top_value_vehicles"`
+}
+
+type InventoryAgingBucket struct {
+	Bucket     mysql.InventoryAgeBucket `json:"bucket"`
+	Units      int                      `json:"units"`
+	TotalValue float64                  `json:"total_value"`
+}
+
+// ModelTurnRate compares sales in the turn window with what is still on the
+// lot. TurnRate is the share of the make/model's units that sold:
+// sold / (sold + on lot).
+type ModelTurnRate struct {
+	Make              string  `json:"make"`
+	Model             string  `json:"model"`
+	UnitsOnLot        int     `json:"units_on_lot"`
+	UnitsSold         int     `json:"units_sold"`
+	AverageDaysToSell float64 `json:"average_days_to_sell"`
+	TurnRate          float64 `json:"turn_rate"`
+}
+
+type AgedVehicle struct {
+	Vehicle   mysql.Vehicle `json:"vehicle"`
+	DaysOnLot int           `json:"days_on_lot"`
 }
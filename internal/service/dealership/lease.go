@@ -0,0 +1,135 @@
+package dealership
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+const (
+	leaseAcquisitionFee = 695.0
+
+	// leases are priced off the customer's loan rate plus this spread
+	leaseRateSpread = 0.5
+
+	// charged per mile over the allowance when the car is returned
+	leaseExcessMileageRate = 0.25
+)
+
+// leaseResiduals is the share of the vehicle's price it is expected to be
+// worth at the end of each lease term at the standard 12,000 mile allowance
+var leaseResiduals = map[int]float64{
+	24: 0.62,
+	36: 0.56,
+	48: 0.48,
+}
+
+// leaseMileageAdjustments move the residual up for lower allowances and
+// down for higher ones
+var leaseMileageAdjustments = map[int]float64{
+	10000: 0.01,
+	12000: 0,
+	15000: -0.03,
+}
+
+const defaultLeaseMileage = 12000
+
+func (s *service) QuoteLease(ctx context.Context, request LeaseQuoteRequest) (*LeaseQuote, error) {
+	vehicle, err := s.getVehicle(ctx, request.VehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := s.getCustomer(ctx, request.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	creditDecision, err := s.currentCreditDecision(ctx, customer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credit decision: %w", err)
+	}
+
+	tradeIn, err := s.tradeInCredit(ctx, request.TradeInID, request.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	tradeInCredit := tradeInOffer(tradeIn)
+	pricing := s.priceOutTheDoor(customer, vehicle, 0, tradeInCredit)
+	return quoteLease(vehicle.Price, vehicle.Price, request, tradeInCredit, pricing.TotalTaxesAndFees, creditDecision)
+}
+
+// quoteLease prices a lease on a vehicle listed at listPrice and selling for
+// salePrice. The residual is a share of the list price, so a discount lowers
+// the capitalized cost without lowering the residual. Taxes and fees are
+// capitalized rather than paid up front. The down payment and trade-in credit
+// both reduce the capitalized cost, and the adjusted capitalized cost (sale
+// price plus acquisition fee, taxes and fees less those reductions) has to
+// fit within the customer's credit limit.
+func quoteLease(listPrice, salePrice float64, request LeaseQuoteRequest, tradeInCredit, taxesAndFees float64, creditDecision *CreditDecision) (*LeaseQuote, error) {
+	if !creditDecision.Approved {
+		return nil, fmt.Errorf("customer not approved for leasing: %w", ErrInvalidInput)
+	}
+
+	residualPercent, ok := leaseResiduals[request.TermMonths]
+	if !ok {
+		return nil, fmt.Errorf("lease term of %d months is not offered: %w", request.TermMonths, ErrInvalidInput)
+	}
+
+	mileage := request.AnnualMileage
+	if mileage == 0 {
+		mileage = defaultLeaseMileage
+	}
+	adjustment, ok := leaseMileageAdjustments[mileage]
+	if !ok {
+		return nil, fmt.Errorf("annual mileage allowance of %d is not offered: %w", mileage, ErrInvalidInput)
+	}
+	residualPercent += adjustment
+
+	if err := validateDownPayment(salePrice+taxesAndFees, request.DownPayment, tradeInCredit); err != nil {
+		return nil, err
+	}
+
+	capCostReduction := request.DownPayment + tradeInCredit
+	residualValue := roundCents(listPrice * residualPercent)
+	adjustedCapCost := roundCents(salePrice + leaseAcquisitionFee + taxesAndFees - capCostReduction)
+	if adjustedCapCost > creditDecision.CreditLimit {
+		return nil, fmt.Errorf("leased amount exceeds credit limit: %w", ErrInvalidInput)
+	}
+
+	// the money factor is the lease equivalent of an interest rate: APR / 2400
+	apr := creditDecision.InterestRate + leaseRateSpread
+	moneyFactor := apr / 2400
+
+	depreciationFee := math.Max(adjustedCapCost-residualValue, 0) / float64(request.TermMonths)
+	financeFee := (adjustedCapCost + residualValue) * moneyFactor
+	monthlyPayment := roundCents(depreciationFee + financeFee)
+
+	return &LeaseQuote{
+		CustomerID:        request.CustomerID,
+		VehicleID:         request.VehicleID,
+		TermMonths:        request.TermMonths,
+		AnnualMileage:     mileage,
+		VehiclePrice:      listPrice,
+		SalePrice:         salePrice,
+		ResidualPercent:   residualPercent,
+		ResidualValue:     residualValue,
+		MoneyFactor:       math.Round(moneyFactor*1e6) / 1e6,
+		EquivalentAPR:     apr,
+		AcquisitionFee:    leaseAcquisitionFee,
+		TaxesAndFees:      taxesAndFees,
+		TradeInCredit:     tradeInCredit,
+		CapCostReduction:  capCostReduction,
+		AdjustedCapCost:   adjustedCapCost,
+		DepreciationFee:   roundCents(depreciationFee),
+		FinanceFee:        roundCents(financeFee),
+		MonthlyPayment:    monthlyPayment,
+		DueAtSigning:      roundCents(request.DownPayment + monthlyPayment),
+		ExcessMileageRate: leaseExcessMileageRate,
+	}, nil
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,121 @@
+package dealership
+
+import (
+	"errors"
+	"testing"
+)
+
+var approvedForLease = &CreditDecision{Approved: true, CreditLimit: 100000, InterestRate: 3.5}
+
+func TestQuoteLease(t *testing.T) {
+	tests := []struct {
+		name            string
+		listPrice       float64
+		salePrice       float64
+		request         LeaseQuoteRequest
+		tradeInCredit   float64
+		taxesAndFees    float64
+		wantMileage     int
+		wantResidualPct float64
+		wantResidual    float64
+		wantCapCost     float64
+	}{
+		{
+			name:      "residual comes from the list price, not the discounted price",
+			listPrice: 30000, salePrice: 27000,
+			request:      LeaseQuoteRequest{TermMonths: 36, DownPayment: 2000},
+			taxesAndFees: 1500,
+			wantMileage:  12000, wantResidualPct: 0.56, wantResidual: 16800, wantCapCost: 27195,
+		},
+		{
+			name:      "lower mileage raises the residual",
+			listPrice: 30000, salePrice: 30000,
+			request:     LeaseQuoteRequest{TermMonths: 24, AnnualMileage: 10000},
+			wantMileage: 10000, wantResidualPct: 0.63, wantResidual: 18900, wantCapCost: 30695,
+		},
+		{
+			name:      "trade-in reduces the capitalized cost",
+			listPrice: 40000, salePrice: 40000,
+			request:       LeaseQuoteRequest{TermMonths: 48, AnnualMileage: 15000, DownPayment: 1000},
+			tradeInCredit: 4000, taxesAndFees: 2000,
+			wantMileage: 15000, wantResidualPct: 0.45, wantResidual: 18000, wantCapCost: 37695,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := quoteLease(tt.listPrice, tt.salePrice, tt.request, tt.tradeInCredit, tt.taxesAndFees, approvedForLease)
+			if err != nil {
+				t.Fatalf("quoteLease returned error: %v", err)
+			}
+
+			if quote.AnnualMileage != tt.wantMileage {
+				t.Errorf("annual mileage = %d, want %d", quote.AnnualMileage, tt.wantMileage)
+			}
+			if roundCents(quote.ResidualPercent) != tt.wantResidualPct {
+				t.Errorf("residual percent = %v, want %v", quote.ResidualPercent, tt.wantResidualPct)
+			}
+			if quote.ResidualValue != tt.wantResidual {
+				t.Errorf("residual value = %v, want %v", quote.ResidualValue, tt.wantResidual)
+			}
+			if quote.AdjustedCapCost != tt.wantCapCost {
+				t.Errorf("adjusted cap cost = %v, want %v", quote.AdjustedCapCost, tt.wantCapCost)
+			}
+			if quote.VehiclePrice != tt.listPrice || quote.SalePrice != tt.salePrice {
+				t.Errorf("prices = %v list, %v sale, want %v and %v", quote.VehiclePrice, quote.SalePrice, tt.listPrice, tt.salePrice)
+			}
+
+			wantMonthly := roundCents(quote.DepreciationFee + quote.FinanceFee)
+			if diff := quote.MonthlyPayment - wantMonthly; diff > 0.01 || diff < -0.01 {
+				t.Errorf("monthly payment = %v, want depreciation plus finance fee %v", quote.MonthlyPayment, wantMonthly)
+			}
+		})
+	}
+}
+
+// A discount is taken off the sale price but the residual is still set from
+// the list price, so the discount comes entirely off the depreciation the
+// customer pays for.
+func TestLeaseDiscountLowersOnlyTheDepreciation(t *testing.T) {
+	request := LeaseQuoteRequest{TermMonths: 36}
+
+	full, err := quoteLease(30000, 30000, request, 0, 0, approvedForLease)
+	if err != nil {
+		t.Fatalf("quoteLease at list price returned error: %v", err)
+	}
+	discounted, err := quoteLease(30000, 27000, request, 0, 0, approvedForLease)
+	if err != nil {
+		t.Fatalf("quoteLease at a discount returned error: %v", err)
+	}
+
+	if discounted.ResidualValue != full.ResidualValue {
+		t.Errorf("residual moved from %v to %v with the discount", full.ResidualValue, discounted.ResidualValue)
+	}
+	if got := roundCents(full.DepreciationFee - discounted.DepreciationFee); got != roundCents(3000.0/36) {
+		t.Errorf("discount took %v a month off the depreciation, want the 3000 spread over 36 months", got)
+	}
+	if discounted.MonthlyPayment >= full.MonthlyPayment {
+		t.Errorf("monthly payment %v with the discount, want less than %v", discounted.MonthlyPayment, full.MonthlyPayment)
+	}
+}
+
+func TestQuoteLeaseRefusesTermsNotOffered(t *testing.T) {
+	tests := map[string]struct {
+		request LeaseQuoteRequest
+		credit  *CreditDecision
+	}{
+		"declined credit":                        {LeaseQuoteRequest{TermMonths: 36}, &CreditDecision{Approved: false}},
+		"term not offered":                       {LeaseQuoteRequest{TermMonths: 30}, approvedForLease},
+		"mileage not offered":                    {LeaseQuoteRequest{TermMonths: 36, AnnualMileage: 20000}, approvedForLease},
+		"capitalized cost over the credit limit": {LeaseQuoteRequest{TermMonths: 36}, &CreditDecision{Approved: true, CreditLimit: 20000, InterestRate: 3.5}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := quoteLease(30000, 30000, tt.request, 0, 0, tt.credit)
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("quoteLease error = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+}
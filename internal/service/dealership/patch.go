@@ -0,0 +1,57 @@
+package dealership
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// applyMergePatch applies a JSON merge patch (RFC 7386) to the JSON encoding
+// of current and decodes the result into target
+func applyMergePatch(current interface{}, patch []byte, target interface{}) error {
+	original, err := json.Marshal(current)
+	if err != nil {
+		return fmt.Errorf("failed to encode current document: %w", err)
+	}
+
+	var originalDoc, patchDoc interface{}
+	if err := json.Unmarshal(original, &originalDoc); err != nil {
+		return fmt.Errorf("failed to decode current document: %w", err)
+	}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return fmt.Errorf("invalid merge patch: %w", err)
+	}
+	if _, ok := patchDoc.(map[string]interface{}); !ok {
+		return fmt.Errorf("invalid merge patch: expected a JSON object")
+	}
+
+	merged, err := json.Marshal(mergePatch(originalDoc, patchDoc))
+	if err != nil {
+		return fmt.Errorf("failed to encode patched document: %w", err)
+	}
+
+	if err := json.Unmarshal(merged, target); err != nil {
+		return fmt.Errorf("patched document is invalid: %w", err)
+	}
+	return nil
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
@@ -8,55 +8,68 @@ import (
 )
 
 func (s *service) GenerateSalesReport(ctx context.Context, period ReportPeriod) (*SalesReport, error) {
-	allSales, err := s.sales_repo.GetAll()
+	start, end, err := period.bounds()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get sales: %w", err)
+		return nil, err
 	}
 
-	var periodSales []mysql.Sale
+	summaries, err := s.sales_repo.SummarizeByVehicle(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize sales: %w", err)
+	}
+
+	totalSales := 0
 	totalRevenue := float64(0)
+	salesByStatus := make(map[string]int)
+	pendingSales := 0
+	pendingRevenue := float64(0)
+	cancelledSales := 0
+	cancelledRevenue := float64(0)
+
+	// rows come back ordered by revenue, so vehicles keep that order as the
+	// per-status rows are folded together
+	var topVehicles []VehicleSalesData
+	vehicleIndex := make(map[string]int)
+
+	for _, summary := range summaries {
+		salesByStatus[string(summary.Status)] += summary.Units_Sold
+		switch summary.Status {
+		case mysql.SaleStatusCompleted:
+		case mysql.SaleStatusPending:
+			pendingSales += summary.Units_Sold
+			pendingRevenue += summary.Total_Revenue
+			continue
+		case mysql.SaleStatusCancelled:
+			cancelledSales += summary.Units_Sold
+			cancelledRevenue += summary.Total_Revenue
+			continue
+		default:
+			continue
+		}
+		totalSales += summary.Units_Sold
+		totalRevenue += summary.Total_Revenue
 
-	for _, sale := range allSales {
-		if sale.Sale_Date.After(period.StartDate) && sale.Sale_Date.Before(period.EndDate) {
-			periodSales = append(periodSales, sale)
-			totalRevenue += sale.Sale_Price
+		key := fmt.Sprintf("%s-%s-%d", summary.Make, summary.Model, summary.Year)
+		if i, ok := vehicleIndex[key]; ok {
+			topVehicles[i].UnitsSold += summary.Units_Sold
+			topVehicles[i].TotalRevenue += summary.Total_Revenue
+			continue
 		}
+		vehicleIndex[key] = len(topVehicles)
+		topVehicles = append(topVehicles, VehicleSalesData{
+			Make:         summary.Make,
+			Model:        summary.Model,
+			Year:         summary.Year,
+			UnitsSold:    summary.Units_Sold,
+			TotalRevenue: summary.Total_Revenue,
+		})
 	}
 
-	totalSales := len(periodSales)
 	averageRevenue := float64(0)
 	if totalSales > 0 {
 		averageRevenue = totalRevenue / float64(totalSales)
 	}
 
-	vehicleSalesMap := make(map[string]*VehicleSalesData)
-	for _, sale := range periodSales {
-		vehicle, err := s.vehicle_repo.GetByID(sale.Vehicle_ID)
-		if err == nil {
-			key := fmt.Sprintf("%s-%s-%d", vehicle.Make, vehicle.Model, vehicle.Year)
-			if existing, ok := vehicleSalesMap[key]; ok {
-				existing.UnitsSold++
-				existing.TotalRevenue += sale.Sale_Price
-			} else {
-				vehicleSalesMap[key] = &VehicleSalesData{
-					Vehicle:      vehicle,
-					UnitsSold:    1,
-					TotalRevenue: sale.Sale_Price,
-				}
-			}
-		}
-	}
-
-	var topVehicles []VehicleSalesData
-	for _, data := range vehicleSalesMap {
-		topVehicles = append(topVehicles, *data)
-	}
-
-	salesByStatus := make(map[string]int)
-	for _, sale := range periodSales {
-		salesByStatus[string(sale.Status)]++
-	}
-
 	return &SalesReport{
 		Period:         period,
 		TotalSales:     totalSales,
@@ -64,98 +77,336 @@ func (s *service) GenerateSalesReport(ctx context.Context, period ReportPeriod)
 		AverageRevenue: averageRevenue,
 		TopVehicles:    topVehicles,
 		SalesByStatus:  salesByStatus,
+
+		PendingSales:     pendingSales,
+		PendingRevenue:   pendingRevenue,
+		CancelledSales:   cancelledSales,
+		CancelledRevenue: cancelledRevenue,
 	}, nil
 }
 
 func (s *service) GetTopPerformers(ctx context.Context, period ReportPeriod) (*PerformanceReport, error) {
-	allSales, err := s.sales_repo.GetAll()
+	start, end, err := period.bounds()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get sales: %w", err)
+		return nil, err
 	}
 
-	allSalespeople, err := s.salesperson_repo.GetAll()
+	summaries, err := s.sales_repo.SummarizeBySalesperson(ctx, start, end)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get salespeople: %w", err)
+		return nil, fmt.Errorf("failed to summarize sales by salesperson: %w", err)
 	}
 
-	if len(allSalespeople) == 0 {
+	if len(summaries) == 0 {
 		return nil, fmt.Errorf("no salespeople found")
 	}
 
-	salesByPerson := make(map[string]*SalespersonPerformance)
+	performanceData := make([]SalespersonPerformance, len(summaries))
+	for i, summary := range summaries {
+		performanceData[i] = SalespersonPerformance{
+			Salesperson:  summary.Salesperson,
+			TotalSales:   summary.Total_Sales,
+			TotalRevenue: summary.Total_Revenue,
+			Commission:   summary.Commission_Earned,
 
-	for _, salesperson := range allSalespeople {
-		salesByPerson[salesperson.ID] = &SalespersonPerformance{
-			Salesperson:  salesperson,
-			TotalSales:   0,
-			TotalRevenue: 0,
-			Commission:   0,
+			PendingSales:       summary.Pending_Sales,
+			PendingRevenue:     summary.Pending_Revenue,
+			CancelledSales:     summary.Cancelled_Sales,
+			CommissionReversed: summary.Commission_Reversed,
 		}
 	}
 
-	for _, sale := range allSales {
-		if sale.Sale_Date.After(period.StartDate) && sale.Sale_Date.Before(period.EndDate) {
-			if perf, ok := salesByPerson[sale.Salesperson_ID]; ok {
-				perf.TotalSales++
-				perf.TotalRevenue += sale.Sale_Price
-				perf.Commission += sale.Sale_Price * 0.02
-			}
-		}
+	return &PerformanceReport{
+		Period:          period,
+		TopSalesperson:  summaries[0].Salesperson,
+		SalesPersonData: performanceData,
+	}, nil
+}
+
+// keeps a daily series over several years from producing a huge response
+const maxTimeSeriesBuckets = 1000
+
+// GetSalesTimeSeries buckets sales over the period on the period's local
+// days. Every series has a point for every bucket, with zeros where nothing
+// sold, so charts don't have to fill gaps.
+func (s *service) GetSalesTimeSeries(ctx context.Context, period ReportPeriod, interval mysql.TimeBucket, groupBy mysql.SalesGrouping) (*SalesTimeSeries, error) {
+	start, end, err := period.bounds()
+	if err != nil {
+		return nil, err
+	}
+	switch interval {
+	case "":
+		interval = mysql.TimeBucketDay
+	case mysql.TimeBucketDay, mysql.TimeBucketWeek, mysql.TimeBucketMonth:
+	default:
+		return nil, fmt.Errorf("interval must be day, week or month: %w", ErrInvalidQuery)
+	}
+	switch groupBy {
+	case mysql.SalesGroupingNone, mysql.SalesGroupingMake, mysql.SalesGroupingSalesperson, mysql.SalesGroupingPaymentMethod:
+	default:
+		return nil, fmt.Errorf("group_by must be make, salesperson or payment_method: %w", ErrInvalidQuery)
+	}
+
+	buckets := bucketStarts(startOfDay(start), end, interval)
+	if len(buckets) > maxTimeSeriesBuckets {
+		return nil, fmt.Errorf("period spans %d %s buckets, the limit is %d: %w", len(buckets), interval, maxTimeSeriesBuckets, ErrInvalidQuery)
+	}
+
+	location := start.Location()
+
+	rows, err := s.sales_repo.TimeSeries(ctx, mysql.SalesTimeSeriesQuery{
+		Start:   start,
+		End:     end,
+		GroupBy: groupBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales time series: %w", err)
 	}
 
-	var performanceData []SalespersonPerformance
-	topSalesperson := allSalespeople[0]
-	topRevenue := float64(0)
+	bucketIndex := make(map[string]int, len(buckets))
+	for i, bucket := range buckets {
+		bucketIndex[bucket.Format("2006-01-02")] = i
+	}
 
-	for _, perf := range salesByPerson {
-		performanceData = append(performanceData, *perf)
-		if perf.TotalRevenue > topRevenue {
-			topRevenue = perf.TotalRevenue
-			topSalesperson = perf.Salesperson
+	var series []SalesSeries
+	seriesIndex := make(map[string]int)
+	if groupBy == mysql.SalesGroupingNone {
+		series = append(series, SalesSeries{Points: emptyPoints(buckets)})
+		seriesIndex[""] = 0
+	}
+
+	for _, row := range rows {
+		// slots are placed on local days with the zone's offset at the time,
+		// so buckets either side of a daylight saving change are cut correctly
+		slot := time.Unix(row.Slot_Start, 0).In(location)
+		i, ok := bucketIndex[bucketStart(slot, interval).Format("2006-01-02")]
+		if !ok {
+			continue
 		}
+
+		n, ok := seriesIndex[row.Group_Key]
+		if !ok {
+			n = len(series)
+			seriesIndex[row.Group_Key] = n
+			series = append(series, SalesSeries{
+				Key:    row.Group_Key,
+				Label:  row.Group_Label,
+				Points: emptyPoints(buckets),
+			})
+		}
+
+		point := &series[n].Points[i]
+		point.UnitsSold += row.Units_Sold
+		point.Revenue += row.Total_Revenue
 	}
 
-	return &PerformanceReport{
-		Period:          period,
-		TopSalesperson:  topSalesperson,
-		SalesPersonData: performanceData,
+	for n := range series {
+		for i := range series[n].Points {
+			point := &series[n].Points[i]
+			point.Revenue = roundCents(point.Revenue)
+			if point.UnitsSold > 0 {
+				point.AveragePrice = roundCents(point.Revenue / float64(point.UnitsSold))
+			}
+		}
+	}
+
+	if series == nil {
+		series = []SalesSeries{}
+	}
+
+	period.Timezone = location.String()
+	return &SalesTimeSeries{
+		Period:   period,
+		Interval: interval,
+		GroupBy:  groupBy,
+		Series:   series,
 	}, nil
 }
 
-func (s *service) GetInventoryReport(ctx context.Context) (*InventoryReport, error) {
-	vehicles, err := s.vehicle_repo.GetAll()
+func (s *service) GetInventoryReport(ctx context.Context, options InventoryReportOptions) (*InventoryReport, error) {
+	options, err := options.withDefaults()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get vehicles: %w", err)
+		return nil, err
 	}
 
-	totalVehicles := len(vehicles)
-	valueByMake := make(map[string]float64)
-	vehiclesByStatus := make(map[string]int)
-	totalAge := 0
-	currentYear := time.Now().Year()
+	asOf := time.Now()
+	summaries, err := s.vehicle_repo.SummarizeInventory(ctx, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize inventory: %w", err)
+	}
 
-	var topValueVehicles []mysql.Vehicle
+	report := &InventoryReport{
+		AsOf:             asOf,
+		ValueByMake:      make(map[string]float64),
+		VehiclesByStatus: make(map[string]int),
+		TurnWindowDays:   options.TurnWindowDays,
+	}
 
-	for _, vehicle := range vehicles {
-		valueByMake[vehicle.Make] += vehicle.Price
-		vehiclesByStatus[string(vehicle.Status)]++
-		totalAge += currentYear - vehicle.Year
+	aging := map[mysql.InventoryAgeBucket]*InventoryAgingBucket{}
+	for _, bucket := range inventoryAgeBuckets {
+		aging[bucket] = &InventoryAgingBucket{Bucket: bucket}
+	}
 
-		if vehicle.Price > 30000 {
-			topValueVehicles = append(topValueVehicles, vehicle)
+	totalDaysOnLot := 0
+	for _, summary := range summaries {
+		report.VehiclesByStatus[string(summary.Status)] += summary.Units
+		if summary.Status == mysql.VehicleStatusSold {
+			continue
+		}
+
+		report.TotalVehicles += summary.Units
+		report.ValueByMake[summary.Make] += summary.Total_Value
+		totalDaysOnLot += summary.Total_Days_On_Lot
+
+		if bucket, ok := aging[summary.Age_Bucket]; ok {
+			bucket.Units += summary.Units
+			bucket.TotalValue += summary.Total_Value
 		}
 	}
 
-	averageAge := 0
-	if totalVehicles > 0 {
-		averageAge = totalAge / totalVehicles
+	if report.TotalVehicles > 0 {
+		report.AverageDaysOnLot = float64(totalDaysOnLot) / float64(report.TotalVehicles)
+	}
+	for _, bucket := range inventoryAgeBuckets {
+		report.Aging = append(report.Aging, *aging[bucket])
 	}
 
-	return &InventoryReport{
-		TotalVehicles:    totalVehicles,
-		ValueByMake:      valueByMake,
-		VehiclesByStatus: vehiclesByStatus,
-		AverageAge:       averageAge,
-		TopValueVehicles: topValueVehicles,
-	}, nil
+	turnover, err := s.vehicle_repo.TurnoverByModel(ctx, asOf.AddDate(0, 0, -options.TurnWindowDays))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get turnover by model: %w", err)
+	}
+
+	report.TurnRates = make([]ModelTurnRate, len(turnover))
+	for i, model := range turnover {
+		report.TurnRates[i] = ModelTurnRate{
+			Make:              model.Make,
+			Model:             model.Model,
+			UnitsOnLot:        model.Units_On_Lot,
+			UnitsSold:         model.Units_Sold,
+			AverageDaysToSell: model.Average_Days_To_Sell,
+			TurnRate:          float64(model.Units_Sold) / float64(model.Units_Sold+model.Units_On_Lot),
+		}
+	}
+
+	topVehicles, err := s.vehicle_repo.List(ctx, mysql.VehicleFilter{Statuses: onLotStatuses},
+		mysql.ListOptions{Limit: options.TopN, Sort: "-price"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top value vehicles: %w", err)
+	}
+
+	report.TopValueVehicles = make([]AgedVehicle, len(topVehicles))
+	for i, vehicle := range topVehicles {
+		report.TopValueVehicles[i] = AgedVehicle{
+			Vehicle:   vehicle,
+			DaysOnLot: daysOnLot(vehicle, asOf),
+		}
+	}
+
+	return report, nil
+}
+
+// bounds returns the inclusive time range covered by the period. An end date
+// with no time of day covers that whole day.
+func (p ReportPeriod) bounds() (time.Time, time.Time, error) {
+	if p.StartDate.IsZero() || p.EndDate.IsZero() {
+		return time.Time{}, time.Time{}, fmt.Errorf("report period needs a start and end date: %w", ErrInvalidQuery)
+	}
+
+	end := p.EndDate
+	if end.Equal(startOfDay(end)) {
+		end = end.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	if p.StartDate.After(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("report start date is after end date: %w", ErrInvalidQuery)
+	}
+	return p.StartDate, end, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// bucketStarts lists the start of every bucket that overlaps [start, end]
+func bucketStarts(start, end time.Time, interval mysql.TimeBucket) []time.Time {
+	var buckets []time.Time
+	for bucket := bucketStart(start, interval); !bucket.After(end); {
+		buckets = append(buckets, bucket)
+		switch interval {
+		case mysql.TimeBucketWeek:
+			bucket = bucket.AddDate(0, 0, 7)
+		case mysql.TimeBucketMonth:
+			bucket = bucket.AddDate(0, 1, 0)
+		default:
+			bucket = bucket.AddDate(0, 0, 1)
+		}
+	}
+	return buckets
+}
+
+// bucketStart is the local midnight starting the bucket t falls in. Weeks
+// start on Monday.
+func bucketStart(t time.Time, interval mysql.TimeBucket) time.Time {
+	day := startOfDay(t)
+	switch interval {
+	case mysql.TimeBucketWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case mysql.TimeBucketMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day
+}
+
+func emptyPoints(buckets []time.Time) []SalesSeriesPoint {
+	points := make([]SalesSeriesPoint, len(buckets))
+	for i, bucket := range buckets {
+		points[i].BucketStart = bucket
+	}
+	return points
+}
+
+const (
+	defaultInventoryTopN  = 10
+	maxInventoryTopN      = 100
+	defaultTurnWindowDays = 90
+	maxTurnWindowDays     = 730
+)
+
+var inventoryAgeBuckets = []mysql.InventoryAgeBucket{
+	mysql.InventoryAge0To30,
+	mysql.InventoryAge31To60,
+	mysql.InventoryAge61To90,
+	mysql.InventoryAgeOver90,
+}
+
+// onLotStatuses are the statuses of vehicles still physically on the lot
+var onLotStatuses = []mysql.VehicleStatus{
+	mysql.VehicleStatusAvailable,
+	mysql.VehicleStatusReserved,
+	mysql.VehicleStatusPending,
+}
+
+func (o InventoryReportOptions) withDefaults() (InventoryReportOptions, error) {
+	if o.TopN == 0 {
+		o.TopN = defaultInventoryTopN
+	}
+	if o.TurnWindowDays == 0 {
+		o.TurnWindowDays = defaultTurnWindowDays
+	}
+	if o.TopN < 0 || o.TopN > maxInventoryTopN {
+		return o, fmt.Errorf("top must be between 1 and %d: %w", maxInventoryTopN, ErrInvalidQuery)
+	}
+	if o.TurnWindowDays < 0 || o.TurnWindowDays > maxTurnWindowDays {
+		return o, fmt.Errorf("turn window must be between 1 and %d days: %w", maxTurnWindowDays, ErrInvalidQuery)
+	}
+	return o, nil
+}
+
+// daysOnLot counts from the vehicle's latest listing, like the aging buckets
+func daysOnLot(vehicle mysql.Vehicle, asOf time.Time) int {
+	days := int(asOf.Sub(vehicle.Listed_At).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	return days
 }
@@ -0,0 +1,327 @@
+package dealership
+
+import (
+	"api-servers/internal/models/mysql"
+	mysqlrepo "api-servers/internal/repository/mysql"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// summarySaleRepository answers the report queries with canned rows
+type summarySaleRepository struct {
+	mysqlrepo.SaleRepository
+	byVehicle  []mysql.VehicleSalesSummary
+	timeSeries []mysql.SalesTimeSeriesRow
+}
+
+func (r *summarySaleRepository) SummarizeByVehicle(ctx context.Context, start, end time.Time) ([]mysql.VehicleSalesSummary, error) {
+	return r.byVehicle, nil
+}
+
+func (r *summarySaleRepository) TimeSeries(ctx context.Context, query mysql.SalesTimeSeriesQuery) ([]mysql.SalesTimeSeriesRow, error) {
+	return r.timeSeries, nil
+}
+
+var march2026 = ReportPeriod{
+	StartDate: time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+	EndDate:   time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC),
+}
+
+func TestGenerateSalesReportFoldsStatusesTogether(t *testing.T) {
+	repo := &summarySaleRepository{byVehicle: []mysql.VehicleSalesSummary{
+		{Make: "Honda", Model: "Accord", Year: 2024, Status: mysql.SaleStatusCompleted, Units_Sold: 2, Total_Revenue: 70000},
+		{Make: "Honda", Model: "Civic", Year: 2024, Status: mysql.SaleStatusCompleted, Units_Sold: 1, Total_Revenue: 25000},
+		{Make: "Honda", Model: "Civic", Year: 2024, Status: mysql.SaleStatusPending, Units_Sold: 1, Total_Revenue: 20000},
+		{Make: "Honda", Model: "Civic", Year: 2023, Status: mysql.SaleStatusCompleted, Units_Sold: 1, Total_Revenue: 18000},
+		{Make: "Honda", Model: "Civic", Year: 2024, Status: mysql.SaleStatusCancelled, Units_Sold: 2, Total_Revenue: 15000},
+	}}
+	s := &service{sales_repo: repo}
+
+	report, err := s.GenerateSalesReport(context.Background(), march2026)
+	if err != nil {
+		t.Fatalf("GenerateSalesReport returned error: %v", err)
+	}
+
+	if report.TotalSales != 4 || report.TotalRevenue != 113000 || report.AverageRevenue != 28250 {
+		t.Errorf("totals = %d sales, %v revenue, %v average, want completed sales only: 4, 113000, 28250",
+			report.TotalSales, report.TotalRevenue, report.AverageRevenue)
+	}
+	if report.PendingSales != 1 || report.PendingRevenue != 20000 || report.CancelledSales != 2 || report.CancelledRevenue != 15000 {
+		t.Errorf("pending %d for %v and cancelled %d for %v, want 1 for 20000 and 2 for 15000",
+			report.PendingSales, report.PendingRevenue, report.CancelledSales, report.CancelledRevenue)
+	}
+	if got := report.SalesByStatus; got["completed"] != 4 || got["pending"] != 1 || got["cancelled"] != 2 {
+		t.Errorf("sales by status = %v", got)
+	}
+
+	// model years are told apart and keep the repository's revenue order
+	if len(report.TopVehicles) != 3 || report.TopVehicles[1].Year != 2024 || report.TopVehicles[2].Year != 2023 {
+		t.Errorf("top vehicles = %+v, want Accord, Civic 2024, Civic 2023", report.TopVehicles)
+	}
+}
+
+func TestReportPeriodBounds(t *testing.T) {
+	start, end, err := march2026.bounds()
+	if err != nil {
+		t.Fatalf("bounds returned error: %v", err)
+	}
+	if !start.Equal(march2026.StartDate) {
+		t.Errorf("start = %v, want %v", start, march2026.StartDate)
+	}
+	if want := time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond); !end.Equal(want) {
+		t.Errorf("end = %v, want the whole of the last day up to %v", end, want)
+	}
+
+	noon := march2026
+	noon.EndDate = noon.EndDate.Add(12 * time.Hour)
+	if _, end, _ := noon.bounds(); !end.Equal(noon.EndDate) {
+		t.Errorf("end with a time of day = %v, want it kept at %v", end, noon.EndDate)
+	}
+
+	backwards := ReportPeriod{StartDate: march2026.EndDate, EndDate: march2026.StartDate}
+	if _, _, err := backwards.bounds(); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("bounds of a period ending before it starts = %v, want ErrInvalidQuery", err)
+	}
+}
+
+func TestBucketStart(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %v", err)
+	}
+	local := func(day, hour int) time.Time { return time.Date(2026, time.March, day, hour, 0, 0, 0, chicago) }
+
+	tests := []struct {
+		at       time.Time
+		interval mysql.TimeBucket
+		want     time.Time
+	}{
+		{local(11, 15), mysql.TimeBucketDay, local(11, 0)},
+		{local(11, 15), mysql.TimeBucketWeek, local(9, 0)},
+		{local(9, 0), mysql.TimeBucketWeek, local(9, 0)},
+		{local(15, 23), mysql.TimeBucketWeek, local(9, 0)},
+		{local(31, 23), mysql.TimeBucketMonth, local(1, 0)},
+		// the Monday of this week is before the daylight saving change
+		{local(10, 1), mysql.TimeBucketWeek, local(9, 0)},
+		{local(8, 12), mysql.TimeBucketWeek, local(2, 0)},
+	}
+	for _, tt := range tests {
+		if got := bucketStart(tt.at, tt.interval); !got.Equal(tt.want) {
+			t.Errorf("bucketStart(%s, %s) = %s, want %s", tt.at, tt.interval, got, tt.want)
+		}
+	}
+}
+
+// Chicago moved to daylight saving time on 8 March 2026, so that day is 23
+// hours long and its buckets still start at local midnight.
+func TestGetSalesTimeSeriesPlacesSalesOnLocalDays(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %v", err)
+	}
+	slot := func(day, hour, minute int) int64 {
+		return time.Date(2026, time.March, day, hour, minute, 0, 0, time.UTC).Unix()
+	}
+
+	repo := &summarySaleRepository{timeSeries: []mysql.SalesTimeSeriesRow{
+		// 23:30 on the 7th, standard time
+		{Slot_Start: slot(8, 5, 30), Units_Sold: 1, Total_Revenue: 20000},
+		// 23:30 on the 8th, daylight time
+		{Slot_Start: slot(9, 4, 30), Units_Sold: 2, Total_Revenue: 40000.5},
+		// midnight on the 9th
+		{Slot_Start: slot(9, 5, 0), Units_Sold: 1, Total_Revenue: 25000},
+		// after the period, with the end date covering the whole 9th
+		{Slot_Start: slot(10, 5, 0), Units_Sold: 1, Total_Revenue: 99000},
+	}}
+	s := &service{sales_repo: repo}
+
+	period := ReportPeriod{
+		StartDate: time.Date(2026, time.March, 7, 0, 0, 0, 0, chicago),
+		EndDate:   time.Date(2026, time.March, 9, 0, 0, 0, 0, chicago),
+	}
+	report, err := s.GetSalesTimeSeries(context.Background(), period, "", mysql.SalesGroupingNone)
+	if err != nil {
+		t.Fatalf("GetSalesTimeSeries returned error: %v", err)
+	}
+	if report.Interval != mysql.TimeBucketDay || report.Period.Timezone != "America/Chicago" {
+		t.Errorf("interval %q in %q, want day in America/Chicago", report.Interval, report.Period.Timezone)
+	}
+	if len(report.Series) != 1 {
+		t.Fatalf("got %d series, want 1", len(report.Series))
+	}
+
+	want := []SalesSeriesPoint{
+		{BucketStart: period.StartDate, UnitsSold: 1, Revenue: 20000, AveragePrice: 20000},
+		{BucketStart: time.Date(2026, time.March, 8, 0, 0, 0, 0, chicago), UnitsSold: 2, Revenue: 40000.5, AveragePrice: 20000.25},
+		{BucketStart: period.EndDate, UnitsSold: 1, Revenue: 25000, AveragePrice: 25000},
+	}
+	points := report.Series[0].Points
+	if len(points) != len(want) {
+		t.Fatalf("got %d points, want %d", len(points), len(want))
+	}
+	for i, point := range points {
+		if !point.BucketStart.Equal(want[i].BucketStart) || point.UnitsSold != want[i].UnitsSold ||
+			point.Revenue != want[i].Revenue || point.AveragePrice != want[i].AveragePrice {
+			t.Errorf("point %d = %+v, want %+v", i, point, want[i])
+		}
+	}
+	if day := points[2].BucketStart.Sub(points[1].BucketStart); day != 23*time.Hour {
+		t.Errorf("the day clocks went forward lasted %v, want 23h", day)
+	}
+}
+
+func TestGetSalesTimeSeriesGivesEveryGroupEveryBucket(t *testing.T) {
+	day := func(d int) int64 { return time.Date(2026, time.March, d, 15, 0, 0, 0, time.UTC).Unix() }
+	repo := &summarySaleRepository{timeSeries: []mysql.SalesTimeSeriesRow{
+		{Slot_Start: day(2), Group_Key: "Honda", Group_Label: "Honda", Units_Sold: 1, Total_Revenue: 30000},
+		{Slot_Start: day(20), Group_Key: "Toyota", Group_Label: "Toyota", Units_Sold: 2, Total_Revenue: 50000},
+		{Slot_Start: day(24), Group_Key: "Honda", Group_Label: "Honda", Units_Sold: 1, Total_Revenue: 20000},
+	}}
+	s := &service{sales_repo: repo}
+
+	report, err := s.GetSalesTimeSeries(context.Background(), march2026, mysql.TimeBucketWeek, mysql.SalesGroupingMake)
+	if err != nil {
+		t.Fatalf("GetSalesTimeSeries returned error: %v", err)
+	}
+
+	// 1 March 2026 is a Sunday, so the first week starts in February
+	units := map[string][]int{}
+	for _, series := range report.Series {
+		for _, point := range series.Points {
+			units[series.Key] = append(units[series.Key], point.UnitsSold)
+		}
+		if first := series.Points[0].BucketStart; !first.Equal(time.Date(2026, time.February, 23, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("%s starts at %s, want Monday 23 February", series.Key, first)
+		}
+	}
+	wantHonda, wantToyota := []int{0, 1, 0, 0, 1, 0}, []int{0, 0, 0, 2, 0, 0}
+	if !slices.Equal(units["Honda"], wantHonda) || !slices.Equal(units["Toyota"], wantToyota) {
+		t.Errorf("weekly units = %v, want Honda %v and Toyota %v", units, wantHonda, wantToyota)
+	}
+}
+
+func TestGetSalesTimeSeriesRefusesBadOptions(t *testing.T) {
+	s := &service{sales_repo: &summarySaleRepository{}}
+	ctx := context.Background()
+
+	if _, err := s.GetSalesTimeSeries(ctx, march2026, "hour", mysql.SalesGroupingNone); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("hourly interval error = %v, want ErrInvalidQuery", err)
+	}
+	if _, err := s.GetSalesTimeSeries(ctx, march2026, mysql.TimeBucketDay, "customer"); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("group by customer error = %v, want ErrInvalidQuery", err)
+	}
+
+	decade := ReportPeriod{StartDate: time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC), EndDate: march2026.EndDate}
+	if _, err := s.GetSalesTimeSeries(ctx, decade, mysql.TimeBucketDay, mysql.SalesGroupingNone); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("daily series over a decade error = %v, want ErrInvalidQuery", err)
+	}
+	if _, err := s.GetSalesTimeSeries(ctx, decade, mysql.TimeBucketMonth, mysql.SalesGroupingNone); err != nil {
+		t.Errorf("monthly series over a decade returned error: %v", err)
+	}
+}
+
+// inventoryVehicleRepository answers the inventory report queries with canned
+// rows and records what it was asked for
+type inventoryVehicleRepository struct {
+	mysqlrepo.VehicleRepository
+	summaries []mysql.InventorySummary
+	turnover  []mysql.ModelTurnover
+	topValue  []mysql.Vehicle
+
+	turnoverSince time.Time
+	listOptions   mysql.ListOptions
+}
+
+func (r *inventoryVehicleRepository) SummarizeInventory(ctx context.Context, asOf time.Time) ([]mysql.InventorySummary, error) {
+	return r.summaries, nil
+}
+
+func (r *inventoryVehicleRepository) TurnoverByModel(ctx context.Context, since time.Time) ([]mysql.ModelTurnover, error) {
+	r.turnoverSince = since
+	return r.turnover, nil
+}
+
+func (r *inventoryVehicleRepository) List(ctx context.Context, filter mysql.VehicleFilter, opts mysql.ListOptions) ([]mysql.Vehicle, error) {
+	r.listOptions = opts
+	return r.topValue, nil
+}
+
+func TestGetInventoryReport(t *testing.T) {
+	relisted := testVehicle(0)
+	relisted.Created_At = time.Now().AddDate(-2, 0, 0)
+	relisted.Listed_At = time.Now().AddDate(0, 0, -12).Add(-time.Hour)
+
+	repo := &inventoryVehicleRepository{
+		summaries: []mysql.InventorySummary{
+			{Make: "Honda", Status: mysql.VehicleStatusAvailable, Age_Bucket: mysql.InventoryAge0To30, Units: 2, Total_Value: 44000, Total_Days_On_Lot: 20},
+			{Make: "Honda", Status: mysql.VehicleStatusReserved, Age_Bucket: mysql.InventoryAgeOver90, Units: 1, Total_Value: 30000, Total_Days_On_Lot: 120},
+			{Make: "Toyota", Status: mysql.VehicleStatusAvailable, Age_Bucket: mysql.InventoryAge31To60, Units: 1, Total_Value: 26000, Total_Days_On_Lot: 40},
+			{Make: "Toyota", Status: mysql.VehicleStatusSold, Age_Bucket: mysql.InventoryAgeOver90, Units: 5, Total_Value: 150000, Total_Days_On_Lot: 900},
+		},
+		turnover: []mysql.ModelTurnover{
+			{Make: "Honda", Model: "Civic", Units_On_Lot: 3, Units_Sold: 1, Average_Days_To_Sell: 20},
+			{Make: "Toyota", Model: "Camry", Units_On_Lot: 0, Units_Sold: 2, Average_Days_To_Sell: 35},
+		},
+		topValue: []mysql.Vehicle{relisted},
+	}
+	s := &service{vehicle_repo: repo}
+
+	report, err := s.GetInventoryReport(context.Background(), InventoryReportOptions{TurnWindowDays: 30})
+	if err != nil {
+		t.Fatalf("GetInventoryReport returned error: %v", err)
+	}
+
+	// sold vehicles are only counted by status
+	if report.TotalVehicles != 4 || report.AverageDaysOnLot != 45 {
+		t.Errorf("%d vehicles averaging %v days on the lot, want 4 averaging 45", report.TotalVehicles, report.AverageDaysOnLot)
+	}
+	if report.VehiclesByStatus["sold"] != 5 || report.ValueByMake["Toyota"] != 26000 {
+		t.Errorf("%d sold and Toyota value %v, want 5 and 26000", report.VehiclesByStatus["sold"], report.ValueByMake["Toyota"])
+	}
+
+	wantAging := []InventoryAgingBucket{
+		{Bucket: mysql.InventoryAge0To30, Units: 2, TotalValue: 44000},
+		{Bucket: mysql.InventoryAge31To60, Units: 1, TotalValue: 26000},
+		{Bucket: mysql.InventoryAge61To90},
+		{Bucket: mysql.InventoryAgeOver90, Units: 1, TotalValue: 30000},
+	}
+	if !slices.Equal(report.Aging, wantAging) {
+		t.Errorf("aging = %+v, want %+v", report.Aging, wantAging)
+	}
+
+	if got := report.TurnRates; got[0].TurnRate != 0.25 || got[1].TurnRate != 1 {
+		t.Errorf("turn rates = %v and %v, want 0.25 and 1", got[0].TurnRate, got[1].TurnRate)
+	}
+	if want := report.AsOf.AddDate(0, 0, -30); !repo.turnoverSince.Equal(want) {
+		t.Errorf("turnover counted since %v, want the 30 day window from %v", repo.turnoverSince, want)
+	}
+
+	if repo.listOptions.Limit != defaultInventoryTopN || repo.listOptions.Sort != "-price" {
+		t.Errorf("top vehicles listed with %+v, want the %d most expensive", repo.listOptions, defaultInventoryTopN)
+	}
+	if days := report.TopValueVehicles[0].DaysOnLot; days != 12 {
+		t.Errorf("relisted vehicle has been on the lot %d days, want the 12 since it was relisted", days)
+	}
+}
+
+func TestInventoryReportOptions(t *testing.T) {
+	options, err := InventoryReportOptions{}.withDefaults()
+	if err != nil || options.TopN != defaultInventoryTopN || options.TurnWindowDays != defaultTurnWindowDays {
+		t.Errorf("defaults = %+v, %v", options, err)
+	}
+
+	for _, options := range []InventoryReportOptions{
+		{TopN: -1},
+		{TopN: maxInventoryTopN + 1},
+		{TurnWindowDays: -30},
+		{TurnWindowDays: maxTurnWindowDays + 1},
+	} {
+		if _, err := options.withDefaults(); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("withDefaults(%+v) = %v, want ErrInvalidQuery", options, err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package dealership
+
+import (
+	"api-servers/internal/models/mysql"
+	mysqlrepo "api-servers/internal/repository/mysql"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const reservationHoldDuration = 48 * time.Hour
+
+func (s *service) ReserveVehicle(ctx context.Context, vehicleID, customerID string) (*mysql.Reservation, error) {
+	now := time.Now()
+	reservation := mysql.Reservation{
+		ID:          uuid.New().String(),
+		Vehicle_ID:  vehicleID,
+		Customer_ID: customerID,
+		Status:      mysql.ReservationStatusActive,
+		Reserved_At: now,
+		Expires_At:  now.Add(reservationHoldDuration),
+		Created_At:  now,
+		Updated_At:  now,
+	}
+
+	err := s.transactor.WithTx(ctx, func(repos mysqlrepo.Repositories) error {
+		customer, err := repos.Customers.GetByID(ctx, customerID)
+		if err != nil {
+			return fmt.Errorf("customer %s not found for vehicle reservation: %w", customerID, err)
+		}
+		if customer.Deleted_At != nil {
+			return fmt.Errorf("customer %s has been deleted: %w", customerID, ErrNotFound)
+		}
+
+		err = repos.Vehicles.UpdateStatus(ctx, vehicleID, mysql.VehicleStatusReserved, mysql.VehicleStatusAvailable)
+		if errors.Is(err, mysqlrepo.ErrConflict) {
+			return &ConflictError{Resource: "vehicle", ID: vehicleID, Err: err}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to reserve vehicle %s for customer %s: %w", vehicleID, customerID, err)
+		}
+
+		return repos.Reservations.Create(ctx, reservation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &reservation, nil
+}
+
+func (s *service) ReleaseVehicleReservation(ctx context.Context, vehicleID string) (*mysql.Reservation, error) {
+	var reservation mysql.Reservation
+
+	err := s.transactor.WithTx(ctx, func(repos mysqlrepo.Repositories) error {
+		var err error
+		reservation, err = repos.Reservations.GetActiveByVehicleId(ctx, vehicleID)
+		if err != nil {
+			return fmt.Errorf("vehicle %s has no active reservation: %w", vehicleID, err)
+		}
+
+		return s.endReservation(ctx, repos, &reservation, mysql.ReservationStatusReleased)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &reservation, nil
+}
+
+func (s *service) GetCustomerReservations(ctx context.Context, customerID string) ([]mysql.Reservation, error) {
+	reservations, err := s.reservation_repo.GetByCustomerId(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve reservations for customer %s: %w", customerID, err)
+	}
+	return reservations, nil
+}
+
+func (s *service) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	expired, err := s.reservation_repo.GetExpired(ctx, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired reservations: %w", err)
+	}
+
+	// one reservation failing doesn't hold up the rest of the sweep
+	released, failed := 0, 0
+	for _, reservation := range expired {
+		if err := s.expireReservation(ctx, reservation); err != nil {
+			log.Printf("Error releasing expired reservation %s: %v", reservation.ID, err)
+			failed++
+			continue
+		}
+		released++
+	}
+
+	if failed > 0 {
+		return released, fmt.Errorf("failed to release %d of %d expired reservations", failed, len(expired))
+	}
+	return released, nil
+}
+
+// RunReservationSweeper releases expired reservations every interval until ctx is done
+func RunReservationSweeper(ctx context.Context, dealershipService DealershipService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := dealershipService.ReleaseExpiredReservations(ctx)
+			if err != nil {
+				log.Printf("Error releasing expired reservations: %v", err)
+			}
+			if released > 0 {
+				log.Printf("Released %d expired vehicle reservations", released)
+			}
+		}
+	}
+}
+
+// reservation helper functions
+
+// endReservation closes an active reservation and puts the vehicle back on the lot
+func (s *service) endReservation(ctx context.Context, repos mysqlrepo.Repositories, reservation *mysql.Reservation, status mysql.ReservationStatus) error {
+	now := time.Now()
+	reservation.Status = status
+	reservation.Released_At = &now
+	reservation.Updated_At = now
+
+	err := repos.Reservations.Update(ctx, reservation.ID, *reservation)
+	if err != nil {
+		return err
+	}
+
+	err = repos.Vehicles.UpdateStatus(ctx, reservation.Vehicle_ID, mysql.VehicleStatusAvailable, mysql.VehicleStatusReserved)
+	if errors.Is(err, mysqlrepo.ErrConflict) {
+		return &ConflictError{Resource: "vehicle", ID: reservation.Vehicle_ID, Err: err}
+	}
+	return err
+}
+
+// expireReservation ends an expired reservation. If the vehicle is no longer
+// reserved, e.g. it was sold or deleted in the meantime, the reservation is
+// still closed and the vehicle is left as it is.
+func (s *service) expireReservation(ctx context.Context, reservation mysql.Reservation) error {
+	err := s.transactor.WithTx(ctx, func(repos mysqlrepo.Repositories) error {
+		return s.endReservation(ctx, repos, &reservation, mysql.ReservationStatusExpired)
+	})
+	if !errors.Is(err, mysqlrepo.ErrConflict) {
+		return err
+	}
+
+	log.Printf("Vehicle %s is no longer reserved, closing expired reservation %s without releasing it: %v", reservation.Vehicle_ID, reservation.ID, err)
+	return s.reservation_repo.Update(ctx, reservation.ID, reservation)
+}
+
+// claimReservation checks that a reserved vehicle is held by customerID. Vehicles
+// reserved without a reservation record are open to any customer.
+func (s *service) claimReservation(ctx context.Context, reservations mysqlrepo.ReservationRepository, vehicleID, customerID string) (*mysql.Reservation, error) {
+	reservation, err := reservations.GetActiveByVehicleId(ctx, vehicleID)
+	if errors.Is(err, mysqlrepo.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check reservation for vehicle %s: %w", vehicleID, err)
+	}
+
+	if reservation.Customer_ID != customerID {
+		return nil, fmt.Errorf("vehicle %s is reserved for another customer: %w", vehicleID, ErrInvalidState)
+	}
+	return &reservation, nil
+}
@@ -2,124 +2,626 @@ package dealership
 
 import (
 	"api-servers/internal/models/mysql"
+	"api-servers/internal/models/redis"
+	mysqlrepo "api-servers/internal/repository/mysql"
+	redisrepo "api-servers/internal/repository/redis"
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const salesSessionDuration = 2 * time.Hour
+
+// financingTerms are the loan terms on offer, each priced as a spread over the customer's base rate
+var financingTerms = []struct {
+	term       FinancingTerm
+	rateSpread float64
+}{
+	{FinancingTerm36Months, 0},
+	{FinancingTerm48Months, 0.5},
+	{FinancingTerm60Months, 1.0},
+	{FinancingTerm72Months, 1.5},
+}
+
 func (s *service) StartSalesProcess(ctx context.Context, customerID, vehicleID, salespersonID string) (*SalesSession, error) {
-	customer, err := s.customer_repo.GetByID(customerID)
+	customer, err := s.getCustomer(ctx, customerID)
 	if err != nil {
-		return nil, fmt.Errorf("customer not found: %w", err)
+		return nil, err
 	}
 
-	vehicle, err := s.vehicle_repo.GetByID(vehicleID)
+	vehicle, err := s.getVehicle(ctx, vehicleID)
 	if err != nil {
-		return nil, fmt.Errorf("vehicle not found: %w", err)
+		return nil, err
 	}
 
-	salesperson, err := s.salesperson_repo.GetByID(salespersonID)
+	salesperson, err := s.salesperson_repo.GetByID(ctx, salespersonID)
 	if err != nil {
 		return nil, fmt.Errorf("salesperson not found: %w", err)
 	}
 
+	if salesperson.Status == mysql.SalesPersonStatusTerminated {
+		return nil, fmt.Errorf("salesperson %s is terminated and cannot start sales: %w", salespersonID, ErrInvalidState)
+	}
+
 	if vehicle.Status != mysql.VehicleStatusAvailable && vehicle.Status != mysql.VehicleStatusReserved {
 		return nil, fmt.Errorf("vehicle is not available for sale")
 	}
 
-	session := &SalesSession{
-		SessionID:   uuid.New().String(),
+	if vehicle.Status == mysql.VehicleStatusReserved {
+		_, err = s.claimReservation(ctx, s.reservation_repo, vehicleID, customerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	stored := redis.SalesSession{
+		ID:             uuid.New().String(),
+		Customer_ID:    customer.ID,
+		Vehicle_ID:     vehicle.ID,
+		Salesperson_ID: salesperson.ID,
+		Status:         string(SalesSessionStatusActive),
+		Started_At:     now,
+		Expires_At:     now.Add(salesSessionDuration),
+		Updated_At:     now,
+	}
+
+	err = s.sales_session_repo.Create(ctx, stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store sales session: %w", err)
+	}
+
+	return &SalesSession{
+		SessionID:   stored.ID,
 		Customer:    customer,
 		Vehicle:     vehicle,
 		Salesperson: salesperson,
-		StartedAt:   time.Now(),
+		StartedAt:   stored.Started_At,
+		ExpiresAt:   stored.Expires_At,
 		Status:      SalesSessionStatusActive,
+	}, nil
+}
+
+func (s *service) GetSalesSession(ctx context.Context, sessionID string) (*SalesSession, error) {
+	stored, err := s.loadSalesSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
 	}
 
+	session, err := s.hydrateSalesSession(ctx, stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sales session %s: %w", sessionID, err)
+	}
 	return session, nil
 }
 
-func (s *service) CalculateFinancingOperations(ctx context.Context, vehicleID string, downPayment float64, customerID string) (FinancingOptions, error) {
-	vehicle, err := s.vehicle_repo.GetByID(vehicleID)
+func (s *service) CancelSalesSession(ctx context.Context, sessionID string) (*SalesSession, error) {
+	stored, err := s.loadSalesSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if SalesSessionStatus(stored.Status) != SalesSessionStatusActive {
+		return nil, fmt.Errorf("sales session %s is %s and cannot be cancelled: %w", sessionID, stored.Status, ErrInvalidState)
+	}
+
+	stored.Status = string(SalesSessionStatusCancelled)
+	stored.Updated_At = time.Now()
+
+	err = s.sales_session_repo.Update(ctx, sessionID, stored)
 	if err != nil {
-		return FinancingOptions{}, fmt.Errorf("vehicle not found: %w", err)
+		return nil, fmt.Errorf("failed to cancel sales session %s: %w", sessionID, err)
 	}
 
-	creditDecision, err := s.ProcessCreditApplication(ctx, customerID)
+	session, err := s.hydrateSalesSession(ctx, stored)
 	if err != nil {
-		return FinancingOptions{}, fmt.Errorf("failed to get credit decision: %w", err)
+		return nil, fmt.Errorf("failed to load sales session %s: %w", sessionID, err)
 	}
+	return session, nil
+}
 
-	if !creditDecision.Approved {
-		return FinancingOptions{}, fmt.Errorf("customer not approved for financing")
+func (s *service) GetActiveSalesSessions(ctx context.Context, salespersonID string) ([]SalesSession, error) {
+	storedSessions, err := s.sales_session_repo.GetBySalespersonID(ctx, salespersonID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales sessions for salesperson %s: %w", salespersonID, err)
 	}
 
-	loanAmount := vehicle.Price - downPayment
-	if loanAmount > creditDecision.CreditLimit {
-		return FinancingOptions{}, fmt.Errorf("loan amount exceeds credit limit")
+	sessions := []SalesSession{}
+	for _, stored := range storedSessions {
+		stored, err = s.expireSalesSession(ctx, stored)
+		if err != nil {
+			return nil, err
+		}
+		if SalesSessionStatus(stored.Status) != SalesSessionStatusActive {
+			continue
+		}
+
+		session, err := s.hydrateSalesSession(ctx, stored)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load sales session %s: %w", stored.ID, err)
+		}
+		sessions = append(sessions, *session)
 	}
 
-	financingOptions := []FinancingOption{
-		s.calculateFinancingOption(loanAmount, creditDecision.InterestRate, int(FinancingTerm36Months)),
-		s.calculateFinancingOption(loanAmount, creditDecision.InterestRate+0.5, int(FinancingTerm48Months)),
-		s.calculateFinancingOption(loanAmount, creditDecision.InterestRate+1.0, int(FinancingTerm60Months)),
-		s.calculateFinancingOption(loanAmount, creditDecision.InterestRate+1.5, int(FinancingTerm72Months)),
+	return sessions, nil
+}
+
+func (s *service) CalculateFinancingOperations(ctx context.Context, vehicleID string, downPayment float64, customerID, tradeInID string) (FinancingOptions, error) {
+	quote, err := s.quoteFinancing(ctx, vehicleID, downPayment, customerID, tradeInID)
+	if err != nil {
+		return FinancingOptions{}, err
+	}
+
+	var financingOptions []FinancingOption
+	for _, offered := range financingTerms {
+		financingOptions = append(financingOptions, s.calculateFinancingOption(quote.loanAmount, quote.creditDecision.InterestRate+offered.rateSpread, int(offered.term)))
 	}
 
 	return FinancingOptions{
-		CustomerID: customerID,
-		VehicleID:  vehicleID,
-		Options:    financingOptions,
+		CustomerID:    customerID,
+		VehicleID:     vehicleID,
+		TradeInCredit: quote.pricing.TradeInCredit,
+		Pricing:       quote.pricing,
+		Options:       financingOptions,
+	}, nil
+}
+
+// financingQuote is what a financing quote is priced from
+type financingQuote struct {
+	creditDecision *CreditDecision
+	pricing        OutTheDoorPrice
+	loanAmount     float64
+}
+
+// quoteFinancing works out how much a customer would borrow for a vehicle,
+// taxes and fees included, and checks they're approved for it
+func (s *service) quoteFinancing(ctx context.Context, vehicleID string, downPayment float64, customerID, tradeInID string) (financingQuote, error) {
+	vehicle, err := s.vehicle_repo.GetByID(ctx, vehicleID)
+	if err != nil {
+		return financingQuote{}, fmt.Errorf("vehicle not found: %w", err)
+	}
+
+	customer, err := s.getCustomer(ctx, customerID)
+	if err != nil {
+		return financingQuote{}, err
+	}
+
+	creditDecision, err := s.currentCreditDecision(ctx, customer)
+	if err != nil {
+		return financingQuote{}, fmt.Errorf("failed to get credit decision: %w", err)
+	}
+
+	if !creditDecision.Approved {
+		return financingQuote{}, fmt.Errorf("customer not approved for financing: %w", ErrInvalidInput)
+	}
+
+	tradeIn, err := s.tradeInCredit(ctx, tradeInID, customerID)
+	if err != nil {
+		return financingQuote{}, err
+	}
+	tradeInCredit := tradeInOffer(tradeIn)
+	pricing := s.priceOutTheDoor(customer, vehicle, 0, tradeInCredit)
+	if err := validateDownPayment(pricing.OutTheDoorPrice, downPayment, tradeInCredit); err != nil {
+		return financingQuote{}, err
+	}
+
+	loanAmount := roundCents(pricing.OutTheDoorPrice - downPayment - tradeInCredit)
+	if loanAmount > creditDecision.CreditLimit {
+		return financingQuote{}, fmt.Errorf("loan amount exceeds credit limit: %w", ErrInvalidInput)
+	}
+
+	return financingQuote{
+		creditDecision: creditDecision,
+		pricing:        pricing,
+		loanAmount:     loanAmount,
 	}, nil
 }
 
 func (s *service) ProcessVehicleSale(ctx context.Context, saleRequest SaleRequest) (*SaleResult, error) {
-	sale := &mysql.Sale{
-		ID:             uuid.New().String(),
-		Sale_Date:      time.Now(),
-		Sale_Price:     0,
-		Down_Payment:   saleRequest.DownPayment,
-		Finance_Term:   saleRequest.FinancingTerm,
-		Payment_Method: saleRequest.PaymentMethod,
-		Status:         mysql.SaleStatusCompleted,
-		Notes:          saleRequest.Notes,
-		Created_At:     time.Now(),
-		Updated_At:     time.Now(),
+	stored, err := s.loadSalesSession(ctx, saleRequest.SessionID)
+	if err != nil {
+		return nil, err
 	}
 
-	err := s.sales_repo.Create(*sale)
+	if SalesSessionStatus(stored.Status) != SalesSessionStatusActive {
+		return nil, fmt.Errorf("sales session %s is %s: %w", saleRequest.SessionID, stored.Status, ErrInvalidState)
+	}
+
+	session, err := s.hydrateSalesSession(ctx, stored)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create sale: %w", err)
+		return nil, fmt.Errorf("failed to load sales session %s: %w", saleRequest.SessionID, err)
+	}
+
+	// the salesperson may have been terminated since the session started
+	if session.Salesperson.Status == mysql.SalesPersonStatusTerminated {
+		return nil, fmt.Errorf("salesperson %s is terminated and cannot complete sales: %w", session.Salesperson.ID, ErrInvalidState)
+	}
+
+	vehicle := session.Vehicle
+	if vehicle.Status != mysql.VehicleStatusAvailable && vehicle.Status != mysql.VehicleStatusReserved {
+		return nil, fmt.Errorf("vehicle %s is %s and not available for sale: %w", vehicle.ID, vehicle.Status, ErrInvalidState)
+	}
+
+	tradeIn, err := s.tradeInCredit(ctx, saleRequest.TradeInID, session.Customer.ID)
+	if err != nil {
+		return nil, err
+	}
+	tradeInCredit := tradeInOffer(tradeIn)
+
+	if err := validateDiscount(vehicle.Price, saleRequest.Discount); err != nil {
+		return nil, err
+	}
+	pricing := s.priceOutTheDoor(session.Customer, vehicle, saleRequest.Discount, tradeInCredit)
+	salePrice := pricing.SalePrice
+	if err := validateDownPayment(pricing.OutTheDoorPrice, saleRequest.DownPayment, tradeInCredit); err != nil {
+		return nil, err
+	}
+
+	var creditDecision *CreditDecision
+	if saleRequest.PaymentMethod == mysql.PaymentMethodFinance || saleRequest.PaymentMethod == mysql.PaymentMethodLease {
+		creditDecision, err = s.currentCreditDecision(ctx, session.Customer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get credit decision: %w", err)
+		}
+	}
+
+	var financingDetails FinancingDetails
+	var leaseDetails *LeaseQuote
+	downPayment := saleRequest.DownPayment
+
+	switch saleRequest.PaymentMethod {
+	case mysql.PaymentMethodCash:
+		downPayment = roundCents(pricing.OutTheDoorPrice - tradeInCredit)
+	case mysql.PaymentMethodFinance:
+		financingDetails, err = s.financeSale(creditDecision, roundCents(pricing.OutTheDoorPrice-downPayment-tradeInCredit), saleRequest.FinancingTerm)
+		if err != nil {
+			return nil, err
+		}
+	case mysql.PaymentMethodLease:
+		leaseDetails, err = quoteLease(vehicle.Price, salePrice, LeaseQuoteRequest{
+			CustomerID:    session.Customer.ID,
+			VehicleID:     vehicle.ID,
+			DownPayment:   downPayment,
+			TermMonths:    saleRequest.FinancingTerm,
+			AnnualMileage: saleRequest.AnnualMileage,
+			TradeInID:     saleRequest.TradeInID,
+		}, tradeInCredit, pricing.TotalTaxesAndFees, creditDecision)
+		if err != nil {
+			return nil, err
+		}
+		financingDetails = FinancingDetails{
+			LoanAmount:     leaseDetails.AdjustedCapCost,
+			InterestRate:   leaseDetails.EquivalentAPR,
+			MonthlyPayment: leaseDetails.MonthlyPayment,
+			TermMonths:     leaseDetails.TermMonths,
+		}
+	default:
+		return nil, fmt.Errorf("unknown payment method %q: %w", saleRequest.PaymentMethod, ErrInvalidInput)
+	}
+
+	// sales the approval policy flags wait for a manager, holding the vehicle
+	// in pending rather than selling it
+	approvalReasons := s.approval_policy.reasons(vehicle.Price, saleRequest.Discount, creditDecision)
+	saleStatus, vehicleStatus := mysql.SaleStatusCompleted, mysql.VehicleStatusSold
+	if len(approvalReasons) > 0 {
+		saleStatus, vehicleStatus = mysql.SaleStatusPending, mysql.VehicleStatusPending
+	}
+
+	now := time.Now()
+	sale := mysql.Sale{
+		ID:                 uuid.New().String(),
+		Vehicle_ID:         vehicle.ID,
+		Customer_ID:        session.Customer.ID,
+		Salesperson_ID:     session.Salesperson.ID,
+		Sale_Date:          now,
+		Sale_Price:         salePrice,
+		Discount:           saleRequest.Discount,
+		Sales_Tax:          pricing.SalesTax,
+		Doc_Fee:            pricing.DocFee,
+		Registration_Fee:   pricing.RegistrationFee,
+		Title_Fee:          pricing.TitleFee,
+		Out_The_Door_Price: pricing.OutTheDoorPrice,
+		Down_Payment:       downPayment,
+		Trade_In_Credit:    tradeInCredit,
+		Finance_Amount:     financingDetails.LoanAmount,
+		Finance_Term:       financingDetails.TermMonths,
+		Interest_Rate:      financingDetails.InterestRate,
+		Monthly_Payment:    financingDetails.MonthlyPayment,
+		Payment_Method:     saleRequest.PaymentMethod,
+		Commission:         roundCents(salePrice * session.Salesperson.Commission),
+		Status:             saleStatus,
+		Notes:              saleRequest.Notes,
+		Approval_Reason:    strings.Join(approvalReasons, "; "),
+		Created_At:         now,
+		Updated_At:         now,
+	}
+	if sale.Status == mysql.SaleStatusCompleted {
+		sale.Completed_At = &now
+	}
+	if leaseDetails != nil {
+		sale.Residual_Value = leaseDetails.ResidualValue
+		sale.Money_Factor = leaseDetails.MoneyFactor
+		sale.Annual_Mileage = leaseDetails.AnnualMileage
+	}
+
+	// the contract is rendered up front so a rendering failure stops the sale
+	// before anything is written
+	var contract *mysql.SaleContract
+	if sale.Status == mysql.SaleStatusCompleted {
+		generated, err := s.newSaleContract(contractParties{
+			Sale:        sale,
+			Customer:    session.Customer,
+			Vehicle:     vehicle,
+			Salesperson: session.Salesperson,
+			TradeIn:     tradeIn,
+		}, now)
+		if err != nil {
+			return nil, err
+		}
+		contract = &generated
 	}
 
-	contract := SalesContract{
-		ContractID:  uuid.New().String(),
-		SaleID:      sale.ID,
-		Terms:       ContractTermsStandard,
-		GeneratedAt: time.Now(),
+	var tradedVehicle *mysql.Vehicle
+	err = s.transactor.WithTx(ctx, func(repos mysqlrepo.Repositories) error {
+		reservation, err := s.claimReservation(ctx, repos.Reservations, vehicle.ID, sale.Customer_ID)
+		if err != nil {
+			return err
+		}
+		if reservation != nil {
+			reservation.Status = mysql.ReservationStatusCompleted
+			reservation.Updated_At = now
+			if err := repos.Reservations.Update(ctx, reservation.ID, *reservation); err != nil {
+				return err
+			}
+		}
+
+		if err := repos.Sales.Create(ctx, sale); err != nil {
+			return err
+		}
+		err = repos.Vehicles.UpdateStatus(ctx, vehicle.ID, vehicleStatus, mysql.VehicleStatusAvailable, mysql.VehicleStatusReserved)
+		if errors.Is(err, mysqlrepo.ErrConflict) {
+			return &ConflictError{Resource: "vehicle", ID: vehicle.ID, Err: err}
+		}
+		if err != nil {
+			return err
+		}
+
+		if tradeIn != nil {
+			if err := applyTradeIn(ctx, repos, tradeIn, sale.ID, now); err != nil {
+				return err
+			}
+		}
+		if contract == nil {
+			return nil
+		}
+		if tradeIn != nil {
+			traded, err := stockTradeIn(ctx, repos, tradeIn, now)
+			if err != nil {
+				return err
+			}
+			tradedVehicle = &traded
+		}
+		return repos.Contracts.Create(ctx, *contract)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sale: %w", err)
 	}
 
-	financingDetails := FinancingDetails{
-		LoanAmount:     saleRequest.DownPayment,
-		InterestRate:   5.0,
-		MonthlyPayment: 500.0,
-		TermMonths:     saleRequest.FinancingTerm,
+	stored.Status = string(SalesSessionStatusCompleted)
+	stored.Updated_At = now
+	err = s.sales_session_repo.Update(ctx, stored.ID, stored)
+	if err != nil {
+		return nil, fmt.Errorf("sale %s recorded but failed to close sales session %s: %w", sale.ID, stored.ID, err)
 	}
 
 	return &SaleResult{
-		Sale:             *sale,
+		Sale:             sale,
 		Contract:         contract,
 		FinancingDetails: financingDetails,
-		Commission:       1000.0,
+		LeaseDetails:     leaseDetails,
+		TradeInVehicle:   tradedVehicle,
+		Commission:       sale.Commission,
 	}, nil
 }
 
+// CancelSale cancels a sale and unwinds it in one transaction: the vehicle
+// goes back on the lot, the commission is reversed and any trade-in is handed
+// back to the customer. The cash paid is reported as the refund due.
+func (s *service) CancelSale(ctx context.Context, saleID, reason string) (*SaleCancellation, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("a cancellation reason is required: %w", ErrInvalidInput)
+	}
+
+	sale, err := s.sales_repo.GetByID(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	if sale.Status != mysql.SaleStatusPending && sale.Status != mysql.SaleStatusCompleted {
+		return nil, fmt.Errorf("sale %s is %s and cannot be cancelled: %w", saleID, sale.Status, ErrInvalidState)
+	}
+
+	now := time.Now()
+	var returned *mysql.TradeIn
+	err = s.transactor.WithTx(ctx, func(repos mysqlrepo.Repositories) error {
+		returned, err = cancelSale(ctx, repos, sale, reason, now)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel sale %s: %w", saleID, err)
+	}
+
+	// commission is only paid on completed sales
+	var commissionReversed float64
+	if sale.Status == mysql.SaleStatusCompleted {
+		commissionReversed = sale.Commission
+	}
+
+	sale.Status = mysql.SaleStatusCancelled
+	sale.Cancelled_At = &now
+	sale.Cancellation_Reason = reason
+	sale.Updated_At = now
+
+	return &SaleCancellation{
+		Sale:               sale,
+		RefundAmount:       sale.Down_Payment,
+		CommissionReversed: commissionReversed,
+		ReturnedTradeIn:    returned,
+	}, nil
+}
+
+// sales helper functions
+
+// cancelSale cancels the sale and releases what it held: the vehicle goes
+// back to available and any trade-in is returned. It returns the returned
+// trade-in, if there was one.
+func cancelSale(ctx context.Context, repos mysqlrepo.Repositories, sale mysql.Sale, reason string, now time.Time) (*mysql.TradeIn, error) {
+	err := repos.Sales.Cancel(ctx, sale.ID, reason, now)
+	if errors.Is(err, mysqlrepo.ErrConflict) {
+		return nil, &ConflictError{Resource: "sale", ID: sale.ID, Err: err}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return releaseSale(ctx, repos, sale, now)
+}
+
+// releaseSale puts the vehicle of a sale that is being unwound back on the
+// lot and returns any trade-in. sale is the sale as it was before unwinding.
+func releaseSale(ctx context.Context, repos mysqlrepo.Repositories, sale mysql.Sale, now time.Time) (*mysql.TradeIn, error) {
+	// a completed sale sold the vehicle; a pending one is holding it
+	held := mysql.VehicleStatusSold
+	if sale.Status == mysql.SaleStatusPending {
+		held = mysql.VehicleStatusPending
+	}
+	err := repos.Vehicles.UpdateStatus(ctx, sale.Vehicle_ID, mysql.VehicleStatusAvailable, held)
+	if errors.Is(err, mysqlrepo.ErrConflict) {
+		return nil, &ConflictError{Resource: "vehicle", ID: sale.Vehicle_ID, Err: err}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tradeIn, err := repos.TradeIns.GetBySaleId(ctx, sale.ID)
+	if errors.Is(err, mysqlrepo.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := returnTradeIn(ctx, repos, &tradeIn, now); err != nil {
+		return nil, err
+	}
+	return &tradeIn, nil
+}
+
+func (s *service) loadSalesSession(ctx context.Context, sessionID string) (redis.SalesSession, error) {
+	stored, err := s.sales_session_repo.GetByID(ctx, sessionID)
+	if errors.Is(err, redisrepo.ErrNotFound) {
+		return stored, fmt.Errorf("sales session %s: %w", sessionID, ErrNotFound)
+	}
+	if err != nil {
+		return stored, fmt.Errorf("failed to load sales session %s: %w", sessionID, err)
+	}
+	return s.expireSalesSession(ctx, stored)
+}
+
+// expireSalesSession moves an active session past its expiry time to expired
+func (s *service) expireSalesSession(ctx context.Context, stored redis.SalesSession) (redis.SalesSession, error) {
+	if SalesSessionStatus(stored.Status) != SalesSessionStatusActive || time.Now().Before(stored.Expires_At) {
+		return stored, nil
+	}
+
+	stored.Status = string(SalesSessionStatusExpired)
+	stored.Updated_At = time.Now()
+
+	err := s.sales_session_repo.Update(ctx, stored.ID, stored)
+	if err != nil {
+		return stored, fmt.Errorf("failed to expire sales session %s: %w", stored.ID, err)
+	}
+	return stored, nil
+}
+
+func (s *service) hydrateSalesSession(ctx context.Context, stored redis.SalesSession) (*SalesSession, error) {
+	customer, err := s.customer_repo.GetByID(ctx, stored.Customer_ID)
+	if err != nil {
+		return nil, fmt.Errorf("customer not found: %w", err)
+	}
+
+	vehicle, err := s.vehicle_repo.GetByID(ctx, stored.Vehicle_ID)
+	if err != nil {
+		return nil, fmt.Errorf("vehicle not found: %w", err)
+	}
+
+	salesperson, err := s.salesperson_repo.GetByID(ctx, stored.Salesperson_ID)
+	if err != nil {
+		return nil, fmt.Errorf("salesperson not found: %w", err)
+	}
+
+	return &SalesSession{
+		SessionID:   stored.ID,
+		Customer:    customer,
+		Vehicle:     vehicle,
+		Salesperson: salesperson,
+		StartedAt:   stored.Started_At,
+		ExpiresAt:   stored.Expires_At,
+		Status:      SalesSessionStatus(stored.Status),
+	}, nil
+}
+
+func (s *service) financeSale(creditDecision *CreditDecision, loanAmount float64, termMonths int) (FinancingDetails, error) {
+	if loanAmount <= 0 {
+		return FinancingDetails{}, fmt.Errorf("nothing left to finance after the down payment: %w", ErrInvalidInput)
+	}
+
+	if !creditDecision.Approved {
+		return FinancingDetails{}, fmt.Errorf("customer not approved for financing: %w", ErrInvalidInput)
+	}
+
+	if loanAmount > creditDecision.CreditLimit {
+		return FinancingDetails{}, fmt.Errorf("loan amount exceeds credit limit: %w", ErrInvalidInput)
+	}
+
+	for _, offered := range financingTerms {
+		if int(offered.term) != termMonths {
+			continue
+		}
+
+		option := s.calculateFinancingOption(loanAmount, creditDecision.InterestRate+offered.rateSpread, termMonths)
+		return FinancingDetails{
+			LoanAmount:     loanAmount,
+			InterestRate:   option.InterestRate,
+			MonthlyPayment: option.MonthlyPayment,
+			TermMonths:     option.TermMonths,
+		}, nil
+	}
+
+	return FinancingDetails{}, fmt.Errorf("financing term of %d months is not offered: %w", termMonths, ErrInvalidInput)
+}
+
+// validateDownPayment checks that the cash down payment and trade-in credit
+// together don't exceed the price, taxes and fees included
+func validateDownPayment(price, downPayment, tradeInCredit float64) error {
+	if downPayment < 0 || downPayment+tradeInCredit > price {
+		return fmt.Errorf("down payment plus trade-in credit must be between 0 and the sale price of %.2f: %w", price, ErrInvalidInput)
+	}
+	return nil
+}
+
+func tradeInOffer(tradeIn *mysql.TradeIn) float64 {
+	if tradeIn == nil {
+		return 0
+	}
+	return tradeIn.Offer_Amount
+}
+
 func (s *service) calculateFinancingOption(loanAmount float64, annualRate float64, termMonths int) FinancingOption {
-	monthlyRate := annualRate / 100 / 12
-	monthlyPayment := loanAmount * (monthlyRate * math.Pow(1+monthlyRate, float64(termMonths))) / (math.Pow(1+monthlyRate, float64(termMonths)) - 1)
+	monthlyPayment := loanPayment(loanAmount, annualRate, termMonths)
 	totalCost := monthlyPayment * float64(termMonths)
 
 	return FinancingOption{
@@ -129,3 +631,15 @@ func (s *service) calculateFinancingOption(loanAmount float64, annualRate float6
 		TotalCost:      totalCost,
 	}
 }
+
+// loanPayment is the level monthly payment that repays loanAmount over
+// termMonths. At 0% APR the annuity formula divides by zero, so the loan is
+// simply split evenly.
+func loanPayment(loanAmount float64, annualRate float64, termMonths int) float64 {
+	monthlyRate := annualRate / 100 / 12
+	if monthlyRate == 0 {
+		return loanAmount / float64(termMonths)
+	}
+	growth := math.Pow(1+monthlyRate, float64(termMonths))
+	return loanAmount * (monthlyRate * growth) / (growth - 1)
+}
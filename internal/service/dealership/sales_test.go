@@ -0,0 +1,95 @@
+package dealership
+
+import (
+	"api-servers/internal/models/redis"
+	redisrepo "api-servers/internal/repository/redis"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// memorySalesSessionRepository keeps sales sessions in memory. Like the Redis
+// repository it only updates sessions that are still stored.
+type memorySalesSessionRepository struct {
+	redisrepo.SalesSessionRepository
+	sessions map[string]redis.SalesSession
+}
+
+func (r *memorySalesSessionRepository) GetByID(ctx context.Context, id string) (redis.SalesSession, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return session, fmt.Errorf("sales session with id %s not found: %w", id, redisrepo.ErrNotFound)
+	}
+	return session, nil
+}
+
+func (r *memorySalesSessionRepository) Update(ctx context.Context, id string, session redis.SalesSession) error {
+	if _, ok := r.sessions[id]; !ok {
+		return fmt.Errorf("sales session with id %s not found for update: %w", id, redisrepo.ErrNotFound)
+	}
+	r.sessions[id] = session
+	return nil
+}
+
+func storedSalesSession(status SalesSessionStatus, expiresAt time.Time) *memorySalesSessionRepository {
+	return &memorySalesSessionRepository{sessions: map[string]redis.SalesSession{
+		"session-1": {
+			ID: "session-1", Customer_ID: "customer-1", Vehicle_ID: "vehicle-1", Salesperson_ID: "salesperson-1",
+			Status: string(status), Started_At: expiresAt.Add(-salesSessionDuration), Expires_At: expiresAt,
+		},
+	}}
+}
+
+func TestLoadSalesSessionExpiresSessionsPastTheirExpiry(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     SalesSessionStatus
+		expiresAt  time.Time
+		wantStatus SalesSessionStatus
+	}{
+		{name: "active and in time", status: SalesSessionStatusActive, expiresAt: time.Now().Add(time.Hour), wantStatus: SalesSessionStatusActive},
+		{name: "active past expiry", status: SalesSessionStatusActive, expiresAt: time.Now().Add(-time.Minute), wantStatus: SalesSessionStatusExpired},
+		{name: "completed past expiry stays completed", status: SalesSessionStatusCompleted, expiresAt: time.Now().Add(-time.Minute), wantStatus: SalesSessionStatusCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := storedSalesSession(tt.status, tt.expiresAt)
+			s := &service{sales_session_repo: repo}
+
+			session, err := s.loadSalesSession(context.Background(), "session-1")
+			if err != nil {
+				t.Fatalf("loadSalesSession returned error: %v", err)
+			}
+			if SalesSessionStatus(session.Status) != tt.wantStatus {
+				t.Errorf("status = %s, want %s", session.Status, tt.wantStatus)
+			}
+			if stored := repo.sessions["session-1"]; SalesSessionStatus(stored.Status) != tt.wantStatus {
+				t.Errorf("stored status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestExpiredSalesSessionsCannotBeUsed(t *testing.T) {
+	s := &service{sales_session_repo: storedSalesSession(SalesSessionStatusActive, time.Now().Add(-time.Minute))}
+	ctx := context.Background()
+
+	if _, err := s.ProcessVehicleSale(ctx, SaleRequest{SessionID: "session-1"}); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("ProcessVehicleSale error = %v, want ErrInvalidState", err)
+	}
+	if _, err := s.CancelSalesSession(ctx, "session-1"); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("CancelSalesSession error = %v, want ErrInvalidState", err)
+	}
+}
+
+func TestMissingSalesSessionIsNotFound(t *testing.T) {
+	s := &service{sales_session_repo: &memorySalesSessionRepository{sessions: map[string]redis.SalesSession{}}}
+
+	_, err := s.CancelSalesSession(context.Background(), "session-1")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("CancelSalesSession error = %v, want ErrNotFound", err)
+	}
+}
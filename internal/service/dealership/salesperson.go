@@ -0,0 +1,142 @@
+package dealership
+
+import (
+	"api-servers/internal/models/mysql"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *service) HireSalesperson(ctx context.Context, input SalespersonInput) (*mysql.Salesperson, error) {
+	if err := validateCommission(input.Commission); err != nil {
+		return nil, err
+	}
+	if input.Role == "" {
+		input.Role = mysql.SalesPersonRoleSales
+	}
+	if err := validateRole(input.Role); err != nil {
+		return nil, err
+	}
+
+	salesperson := mysql.Salesperson{
+		ID:          uuid.New().String(),
+		Employee_ID: input.EmployeeID,
+		First_Name:  input.FirstName,
+		Last_Name:   input.LastName,
+		Email:       input.Email,
+		Phone:       input.Phone,
+		Hire_Date:   input.HireDate,
+		Commission:  input.Commission,
+		Department:  input.Department,
+		Role:        input.Role,
+		Status:      mysql.SalesPersonStatusActive,
+		Created_At:  time.Now(),
+		Updated_At:  time.Now(),
+	}
+
+	err := s.salesperson_repo.Create(ctx, salesperson)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hire salesperson %s %s: %w", input.FirstName, input.LastName, err)
+	}
+
+	return &salesperson, nil
+}
+
+func (s *service) GetSalesperson(ctx context.Context, salespersonID string) (*mysql.Salesperson, error) {
+	salesperson, err := s.salesperson_repo.GetByID(ctx, salespersonID)
+	if err != nil {
+		return nil, fmt.Errorf("salesperson %s not found: %w", salespersonID, err)
+	}
+	return &salesperson, nil
+}
+
+func (s *service) GetSalespeople(ctx context.Context, filter mysql.SalespersonFilter, opts mysql.ListOptions) ([]mysql.Salesperson, int, error) {
+	salespeople, err := s.salesperson_repo.List(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve salespeople: %w", err)
+	}
+
+	total, err := s.salesperson_repo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count salespeople: %w", err)
+	}
+	return salespeople, total, nil
+}
+
+func (s *service) UpdateSalesperson(ctx context.Context, salespersonID string, input SalespersonInput) (*mysql.Salesperson, error) {
+	if err := validateCommission(input.Commission); err != nil {
+		return nil, err
+	}
+	if input.Role != "" {
+		if err := validateRole(input.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	salesperson, err := s.salesperson_repo.GetByID(ctx, salespersonID)
+	if err != nil {
+		return nil, fmt.Errorf("salesperson %s not found for update: %w", salespersonID, err)
+	}
+
+	salesperson.Employee_ID = input.EmployeeID
+	salesperson.First_Name = input.FirstName
+	salesperson.Last_Name = input.LastName
+	salesperson.Email = input.Email
+	salesperson.Phone = input.Phone
+	salesperson.Hire_Date = input.HireDate
+	salesperson.Commission = input.Commission
+	salesperson.Department = input.Department
+	if input.Role != "" {
+		salesperson.Role = input.Role
+	}
+	if input.Status != "" {
+		salesperson.Status = input.Status
+	}
+	salesperson.Updated_At = time.Now()
+
+	err = s.salesperson_repo.Update(ctx, salespersonID, salesperson)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update salesperson %s: %w", salespersonID, err)
+	}
+
+	return &salesperson, nil
+}
+
+func (s *service) DeactivateSalesperson(ctx context.Context, salespersonID string) (*mysql.Salesperson, error) {
+	salesperson, err := s.salesperson_repo.GetByID(ctx, salespersonID)
+	if err != nil {
+		return nil, fmt.Errorf("salesperson %s not found for deactivation: %w", salespersonID, err)
+	}
+
+	if salesperson.Status == mysql.SalesPersonStatusTerminated {
+		return nil, fmt.Errorf("salesperson %s is terminated and cannot be deactivated: %w", salespersonID, ErrInvalidState)
+	}
+
+	salesperson.Status = mysql.SalesPersonStatusInactive
+	salesperson.Updated_At = time.Now()
+
+	err = s.salesperson_repo.Update(ctx, salespersonID, salesperson)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deactivate salesperson %s: %w", salespersonID, err)
+	}
+
+	return &salesperson, nil
+}
+
+// salesperson helper functions
+
+func validateCommission(commission float64) error {
+	if commission < 0 || commission >= 1 {
+		return fmt.Errorf("commission must be a fraction between 0 and 1, got %.3f: %w", commission, ErrInvalidInput)
+	}
+	return nil
+}
+
+func validateRole(role mysql.SalesPersonRole) error {
+	if role != mysql.SalesPersonRoleSales && role != mysql.SalesPersonRoleManager {
+		return fmt.Errorf("role must be sales or manager, got %q: %w", role, ErrInvalidInput)
+	}
+	return nil
+}
@@ -2,13 +2,63 @@ package dealership
 
 import (
 	"api-servers/internal/repository/mysql"
+	"api-servers/internal/repository/redis"
+	"time"
 )
 
+// DefaultCreditDecisionTTL is how long a stored credit decision is reused
+// before a new application is run
+const DefaultCreditDecisionTTL = 30 * 24 * time.Hour
+
 type service struct {
-	customer_repo    mysql.CustomerRepository
-	vehicle_repo     mysql.VehicleRepository
-	salesperson_repo mysql.SalespersonRepository
-	sales_repo       mysql.SaleRepository
+	customer_repo      mysql.CustomerRepository
+	vehicle_repo       mysql.VehicleRepository
+	salesperson_repo   mysql.SalespersonRepository
+	sales_repo         mysql.SaleRepository
+	reservation_repo   mysql.ReservationRepository
+	transactor         mysql.Transactor
+	sales_session_repo redis.SalesSessionRepository
+	credit_repo        mysql.CreditApplicationRepository
+	trade_in_repo      mysql.TradeInRepository
+	approval_repo      mysql.SaleApprovalRepository
+	contract_repo      mysql.SaleContractRepository
+	credit_bureau      CreditBureau
+	credit_ttl         time.Duration
+	approval_policy    ApprovalPolicy
+	fee_table          FeeTable
+	contract_key       []byte
+}
+
+// ServiceOption overrides a service default
+type ServiceOption func(*service)
+
+// WithCreditDecisionTTL sets how long credit decisions stay valid
+func WithCreditDecisionTTL(ttl time.Duration) ServiceOption {
+	return func(s *service) {
+		s.credit_ttl = ttl
+	}
+}
+
+// WithApprovalPolicy sets which sales need a manager's approval
+func WithApprovalPolicy(policy ApprovalPolicy) ServiceOption {
+	return func(s *service) {
+		s.approval_policy = policy
+	}
+}
+
+// WithFeeTable sets the taxes and fees charged on top of the sale price
+func WithFeeTable(table FeeTable) ServiceOption {
+	return func(s *service) {
+		s.fee_table = table
+	}
+}
+
+// WithContractSigningKey sets the key contracts are signed with. Without one
+// no contracts can be generated or verified.
+func WithContractSigningKey(key []byte) ServiceOption {
+	return func(s *service) {
+		s.contract_key = key
+	}
 }
 
 func NewService(
@@ -16,11 +66,35 @@ func NewService(
 	vehicle_repo mysql.VehicleRepository,
 	salesperson_repo mysql.SalespersonRepository,
 	sales_repo mysql.SaleRepository,
+	reservation_repo mysql.ReservationRepository,
+	transactor mysql.Transactor,
+	sales_session_repo redis.SalesSessionRepository,
+	credit_repo mysql.CreditApplicationRepository,
+	trade_in_repo mysql.TradeInRepository,
+	approval_repo mysql.SaleApprovalRepository,
+	contract_repo mysql.SaleContractRepository,
+	credit_bureau CreditBureau,
+	options ...ServiceOption,
 ) DealershipService {
-	return &service{
-		customer_repo:    customer_repo,
-		vehicle_repo:     vehicle_repo,
-		salesperson_repo: salesperson_repo,
-		sales_repo:       sales_repo,
+	s := &service{
+		customer_repo:      customer_repo,
+		vehicle_repo:       vehicle_repo,
+		salesperson_repo:   salesperson_repo,
+		sales_repo:         sales_repo,
+		reservation_repo:   reservation_repo,
+		transactor:         transactor,
+		sales_session_repo: sales_session_repo,
+		credit_repo:        credit_repo,
+		trade_in_repo:      trade_in_repo,
+		approval_repo:      approval_repo,
+		contract_repo:      contract_repo,
+		credit_bureau:      credit_bureau,
+		credit_ttl:         DefaultCreditDecisionTTL,
+		approval_policy:    DefaultApprovalPolicy,
+		fee_table:          DefaultFeeTable,
+	}
+	for _, option := range options {
+		option(s)
 	}
+	return s
 }
@@ -0,0 +1,337 @@
+package dealership
+
+import (
+	"api-servers/internal/models/mysql"
+	mysqlrepo "api-servers/internal/repository/mysql"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	tradeInOfferDuration = 7 * 24 * time.Hour
+
+	// each mile over or under this many miles per year of age moves the value
+	// by tradeInMileageRate, capped at tradeInMaxMileageAdjustment of the value
+	tradeInExpectedAnnualMileage = 12000
+	tradeInMileageRate           = 0.08
+	tradeInMaxMileageAdjustment  = 0.2
+
+	tradeInMinimumOffer = 500.0
+
+	// traded cars are listed at the offer plus reconditioning and margin
+	tradeInResaleMarkup = 0.2
+
+	tradeInDefaultBaseValue = 28000.0
+)
+
+// tradeInBaseValues is what a new car of each make is worth to the
+// dealership before depreciation. Makes are matched case-insensitively.
+var tradeInBaseValues = map[string]float64{
+	"chevrolet":     32000,
+	"ford":          33000,
+	"honda":         29000,
+	"hyundai":       26000,
+	"toyota":        30000,
+	"volkswagen":    28000,
+	"bmw":           48000,
+	"mercedes-benz": 52000,
+	"tesla":         45000,
+}
+
+// tradeInDepreciation is the share of the base value a car keeps at each age
+// in years. Bands are checked in order; cars older than the last band keep
+// tradeInMinimumRetained.
+var tradeInDepreciation = []struct {
+	maxAge   int
+	retained float64
+}{
+	{0, 0.85},
+	{1, 0.75},
+	{3, 0.6},
+	{5, 0.45},
+	{8, 0.3},
+	{12, 0.18},
+}
+
+const tradeInMinimumRetained = 0.1
+
+var tradeInConditionFactors = map[mysql.TradeInCondition]float64{
+	mysql.TradeInConditionExcellent: 1.0,
+	mysql.TradeInConditionGood:      0.9,
+	mysql.TradeInConditionFair:      0.75,
+	mysql.TradeInConditionPoor:      0.55,
+}
+
+func (s *service) AppraiseTradeIn(ctx context.Context, request TradeInRequest) (*TradeInAppraisal, error) {
+	customer, err := s.getCustomer(ctx, request.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	request.VIN = strings.ToUpper(strings.TrimSpace(request.VIN))
+	if len(request.VIN) != 17 {
+		return nil, fmt.Errorf("vin must be 17 characters: %w", ErrInvalidInput)
+	}
+	if request.Make == "" || request.Model == "" {
+		return nil, fmt.Errorf("make and model are required: %w", ErrInvalidInput)
+	}
+
+	existing, err := s.vehicle_repo.GetByVin(ctx, request.VIN)
+	if err != nil && !errors.Is(err, mysqlrepo.ErrNotFound) {
+		return nil, fmt.Errorf("failed to check inventory for vin %s: %w", request.VIN, err)
+	}
+	if err == nil && existing.Status != mysql.VehicleStatusSold {
+		return nil, fmt.Errorf("vehicle with vin %s is already in inventory: %w", request.VIN, ErrInvalidState)
+	}
+
+	now := time.Now()
+	appraisal, err := appraiseTradeIn(request, now)
+	if err != nil {
+		return nil, err
+	}
+
+	appraisal.TradeIn = mysql.TradeIn{
+		ID:           uuid.New().String(),
+		Customer_ID:  customer.ID,
+		VIN:          request.VIN,
+		Make:         request.Make,
+		Model:        request.Model,
+		Year:         request.Year,
+		Color:        request.Color,
+		Mileage:      request.Mileage,
+		Condition:    request.Condition,
+		Offer_Amount: appraisal.OfferAmount,
+		Status:       mysql.TradeInStatusOffered,
+		Expires_At:   now.Add(tradeInOfferDuration),
+		Created_At:   now,
+		Updated_At:   now,
+	}
+
+	err = s.trade_in_repo.Create(ctx, appraisal.TradeIn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record trade-in offer: %w", err)
+	}
+
+	return appraisal, nil
+}
+
+func (s *service) GetTradeIn(ctx context.Context, tradeInID string) (*mysql.TradeIn, error) {
+	tradeIn, err := s.trade_in_repo.GetByID(ctx, tradeInID)
+	if err != nil {
+		return nil, err
+	}
+
+	tradeIn, err = s.expireTradeIn(ctx, tradeIn)
+	if err != nil {
+		return nil, err
+	}
+	return &tradeIn, nil
+}
+
+func (s *service) GetCustomerTradeIns(ctx context.Context, customerID string) ([]mysql.TradeIn, error) {
+	if _, err := s.getCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	tradeIns, err := s.trade_in_repo.GetByCustomerId(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade-ins for customer %s: %w", customerID, err)
+	}
+
+	for i := range tradeIns {
+		tradeIns[i], err = s.expireTradeIn(ctx, tradeIns[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tradeIns, nil
+}
+
+// appraiseTradeIn values a car from its make, age, mileage and condition.
+// The offer is rounded down to the nearest $50 and never below
+// tradeInMinimumOffer.
+func appraiseTradeIn(request TradeInRequest, now time.Time) (*TradeInAppraisal, error) {
+	conditionFactor, ok := tradeInConditionFactors[request.Condition]
+	if !ok {
+		return nil, fmt.Errorf("condition must be one of excellent, good, fair or poor: %w", ErrInvalidInput)
+	}
+
+	age := now.Year() - request.Year
+	if request.Year <= 0 || age < -1 {
+		return nil, fmt.Errorf("year %d is not valid: %w", request.Year, ErrInvalidInput)
+	}
+	if age < 0 {
+		// next year's models go on sale during the current year
+		age = 0
+	}
+	if request.Mileage < 0 {
+		return nil, fmt.Errorf("mileage cannot be negative: %w", ErrInvalidInput)
+	}
+
+	baseValue, ok := tradeInBaseValues[strings.ToLower(request.Make)]
+	if !ok {
+		baseValue = tradeInDefaultBaseValue
+	}
+
+	retained := tradeInMinimumRetained
+	for _, band := range tradeInDepreciation {
+		if age <= band.maxAge {
+			retained = band.retained
+			break
+		}
+	}
+	value := baseValue * retained
+
+	expectedMileage := max(age, 1) * tradeInExpectedAnnualMileage
+	mileageAdjustment := float64(expectedMileage-request.Mileage) * tradeInMileageRate
+	limit := value * tradeInMaxMileageAdjustment
+	mileageAdjustment = math.Max(-limit, math.Min(limit, mileageAdjustment))
+
+	value = (value + mileageAdjustment) * conditionFactor
+	offer := math.Max(math.Floor(value/50)*50, tradeInMinimumOffer)
+
+	return &TradeInAppraisal{
+		BaseValue:         baseValue,
+		AgeYears:          age,
+		RetainedPercent:   retained,
+		ExpectedMileage:   expectedMileage,
+		MileageAdjustment: roundCents(mileageAdjustment),
+		ConditionFactor:   conditionFactor,
+		OfferAmount:       offer,
+	}, nil
+}
+
+// tradeInCredit loads an offer the customer can put toward a purchase. An
+// empty tradeInID means no trade-in.
+func (s *service) tradeInCredit(ctx context.Context, tradeInID, customerID string) (*mysql.TradeIn, error) {
+	if tradeInID == "" {
+		return nil, nil
+	}
+
+	tradeIn, err := s.trade_in_repo.GetByID(ctx, tradeInID)
+	if err != nil {
+		return nil, err
+	}
+	if tradeIn.Customer_ID != customerID {
+		return nil, fmt.Errorf("trade-in %s belongs to another customer: %w", tradeInID, ErrInvalidInput)
+	}
+
+	tradeIn, err = s.expireTradeIn(ctx, tradeIn)
+	if err != nil {
+		return nil, err
+	}
+	if tradeIn.Status != mysql.TradeInStatusOffered {
+		return nil, fmt.Errorf("trade-in %s is %s and cannot be applied: %w", tradeInID, tradeIn.Status, ErrInvalidState)
+	}
+	return &tradeIn, nil
+}
+
+// expireTradeIn moves an offer past its expiry time to expired
+func (s *service) expireTradeIn(ctx context.Context, tradeIn mysql.TradeIn) (mysql.TradeIn, error) {
+	if tradeIn.Status != mysql.TradeInStatusOffered || time.Now().Before(tradeIn.Expires_At) {
+		return tradeIn, nil
+	}
+
+	tradeIn.Status = mysql.TradeInStatusExpired
+	tradeIn.Updated_At = time.Now()
+
+	err := s.trade_in_repo.Update(ctx, tradeIn.ID, tradeIn, mysql.TradeInStatusOffered)
+	if err != nil {
+		return tradeIn, fmt.Errorf("failed to expire trade-in %s: %w", tradeIn.ID, err)
+	}
+	return tradeIn, nil
+}
+
+// applyTradeIn ties an offer to the sale that used it, so it can't be put
+// toward another purchase
+func applyTradeIn(ctx context.Context, repos mysqlrepo.Repositories, tradeIn *mysql.TradeIn, saleID string, now time.Time) error {
+	tradeIn.Status = mysql.TradeInStatusApplied
+	tradeIn.Sale_ID = &saleID
+	tradeIn.Updated_At = now
+	err := repos.TradeIns.Update(ctx, tradeIn.ID, *tradeIn, mysql.TradeInStatusOffered)
+	if errors.Is(err, mysqlrepo.ErrConflict) {
+		return &ConflictError{Resource: "trade-in", ID: tradeIn.ID, Err: err}
+	}
+	return err
+}
+
+// stockTradeIn takes an applied trade-in into inventory once its sale is
+// completed. A car the dealership sold before is listed again under its
+// existing record, since VINs are unique among live vehicles, with its listing
+// date reset so it doesn't age from when it was first on the lot; one whose
+// record was deleted gets a new one.
+func stockTradeIn(ctx context.Context, repos mysqlrepo.Repositories, tradeIn *mysql.TradeIn, now time.Time) (mysql.Vehicle, error) {
+	price := roundCents(tradeIn.Offer_Amount * (1 + tradeInResaleMarkup))
+
+	vehicle, err := repos.Vehicles.GetByVin(ctx, tradeIn.VIN)
+	switch {
+	case err == nil:
+		if vehicle.Status != mysql.VehicleStatusSold {
+			return vehicle, fmt.Errorf("vehicle with vin %s is already in inventory: %w", tradeIn.VIN, ErrInvalidState)
+		}
+		vehicle.Color = tradeIn.Color
+		vehicle.Mileage = tradeIn.Mileage
+		vehicle.Price = price
+		vehicle.Status = mysql.VehicleStatusAvailable
+		vehicle.Listed_At = now
+		vehicle.Updated_At = now
+		err = repos.Vehicles.Update(ctx, vehicle.ID, vehicle)
+	case errors.Is(err, mysqlrepo.ErrNotFound):
+		vehicle = mysql.Vehicle{
+			ID:         uuid.New().String(),
+			VIN:        tradeIn.VIN,
+			Make:       tradeIn.Make,
+			Model:      tradeIn.Model,
+			Year:       tradeIn.Year,
+			Color:      tradeIn.Color,
+			Mileage:    tradeIn.Mileage,
+			Price:      price,
+			Status:     mysql.VehicleStatusAvailable,
+			Listed_At:  now,
+			Created_At: now,
+			Updated_At: now,
+		}
+		err = repos.Vehicles.Create(ctx, vehicle)
+	}
+	if err != nil {
+		return vehicle, fmt.Errorf("failed to add trade-in %s to inventory: %w", tradeIn.ID, err)
+	}
+
+	tradeIn.Vehicle_ID = &vehicle.ID
+	tradeIn.Updated_At = now
+	err = repos.TradeIns.Update(ctx, tradeIn.ID, *tradeIn, mysql.TradeInStatusApplied)
+	if errors.Is(err, mysqlrepo.ErrConflict) {
+		return vehicle, &ConflictError{Resource: "trade-in", ID: tradeIn.ID, Err: err}
+	}
+	return vehicle, err
+}
+
+// returnTradeIn hands a trade-in back to the customer when its sale is
+// cancelled. The car is soft-deleted from inventory rather than marked sold,
+// since nobody bought it, which fails if it has since been reserved or sold
+// to someone else.
+func returnTradeIn(ctx context.Context, repos mysqlrepo.Repositories, tradeIn *mysql.TradeIn, now time.Time) error {
+	if tradeIn.Vehicle_ID != nil {
+		err := repos.Vehicles.Delete(ctx, *tradeIn.Vehicle_ID, mysql.VehicleStatusAvailable)
+		if errors.Is(err, mysqlrepo.ErrConflict) {
+			return &ConflictError{Resource: "trade-in vehicle", ID: *tradeIn.Vehicle_ID, Err: err}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	tradeIn.Status = mysql.TradeInStatusReturned
+	tradeIn.Updated_At = now
+	err := repos.TradeIns.Update(ctx, tradeIn.ID, *tradeIn, mysql.TradeInStatusApplied)
+	if errors.Is(err, mysqlrepo.ErrConflict) {
+		return &ConflictError{Resource: "trade-in", ID: tradeIn.ID, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,127 @@
+package dealership
+
+import (
+	"api-servers/internal/models/mysql"
+	mysqlrepo "api-servers/internal/repository/mysql"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// memoryTradeInRepository keeps trade-ins in memory and only updates one
+// still in the expected status, like the MySQL repository
+type memoryTradeInRepository struct {
+	mysqlrepo.TradeInRepository
+	tradeIns map[string]mysql.TradeIn
+}
+
+func (r *memoryTradeInRepository) Update(ctx context.Context, id string, tradeIn mysql.TradeIn, from mysql.TradeInStatus) error {
+	stored, ok := r.tradeIns[id]
+	if !ok {
+		return fmt.Errorf("trade-in with id %s not found for update: %w", id, mysqlrepo.ErrNotFound)
+	}
+	if stored.Status != from {
+		return fmt.Errorf("trade-in %s is %s, not %s: %w", id, stored.Status, from, mysqlrepo.ErrConflict)
+	}
+	r.tradeIns[id] = tradeIn
+	return nil
+}
+
+func appliedTradeIn(vin string) *mysql.TradeIn {
+	return &mysql.TradeIn{
+		ID: "trade-in-1", Customer_ID: "customer-1", VIN: vin, Make: "Honda", Model: "Accord",
+		Year: 2019, Color: "blue", Mileage: 61000, Offer_Amount: 15000, Status: mysql.TradeInStatusApplied,
+	}
+}
+
+func TestStockTradeInRelistsACarSoldBefore(t *testing.T) {
+	firstListed := time.Date(2021, time.March, 1, 9, 0, 0, 0, time.UTC)
+	sold := testVehicle(4)
+	sold.Status = mysql.VehicleStatusSold
+	sold.Listed_At = firstListed
+	sold.Created_At = firstListed
+
+	vehicles := newMemoryVehicleRepository(sold)
+	tradeIn := appliedTradeIn(sold.VIN)
+	tradeIns := &memoryTradeInRepository{tradeIns: map[string]mysql.TradeIn{tradeIn.ID: *tradeIn}}
+	repos := mysqlrepo.Repositories{Vehicles: vehicles, TradeIns: tradeIns}
+
+	now := time.Date(2026, time.October, 1, 15, 0, 0, 0, time.UTC)
+	vehicle, err := stockTradeIn(context.Background(), repos, tradeIn, now)
+	if err != nil {
+		t.Fatalf("stockTradeIn returned error: %v", err)
+	}
+
+	if vehicle.ID != sold.ID {
+		t.Errorf("relisted as vehicle %s, want the existing record %s", vehicle.ID, sold.ID)
+	}
+	stored, _ := vehicles.GetByID(context.Background(), sold.ID)
+	if stored.Status != mysql.VehicleStatusAvailable {
+		t.Errorf("status = %s, want available", stored.Status)
+	}
+	if !stored.Listed_At.Equal(now) {
+		t.Errorf("listed at %v, want the relisting time %v", stored.Listed_At, now)
+	}
+	if !stored.Created_At.Equal(firstListed) {
+		t.Errorf("created at %v, want the original record's %v", stored.Created_At, firstListed)
+	}
+	if stored.Mileage != tradeIn.Mileage || stored.Price != 18000 {
+		t.Errorf("mileage %d and price %v, want the trade-in's %d and 18000", stored.Mileage, stored.Price, tradeIn.Mileage)
+	}
+	if got := tradeIns.tradeIns[tradeIn.ID].Vehicle_ID; got == nil || *got != sold.ID {
+		t.Errorf("trade-in vehicle = %v, want %s", got, sold.ID)
+	}
+}
+
+func TestStockTradeInListsANewCar(t *testing.T) {
+	vehicles := newMemoryVehicleRepository()
+	tradeIn := appliedTradeIn("2T1BURHE0JC074512")
+	tradeIns := &memoryTradeInRepository{tradeIns: map[string]mysql.TradeIn{tradeIn.ID: *tradeIn}}
+	repos := mysqlrepo.Repositories{Vehicles: vehicles, TradeIns: tradeIns}
+
+	now := time.Date(2026, time.October, 1, 15, 0, 0, 0, time.UTC)
+	vehicle, err := stockTradeIn(context.Background(), repos, tradeIn, now)
+	if err != nil {
+		t.Fatalf("stockTradeIn returned error: %v", err)
+	}
+	if !vehicle.Listed_At.Equal(now) || !vehicle.Created_At.Equal(now) {
+		t.Errorf("listed at %v and created at %v, want both %v", vehicle.Listed_At, vehicle.Created_At, now)
+	}
+}
+
+func TestStockTradeInRefusesACarStillInInventory(t *testing.T) {
+	vehicles := newMemoryVehicleRepository(testVehicle(0))
+	tradeIn := appliedTradeIn(testVehicle(0).VIN)
+	repos := mysqlrepo.Repositories{Vehicles: vehicles, TradeIns: &memoryTradeInRepository{}}
+
+	_, err := stockTradeIn(context.Background(), repos, tradeIn, time.Now())
+	if !errors.Is(err, ErrInvalidState) {
+		t.Errorf("stockTradeIn error = %v, want ErrInvalidState", err)
+	}
+}
+
+func TestValidateDownPayment(t *testing.T) {
+	tests := []struct {
+		name          string
+		downPayment   float64
+		tradeInCredit float64
+		wantErr       bool
+	}{
+		{name: "nothing down"},
+		{name: "cash and trade-in within the price", downPayment: 5000, tradeInCredit: 10000},
+		{name: "trade-in covers the whole price", tradeInCredit: 20000},
+		{name: "negative down payment", downPayment: -100, wantErr: true},
+		{name: "trade-in and cash over the price", downPayment: 6000, tradeInCredit: 15000, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDownPayment(20000, tt.downPayment, tt.tradeInCredit)
+			if tt.wantErr != errors.Is(err, ErrInvalidInput) || (!tt.wantErr && err != nil) {
+				t.Errorf("validateDownPayment = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
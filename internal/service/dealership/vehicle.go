@@ -2,7 +2,9 @@ package dealership
 
 import (
 	"api-servers/internal/models/mysql"
+	mysqlrepo "api-servers/internal/repository/mysql"
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,6 +12,7 @@ import (
 )
 
 func (s *service) AddVehicleToInventory(ctx context.Context, vehicle VehicleInput) (*mysql.Vehicle, error) {
+	now := time.Now()
 	newVehicle := mysql.Vehicle{
 		ID:           uuid.New().String(),
 		VIN:          vehicle.VIN,
@@ -23,11 +26,12 @@ func (s *service) AddVehicleToInventory(ctx context.Context, vehicle VehicleInpu
 		Engine_Type:  vehicle.EngineType,
 		Transmission: vehicle.Transmission,
 		Fuel_Type:    vehicle.FuelType,
-		Created_At:   time.Now(),
-		Updated_At:   time.Now(),
+		Listed_At:    now,
+		Created_At:   now,
+		Updated_At:   now,
 	}
 
-	err := s.vehicle_repo.Create(newVehicle)
+	err := s.vehicle_repo.Create(ctx, newVehicle)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add %s %s to inventory: %w", vehicle.Make, vehicle.Model, err)
 	}
@@ -35,99 +39,188 @@ func (s *service) AddVehicleToInventory(ctx context.Context, vehicle VehicleInpu
 	return &newVehicle, nil
 }
 
-func (s *service) GetAllVehicles(ctx context.Context) ([]mysql.Vehicle, error) {
-	vehicles, err := s.vehicle_repo.GetAll()
+func (s *service) GetAllVehicles(ctx context.Context, filter mysql.VehicleFilter, opts mysql.ListOptions) ([]mysql.Vehicle, int, error) {
+	vehicles, err := s.vehicle_repo.List(ctx, filter, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve vehicles: %w", err)
+		return nil, 0, fmt.Errorf("failed to retrieve vehicles: %w", err)
 	}
-	return vehicles, nil
+
+	total, err := s.vehicle_repo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count vehicles: %w", err)
+	}
+	return vehicles, total, nil
 }
 
 func (s *service) GetVehicleByID(ctx context.Context, vehicleID string) (*mysql.Vehicle, error) {
-	vehicle, err := s.vehicle_repo.GetByID(vehicleID)
+	vehicle, err := s.getVehicle(ctx, vehicleID)
 	if err != nil {
-		return nil, fmt.Errorf("vehicle %s not found: %w", vehicleID, err)
+		return nil, err
 	}
 	return &vehicle, nil
 }
 
-func (s *service) FindVehiclesForCustomers(ctx context.Context, customerID string, preferences VehiclePreferences) ([]mysql.Vehicle, error) {
-	allVehicles, err := s.vehicle_repo.GetAll()
+func (s *service) UpdateVehicle(ctx context.Context, vehicleID string, input VehicleInput) (*mysql.Vehicle, error) {
+	vehicle, err := s.getVehicle(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.saveVehicle(ctx, vehicle, input)
+}
+
+// PatchVehicle applies a merge patch to a vehicle. The version the client
+// last read comes from the patch's "version" or, when that is absent, version
+// (nil for none).
+func (s *service) PatchVehicle(ctx context.Context, vehicleID string, patch []byte, version *int) (*mysql.Vehicle, error) {
+	vehicle, err := s.getVehicle(ctx, vehicleID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get vehicles: %w", err)
+		return nil, err
 	}
 
-	var matchingVehicles []mysql.Vehicle
-	for _, vehicle := range allVehicles {
-		if s.matchesPreferences(vehicle, preferences) {
-			matchingVehicles = append(matchingVehicles, vehicle)
+	var input VehicleInput
+	err = applyMergePatch(vehicleInputFrom(vehicle), patch, &input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to patch vehicle %s: %w", vehicleID, err)
+	}
+	if version != nil {
+		if input.Version != nil && *input.Version != *version {
+			return nil, fmt.Errorf("patch version %d does not match version %d: %w", *input.Version, *version, ErrInvalidInput)
 		}
+		input.Version = version
 	}
 
-	return matchingVehicles, nil
+	return s.saveVehicle(ctx, vehicle, input)
 }
 
-func (s *service) ReserveVehicle(ctx context.Context, vehicleID, customerID string) error {
-	vehicle, err := s.vehicle_repo.GetByID(vehicleID)
+func (s *service) DeleteVehicle(ctx context.Context, vehicleID string) error {
+	vehicle, err := s.getVehicle(ctx, vehicleID)
 	if err != nil {
-		return fmt.Errorf("could not find vehicle %s for reservation: %w", vehicleID, err)
+		return err
 	}
 
-	if vehicle.Status != mysql.VehicleStatusAvailable {
-		return fmt.Errorf("vehicle %s is not available for reservation", vehicleID)
+	if vehicle.Status != mysql.VehicleStatusAvailable && vehicle.Status != mysql.VehicleStatusMaintenance {
+		return fmt.Errorf("vehicle %s is %s and cannot be deleted: %w", vehicleID, vehicle.Status, ErrInvalidState)
 	}
 
-	_, err = s.customer_repo.GetByID(customerID)
+	// a reservation or sale can still land between the read and the delete,
+	// so the delete only goes through while the vehicle is unclaimed
+	err = s.vehicle_repo.Delete(ctx, vehicleID, mysql.VehicleStatusAvailable, mysql.VehicleStatusMaintenance)
+	if errors.Is(err, mysqlrepo.ErrConflict) {
+		return &ConflictError{Resource: "vehicle", ID: vehicleID, Err: err}
+	}
 	if err != nil {
-		return fmt.Errorf("customer %s not found for vehicle reservation: %w", customerID, err)
+		return fmt.Errorf("failed to delete vehicle %s: %w", vehicleID, err)
 	}
+	return nil
+}
 
-	vehicle.Status = mysql.VehicleStatusReserved
-	vehicle.Updated_At = time.Now()
+func (s *service) FindVehiclesForCustomers(ctx context.Context, customerID string, preferences VehiclePreferences, opts mysql.ListOptions) ([]mysql.Vehicle, int, error) {
+	filter, err := vehicleFilterFrom(preferences)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	err = s.vehicle_repo.Update(vehicleID, vehicle)
+	vehicles, err := s.vehicle_repo.Search(ctx, filter, opts)
 	if err != nil {
-		return fmt.Errorf("failed to reserve vehicle %s for customer %s: %w", vehicleID, customerID, err)
+		return nil, 0, fmt.Errorf("failed to search vehicles: %w", err)
 	}
 
-	return nil
+	total, err := s.vehicle_repo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count vehicles: %w", err)
+	}
+	return vehicles, total, nil
 }
 
 // vehicle helper functions
 
-func (s *service) matchesPreferences(vehicle mysql.Vehicle, preferences VehiclePreferences) bool {
-	if vehicle.Price < preferences.MinPrice || vehicle.Price > preferences.MaxPrice {
-		return false
+// getVehicle loads a vehicle, treating soft-deleted vehicles as not found
+func (s *service) getVehicle(ctx context.Context, vehicleID string) (mysql.Vehicle, error) {
+	vehicle, err := s.vehicle_repo.GetByID(ctx, vehicleID)
+	if err != nil {
+		return vehicle, fmt.Errorf("vehicle %s not found: %w", vehicleID, err)
+	}
+	if vehicle.Deleted_At != nil {
+		return vehicle, fmt.Errorf("vehicle %s has been deleted: %w", vehicleID, ErrNotFound)
 	}
+	return vehicle, nil
+}
 
-	if (preferences.MinYear > 0 && vehicle.Year < preferences.MinYear) ||
-		(preferences.MaxYear > 0 && vehicle.Year > preferences.MaxYear) {
-		return false
+func (s *service) saveVehicle(ctx context.Context, vehicle mysql.Vehicle, input VehicleInput) (*mysql.Vehicle, error) {
+	if input.VIN == "" || input.Make == "" || input.Model == "" {
+		return nil, fmt.Errorf("vin, make and model are required")
+	}
+	if input.Price < 0 || input.Mileage < 0 {
+		return nil, fmt.Errorf("price and mileage cannot be negative")
 	}
 
-	if preferences.MaxMileage > 0 && vehicle.Mileage > preferences.MaxMileage {
-		return false
+	vehicle.VIN = input.VIN
+	vehicle.Make = input.Make
+	vehicle.Model = input.Model
+	vehicle.Year = input.Year
+	vehicle.Color = input.Color
+	vehicle.Mileage = input.Mileage
+	vehicle.Price = input.Price
+	vehicle.Engine_Type = input.EngineType
+	vehicle.Transmission = input.Transmission
+	vehicle.Fuel_Type = input.FuelType
+	vehicle.Updated_At = time.Now()
+
+	// update against the version the client read, not the one just loaded,
+	// so a change made in between is reported as a conflict
+	if input.Version != nil {
+		vehicle.Version = *input.Version
 	}
 
-	if len(preferences.Makes) > 0 {
-		makeSet := make(map[string]bool, len(preferences.Makes))
-		for _, make := range preferences.Makes {
-			makeSet[make] = true
-		}
-		if !makeSet[vehicle.Make] {
-			return false
-		}
+	err := s.vehicle_repo.Update(ctx, vehicle.ID, vehicle)
+	if errors.Is(err, mysqlrepo.ErrConflict) {
+		return nil, &ConflictError{Resource: "vehicle", ID: vehicle.ID, Err: err}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update vehicle %s: %w", vehicle.ID, err)
 	}
 
-	if len(preferences.FuelTypes) > 0 {
-		fuelSet := make(map[mysql.FuelType]bool, len(preferences.FuelTypes))
-		for _, fuel := range preferences.FuelTypes {
-			fuelSet[fuel] = true
-		}
-		if !fuelSet[vehicle.Fuel_Type] {
-			return false
-		}
+	vehicle.Version++
+	return &vehicle, nil
+}
+
+func vehicleInputFrom(vehicle mysql.Vehicle) VehicleInput {
+	return VehicleInput{
+		VIN:          vehicle.VIN,
+		Make:         vehicle.Make,
+		Model:        vehicle.Model,
+		Year:         vehicle.Year,
+		Color:        vehicle.Color,
+		Mileage:      vehicle.Mileage,
+		Price:        vehicle.Price,
+		EngineType:   vehicle.Engine_Type,
+		Transmission: vehicle.Transmission,
+		FuelType:     vehicle.Fuel_Type,
+	}
+}
+
+// vehicleFilterFrom maps search preferences onto a repository filter. Unset
+// preferences are left at zero so they don't bound the query.
+func vehicleFilterFrom(preferences VehiclePreferences) (mysql.VehicleFilter, error) {
+	if preferences.MinPrice < 0 || preferences.MaxPrice < 0 || preferences.MaxMileage < 0 {
+		return mysql.VehicleFilter{}, fmt.Errorf("price and mileage preferences cannot be negative: %w", ErrInvalidQuery)
+	}
+	if preferences.MaxPrice > 0 && preferences.MinPrice > preferences.MaxPrice {
+		return mysql.VehicleFilter{}, fmt.Errorf("min_price cannot be greater than max_price: %w", ErrInvalidQuery)
+	}
+	if preferences.MaxYear > 0 && preferences.MinYear > preferences.MaxYear {
+		return mysql.VehicleFilter{}, fmt.Errorf("min_year cannot be greater than max_year: %w", ErrInvalidQuery)
 	}
 
-	return true
+	return mysql.VehicleFilter{
+		Makes:      preferences.Makes,
+		FuelTypes:  preferences.FuelTypes,
+		Status:     mysql.VehicleStatusAvailable,
+		MinPrice:   preferences.MinPrice,
+		MaxPrice:   preferences.MaxPrice,
+		MinYear:    preferences.MinYear,
+		MaxYear:    preferences.MaxYear,
+		MaxMileage: preferences.MaxMileage,
+	}, nil
 }
@@ -0,0 +1,287 @@
+package dealership
+
+import (
+	"api-servers/internal/models/mysql"
+	mysqlrepo "api-servers/internal/repository/mysql"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryVehicleRepository keeps vehicles in memory and guards writes on
+// version and status the way the MySQL repository's WHERE clauses do
+type memoryVehicleRepository struct {
+	mysqlrepo.VehicleRepository
+
+	mu       sync.Mutex
+	vehicles map[string]mysql.Vehicle
+
+	// beforeDelete runs between a delete being requested and applied, to
+	// stand in for a request that lands in between
+	beforeDelete func()
+}
+
+func newMemoryVehicleRepository(vehicles ...mysql.Vehicle) *memoryVehicleRepository {
+	r := &memoryVehicleRepository{vehicles: map[string]mysql.Vehicle{}}
+	for _, vehicle := range vehicles {
+		r.vehicles[vehicle.ID] = vehicle
+	}
+	return r
+}
+
+func (r *memoryVehicleRepository) GetByID(ctx context.Context, id string) (mysql.Vehicle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	vehicle, ok := r.vehicles[id]
+	if !ok {
+		return vehicle, fmt.Errorf("vehicle with id %s not found: %w", id, mysqlrepo.ErrNotFound)
+	}
+	return vehicle, nil
+}
+
+func (r *memoryVehicleRepository) GetByVin(ctx context.Context, vin string) (mysql.Vehicle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, vehicle := range r.vehicles {
+		if vehicle.VIN == vin && vehicle.Deleted_At == nil {
+			return vehicle, nil
+		}
+	}
+	return mysql.Vehicle{}, fmt.Errorf("vehicle with vin %s not found: %w", vin, mysqlrepo.ErrNotFound)
+}
+
+func (r *memoryVehicleRepository) Create(ctx context.Context, vehicle mysql.Vehicle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.vehicles[vehicle.ID] = vehicle
+	return nil
+}
+
+func (r *memoryVehicleRepository) Update(ctx context.Context, id string, vehicle mysql.Vehicle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.vehicles[id]
+	if !ok || stored.Deleted_At != nil {
+		return fmt.Errorf("vehicle with id %s not found for update: %w", id, mysqlrepo.ErrNotFound)
+	}
+	if stored.Version != vehicle.Version {
+		return fmt.Errorf("vehicle %s was modified since version %d: %w", id, vehicle.Version, mysqlrepo.ErrConflict)
+	}
+	vehicle.Version++
+	r.vehicles[id] = vehicle
+	return nil
+}
+
+func (r *memoryVehicleRepository) UpdateStatus(ctx context.Context, id string, status mysql.VehicleStatus, from ...mysql.VehicleStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.vehicles[id]
+	if !ok || stored.Deleted_At != nil {
+		return fmt.Errorf("vehicle with id %s not found for status update: %w", id, mysqlrepo.ErrNotFound)
+	}
+	if len(from) > 0 && !slices.Contains(from, stored.Status) {
+		return fmt.Errorf("vehicle %s is %s: %w", id, stored.Status, mysqlrepo.ErrConflict)
+	}
+	stored.Status = status
+	stored.Version++
+	r.vehicles[id] = stored
+	return nil
+}
+
+func (r *memoryVehicleRepository) Delete(ctx context.Context, id string, from ...mysql.VehicleStatus) error {
+	if r.beforeDelete != nil {
+		r.beforeDelete()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.vehicles[id]
+	if !ok || stored.Deleted_At != nil {
+		return fmt.Errorf("vehicle with id %s not found for deletion: %w", id, mysqlrepo.ErrNotFound)
+	}
+	if len(from) > 0 && !slices.Contains(from, stored.Status) {
+		return fmt.Errorf("vehicle %s is %s: %w", id, stored.Status, mysqlrepo.ErrConflict)
+	}
+	now := time.Now()
+	stored.Deleted_At = &now
+	stored.Version++
+	r.vehicles[id] = stored
+	return nil
+}
+
+func testVehicle(version int) mysql.Vehicle {
+	return mysql.Vehicle{
+		ID: "vehicle-1", VIN: "1HGCM82633A004352", Make: "Honda", Model: "Accord",
+		Year: 2021, Mileage: 20000, Price: 24000, Status: mysql.VehicleStatusAvailable, Version: version,
+	}
+}
+
+func versionOf(v int) *int {
+	return &v
+}
+
+func TestUpdateVehicleChecksTheClientVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		storedVersion int
+		clientVersion *int
+		wantConflict  bool
+		wantVersion   int
+	}{
+		{name: "no version updates whatever is current", storedVersion: 3, wantVersion: 4},
+		{name: "version 0 is checked like any other", storedVersion: 0, clientVersion: versionOf(0), wantVersion: 1},
+		{name: "stale version 0", storedVersion: 1, clientVersion: versionOf(0), wantConflict: true},
+		{name: "stale later version", storedVersion: 5, clientVersion: versionOf(4), wantConflict: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryVehicleRepository(testVehicle(tt.storedVersion))
+			s := &service{vehicle_repo: repo}
+
+			input := vehicleInputFrom(testVehicle(tt.storedVersion))
+			input.Price = 23000
+			input.Version = tt.clientVersion
+
+			vehicle, err := s.UpdateVehicle(context.Background(), "vehicle-1", input)
+
+			var conflict *ConflictError
+			if tt.wantConflict {
+				if !errors.As(err, &conflict) {
+					t.Fatalf("UpdateVehicle error = %v, want a ConflictError", err)
+				}
+				if stored, _ := repo.GetByID(context.Background(), "vehicle-1"); stored.Price != 24000 {
+					t.Errorf("stored price = %v, want the conflicting update to leave it at 24000", stored.Price)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateVehicle returned error: %v", err)
+			}
+			if vehicle.Version != tt.wantVersion {
+				t.Errorf("returned version = %d, want %d", vehicle.Version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestConcurrentVehicleUpdatesFromTheSameRead(t *testing.T) {
+	repo := newMemoryVehicleRepository(testVehicle(0))
+	s := &service{vehicle_repo: repo}
+
+	const writers = 8
+	errs := make(chan error, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(price float64) {
+			defer wg.Done()
+			input := vehicleInputFrom(testVehicle(0))
+			input.Price = price
+			input.Version = versionOf(0)
+			_, err := s.UpdateVehicle(context.Background(), "vehicle-1", input)
+			errs <- err
+		}(float64(20000 + i))
+	}
+	wg.Wait()
+	close(errs)
+
+	var saved, conflicts int
+	for err := range errs {
+		var conflict *ConflictError
+		switch {
+		case err == nil:
+			saved++
+		case errors.As(err, &conflict):
+			conflicts++
+		default:
+			t.Errorf("UpdateVehicle returned unexpected error: %v", err)
+		}
+	}
+	if saved != 1 || conflicts != writers-1 {
+		t.Errorf("%d updates saved and %d conflicted, want 1 and %d", saved, conflicts, writers-1)
+	}
+}
+
+func TestPatchVehicleVersions(t *testing.T) {
+	tests := []struct {
+		name        string
+		patch       string
+		ifMatch     *int
+		wantErr     error
+		wantVersion int
+	}{
+		{name: "If-Match version 0", patch: `{"price": 23000}`, ifMatch: versionOf(0), wantVersion: 1},
+		{name: "patch version 0", patch: `{"price": 23000, "version": 0}`, wantVersion: 1},
+		{name: "patch and If-Match disagree", patch: `{"version": 0}`, ifMatch: versionOf(1), wantErr: ErrInvalidInput},
+		{name: "stale If-Match", patch: `{"price": 23000}`, ifMatch: versionOf(2), wantErr: mysqlrepo.ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &service{vehicle_repo: newMemoryVehicleRepository(testVehicle(0))}
+
+			vehicle, err := s.PatchVehicle(context.Background(), "vehicle-1", []byte(tt.patch), tt.ifMatch)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("PatchVehicle error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PatchVehicle returned error: %v", err)
+			}
+			if vehicle.Version != tt.wantVersion {
+				t.Errorf("returned version = %d, want %d", vehicle.Version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestDeleteVehicleLosesToAReservation(t *testing.T) {
+	repo := newMemoryVehicleRepository(testVehicle(0))
+	s := &service{vehicle_repo: repo}
+
+	repo.beforeDelete = func() {
+		repo.beforeDelete = nil
+		if err := repo.UpdateStatus(context.Background(), "vehicle-1", mysql.VehicleStatusReserved, mysql.VehicleStatusAvailable); err != nil {
+			t.Fatalf("failed to reserve vehicle: %v", err)
+		}
+	}
+
+	err := s.DeleteVehicle(context.Background(), "vehicle-1")
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("DeleteVehicle error = %v, want a ConflictError", err)
+	}
+
+	stored, _ := repo.GetByID(context.Background(), "vehicle-1")
+	if stored.Deleted_At != nil {
+		t.Errorf("reserved vehicle was deleted")
+	}
+}
+
+func TestDeleteVehicleRefusesClaimedVehicles(t *testing.T) {
+	for _, status := range []mysql.VehicleStatus{mysql.VehicleStatusReserved, mysql.VehicleStatusPending, mysql.VehicleStatusSold} {
+		t.Run(string(status), func(t *testing.T) {
+			vehicle := testVehicle(0)
+			vehicle.Status = status
+			s := &service{vehicle_repo: newMemoryVehicleRepository(vehicle)}
+
+			err := s.DeleteVehicle(context.Background(), "vehicle-1")
+			if !errors.Is(err, ErrInvalidState) {
+				t.Errorf("DeleteVehicle error = %v, want ErrInvalidState", err)
+			}
+		})
+	}
+}
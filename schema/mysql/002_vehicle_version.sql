@@ -0,0 +1 @@
+ALTER TABLE vehicles ADD COLUMN version INT NOT NULL DEFAULT 0 AFTER fuel_type;
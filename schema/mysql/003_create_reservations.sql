@@ -0,0 +1,18 @@
+CREATE TABLE reservations (
+    id VARCHAR(36) PRIMARY KEY,
+    vehicle_id VARCHAR(36) NOT NULL,
+    customer_id VARCHAR(36) NOT NULL,
+    status ENUM('active', 'released', 'expired', 'completed') DEFAULT 'active',
+    reserved_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    released_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (vehicle_id) REFERENCES vehicles(id) ON DELETE CASCADE,
+    FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_reservations_vehicle ON reservations(vehicle_id, status);
+CREATE INDEX idx_reservations_customer ON reservations(customer_id);
+CREATE INDEX idx_reservations_expiry ON reservations(status, expires_at);
@@ -0,0 +1,13 @@
+ALTER TABLE customers ADD COLUMN deleted_at TIMESTAMP NULL AFTER credit_score;
+ALTER TABLE vehicles ADD COLUMN deleted_at TIMESTAMP NULL AFTER version;
+
+-- customers and vehicles are soft-deleted; sales history must never be removed with them
+ALTER TABLE sales
+    DROP FOREIGN KEY sales_ibfk_1,
+    DROP FOREIGN KEY sales_ibfk_2,
+    DROP FOREIGN KEY sales_ibfk_3;
+
+ALTER TABLE sales
+    ADD CONSTRAINT fk_sales_vehicle FOREIGN KEY (vehicle_id) REFERENCES vehicles(id) ON DELETE RESTRICT,
+    ADD CONSTRAINT fk_sales_customer FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE RESTRICT,
+    ADD CONSTRAINT fk_sales_salesperson FOREIGN KEY (salesperson_id) REFERENCES salespersons(id) ON DELETE RESTRICT;
@@ -0,0 +1 @@
+ALTER TABLE customers ADD COLUMN annual_income DECIMAL(12,2) NOT NULL DEFAULT 0 AFTER credit_score;
@@ -0,0 +1,19 @@
+CREATE TABLE credit_applications (
+    id VARCHAR(36) PRIMARY KEY,
+    customer_id VARCHAR(36) NOT NULL,
+    bureau VARCHAR(50) NOT NULL,
+    annual_income DECIMAL(12,2) NOT NULL,
+    monthly_debt DECIMAL(10,2) NOT NULL,
+    credit_score INT NOT NULL,
+    debt_to_income DECIMAL(6,3) NOT NULL,
+    approved BOOLEAN NOT NULL,
+    credit_limit DECIMAL(12,2) NOT NULL,
+    interest_rate DECIMAL(5,3) NOT NULL,
+    reason VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_credit_applications_customer ON credit_applications(customer_id, created_at);
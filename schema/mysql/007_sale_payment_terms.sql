@@ -0,0 +1,7 @@
+-- monthly payment for financed and leased sales, plus the lease terms the
+-- payment was priced on
+ALTER TABLE sales
+    ADD COLUMN monthly_payment DECIMAL(10,2) NOT NULL DEFAULT 0 AFTER interest_rate,
+    ADD COLUMN residual_value DECIMAL(10,2) NOT NULL DEFAULT 0 AFTER monthly_payment,
+    ADD COLUMN money_factor DECIMAL(8,6) NOT NULL DEFAULT 0 AFTER residual_value,
+    ADD COLUMN annual_mileage INT NOT NULL DEFAULT 0 AFTER money_factor;
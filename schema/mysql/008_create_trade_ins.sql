@@ -0,0 +1,28 @@
+CREATE TABLE trade_ins (
+    id VARCHAR(36) PRIMARY KEY,
+    customer_id VARCHAR(36) NOT NULL,
+    vin VARCHAR(17) NOT NULL,
+    make VARCHAR(50) NOT NULL,
+    model VARCHAR(50) NOT NULL,
+    year INT NOT NULL,
+    color VARCHAR(30) NOT NULL DEFAULT '',
+    mileage INT NOT NULL,
+    `condition` ENUM('excellent', 'good', 'fair', 'poor') NOT NULL,
+    offer_amount DECIMAL(10,2) NOT NULL,
+    status ENUM('offered', 'applied', 'expired') DEFAULT 'offered',
+    sale_id VARCHAR(36) NULL,
+    vehicle_id VARCHAR(36) NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE RESTRICT,
+    FOREIGN KEY (sale_id) REFERENCES sales(id) ON DELETE RESTRICT,
+    FOREIGN KEY (vehicle_id) REFERENCES vehicles(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_trade_ins_customer ON trade_ins(customer_id, created_at);
+
+-- credit from the customer's trade-in, counted toward the down payment
+ALTER TABLE sales
+    ADD COLUMN trade_in_credit DECIMAL(10,2) NOT NULL DEFAULT 0 AFTER down_payment;
@@ -0,0 +1,14 @@
+-- commission is stored with the sale so a cancellation reverses exactly what
+-- was paid, even if the salesperson's rate has changed since
+ALTER TABLE sales
+    ADD COLUMN commission DECIMAL(10,2) NOT NULL DEFAULT 0 AFTER payment_method,
+    ADD COLUMN cancelled_at TIMESTAMP NULL AFTER notes,
+    ADD COLUMN cancellation_reason VARCHAR(255) NOT NULL DEFAULT '' AFTER cancelled_at;
+
+UPDATE sales s
+    JOIN salespersons sp ON sp.id = s.salesperson_id
+    SET s.commission = ROUND(s.sale_price * sp.commission, 2);
+
+-- a cancelled sale hands the trade-in back to the customer
+ALTER TABLE trade_ins
+    MODIFY status ENUM('offered', 'applied', 'expired', 'returned') DEFAULT 'offered';
@@ -0,0 +1,26 @@
+-- a sale waiting on manager approval holds its vehicle in pending
+ALTER TABLE vehicles
+    MODIFY status ENUM('available', 'reserved', 'pending', 'sold', 'maintenance') DEFAULT 'available';
+
+ALTER TABLE salespersons
+    ADD COLUMN role ENUM('sales', 'manager') NOT NULL DEFAULT 'sales' AFTER department;
+
+-- discount is off the vehicle's list price; approval_reason says why a sale
+-- was sent for approval
+ALTER TABLE sales
+    ADD COLUMN discount DECIMAL(10,2) NOT NULL DEFAULT 0 AFTER sale_price,
+    ADD COLUMN approval_reason VARCHAR(255) NOT NULL DEFAULT '' AFTER notes;
+
+CREATE TABLE sale_approvals (
+    id VARCHAR(36) PRIMARY KEY,
+    sale_id VARCHAR(36) NOT NULL,
+    manager_id VARCHAR(36) NOT NULL,
+    decision ENUM('approved', 'rejected') NOT NULL,
+    notes VARCHAR(255) NOT NULL DEFAULT '',
+    decided_at TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (sale_id) REFERENCES sales(id) ON DELETE RESTRICT,
+    FOREIGN KEY (manager_id) REFERENCES salespersons(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_sale_approvals_sale ON sale_approvals(sale_id, decided_at);
@@ -0,0 +1,14 @@
+-- the rendered agreement for a completed sale. Each rendering is stored with
+-- the SHA-256 of its bytes so a copy can be checked against the original.
+CREATE TABLE sale_contracts (
+    id VARCHAR(36) PRIMARY KEY,
+    sale_id VARCHAR(36) NOT NULL UNIQUE,
+    terms VARCHAR(100) NOT NULL,
+    html MEDIUMTEXT NOT NULL,
+    html_sha256 CHAR(64) NOT NULL,
+    pdf MEDIUMBLOB NOT NULL,
+    pdf_sha256 CHAR(64) NOT NULL,
+    generated_at TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (sale_id) REFERENCES sales(id) ON DELETE RESTRICT
+);
@@ -0,0 +1,13 @@
+-- taxes and fees charged on top of the sale price. out_the_door_price is the
+-- sale price plus all of them, and is what down payments, trade-ins and
+-- financing are worked out against.
+ALTER TABLE sales
+    ADD COLUMN sales_tax DECIMAL(10,2) NOT NULL DEFAULT 0 AFTER discount,
+    ADD COLUMN doc_fee DECIMAL(10,2) NOT NULL DEFAULT 0 AFTER sales_tax,
+    ADD COLUMN registration_fee DECIMAL(10,2) NOT NULL DEFAULT 0 AFTER doc_fee,
+    ADD COLUMN title_fee DECIMAL(10,2) NOT NULL DEFAULT 0 AFTER registration_fee,
+    ADD COLUMN out_the_door_price DECIMAL(10,2) NOT NULL DEFAULT 0 AFTER title_fee;
+
+-- sales recorded before taxes and fees were charged went out the door at
+-- their sale price
+UPDATE sales SET out_the_door_price = sale_price;
@@ -0,0 +1,20 @@
+-- a sale a manager turns down is rejected rather than cancelled: it never
+-- completed, so it has no revenue or commission to reverse
+ALTER TABLE sales
+    MODIFY status ENUM('pending', 'completed', 'cancelled', 'rejected') DEFAULT 'pending',
+    ADD COLUMN completed_at TIMESTAMP NULL AFTER approval_reason;
+
+UPDATE sales s
+    SET s.status = 'rejected'
+    WHERE s.status = 'cancelled'
+      AND EXISTS (SELECT 1 FROM sale_approvals a WHERE a.sale_id = s.id AND a.decision = 'rejected');
+
+-- completed_at marks sales that were completed, so cancelling one later only
+-- reverses commission that was actually earned
+UPDATE sales s
+    SET s.completed_at = COALESCE(
+        (SELECT MAX(a.decided_at) FROM sale_approvals a WHERE a.sale_id = s.id AND a.decision = 'approved'),
+        s.sale_date)
+    WHERE s.status = 'completed'
+       OR (s.status = 'cancelled' AND (s.approval_reason = ''
+           OR EXISTS (SELECT 1 FROM sale_approvals a WHERE a.sale_id = s.id AND a.decision = 'approved')));
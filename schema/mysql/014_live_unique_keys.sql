@@ -0,0 +1,14 @@
+-- VINs and emails only have to be unique among rows that haven't been
+-- soft-deleted, so a deleted customer can register again and a deleted car
+-- can come back through a trade-in. Deleted rows index as NULL, which a
+-- unique index allows any number of. customers.email keeps the plain
+-- idx_customers_email lookup index from 001; vehicles.vin had none besides
+-- the unique key being dropped.
+ALTER TABLE vehicles
+    DROP INDEX vin,
+    ADD INDEX idx_vehicles_vin (vin),
+    ADD UNIQUE INDEX ux_vehicles_live_vin ((IF(deleted_at IS NULL, vin, NULL)));
+
+ALTER TABLE customers
+    DROP INDEX email,
+    ADD UNIQUE INDEX ux_customers_live_email ((IF(deleted_at IS NULL, email, NULL)));
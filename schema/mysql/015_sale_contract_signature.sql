@@ -0,0 +1,5 @@
+-- an HMAC of each contract's hashes under a key only the server holds, so a
+-- contract and its hashes can't be rewritten together in the database.
+-- Contracts stored before signing have no signature and fail verification.
+ALTER TABLE sale_contracts
+    ADD COLUMN signature CHAR(64) NOT NULL DEFAULT '' AFTER pdf_sha256;
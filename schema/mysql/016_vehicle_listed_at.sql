@@ -0,0 +1,6 @@
+-- when a vehicle last went on the lot. It starts out as created_at and moves
+-- when a car the dealership sold before comes back as a trade-in, so aging and
+-- days to sell count from the relisting rather than the car's first listing.
+ALTER TABLE vehicles ADD COLUMN listed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP AFTER deleted_at;
+
+UPDATE vehicles SET listed_at = created_at, updated_at = updated_at;